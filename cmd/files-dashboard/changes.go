@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+)
+
+// Change statuses shown as badges in the dashboard
+const (
+	statusNew       = "new"
+	statusUpdated   = "updated"
+	statusUnchanged = "unchanged"
+)
+
+// changeSummary counts files per change status
+type changeSummary struct {
+	New       int
+	Updated   int
+	Unchanged int
+}
+
+// markChangesFromManifest compares files against a previous render manifest by checksum
+func markChangesFromManifest(sections []section, source, previousPath string) error {
+	previous, err := manifest.Load(previousPath)
+	if err != nil {
+		return fmt.Errorf("load previous manifest: %w", err)
+	}
+
+	sums := make(map[string]string, len(previous.Artifacts))
+	for _, a := range previous.Artifacts {
+		sums[filepath.Clean(a.Output)] = a.SHA256
+	}
+
+	for i := range sections {
+		for j := range sections[i].Files {
+			entry := &sections[i].Files[j]
+			path := filepath.Join(source, entry.Path)
+
+			oldSum, ok := sums[path]
+			if !ok {
+				entry.Status = statusNew
+				continue
+			}
+
+			sum, _, err := manifest.Checksum(path)
+			if err != nil || sum != oldSum {
+				entry.Status = statusUpdated
+			} else {
+				entry.Status = statusUnchanged
+			}
+		}
+	}
+
+	return nil
+}
+
+// markChangesFromGit compares files against a git ref: files missing from the ref are new,
+// files that differ from it are updated
+func markChangesFromGit(sections []section, source, ref string) error {
+	tracked, err := gitPaths("ls-tree", "-r", "--name-only", ref, "--", source)
+	if err != nil {
+		return fmt.Errorf("list files at %s: %w", ref, err)
+	}
+
+	changed, err := gitPaths("diff", "--name-only", "--relative", ref, "--", source)
+	if err != nil {
+		return fmt.Errorf("diff against %s: %w", ref, err)
+	}
+
+	for i := range sections {
+		for j := range sections[i].Files {
+			entry := &sections[i].Files[j]
+			path := filepath.Join(source, entry.Path)
+
+			switch {
+			case !tracked[path]:
+				entry.Status = statusNew
+			case changed[path]:
+				entry.Status = statusUpdated
+			default:
+				entry.Status = statusUnchanged
+			}
+		}
+	}
+
+	return nil
+}
+
+// gitPaths runs a git command that prints one path per line, relative to the working directory
+func gitPaths(args ...string) (map[string]bool, error) {
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths[filepath.Clean(line)] = true
+		}
+	}
+	return paths, nil
+}
+
+// summarizeChanges counts change statuses, returning nil when no comparison was made
+func summarizeChanges(sections []section) *changeSummary {
+	var summary changeSummary
+	compared := false
+
+	for _, s := range sections {
+		for _, f := range s.Files {
+			switch f.Status {
+			case statusNew:
+				summary.New++
+			case statusUpdated:
+				summary.Updated++
+			case statusUnchanged:
+				summary.Unchanged++
+			default:
+				continue
+			}
+			compared = true
+		}
+	}
+
+	if !compared {
+		return nil
+	}
+	return &summary
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// fileType describes how an artifact type is presented in the dashboard
+type fileType struct {
+	Icon  string `yaml:"icon"`
+	Label string `yaml:"label"`
+}
+
+// defaultFileTypes covers common artifact types, keyed by lowercase extension
+var defaultFileTypes = map[string]fileType{
+	".pdf":  {Icon: "📄", Label: "PDF"},
+	".zip":  {Icon: "🗜️", Label: "Archive"},
+	".docx": {Icon: "📝", Label: "Word"},
+	".html": {Icon: "🌐", Label: "HTML"},
+	".epub": {Icon: "📚", Label: "EPUB"},
+	".md":   {Icon: "📃", Label: "Markdown"},
+	".png":  {Icon: "🖼️", Label: "Image"},
+	".jpg":  {Icon: "🖼️", Label: "Image"},
+	".svg":  {Icon: "🖼️", Label: "Image"},
+	".json": {Icon: "🔧", Label: "JSON"},
+	".txt":  {Icon: "📃", Label: "Text"},
+}
+
+// fileFilter selects which files are listed and how they are labelled
+type fileFilter struct {
+	include map[string]bool // extensions to list; empty lists everything
+	exclude []string        // globs relative to the source directory
+	types   map[string]fileType
+}
+
+// newFileFilter builds a filter from comma-separated extensions and globs,
+// plus an optional YAML file mapping extensions to icons and labels
+func newFileFilter(include, exclude, typesPath string) (fileFilter, error) {
+	f := fileFilter{
+		include: make(map[string]bool),
+		types:   make(map[string]fileType),
+	}
+
+	for ext, t := range defaultFileTypes {
+		f.types[ext] = t
+	}
+
+	for _, ext := range splitList(include) {
+		f.include[normalizeExt(ext)] = true
+	}
+
+	for _, pattern := range splitList(exclude) {
+		if !doublestar.ValidatePattern(pattern) {
+			return f, fmt.Errorf("invalid exclude pattern %q", pattern)
+		}
+		f.exclude = append(f.exclude, pattern)
+	}
+
+	if typesPath != "" {
+		data, err := os.ReadFile(typesPath)
+		if err != nil {
+			return f, fmt.Errorf("read file types: %w", err)
+		}
+
+		var custom map[string]fileType
+		if err := yaml.Unmarshal(data, &custom); err != nil {
+			return f, fmt.Errorf("parse file types: %w", err)
+		}
+
+		for ext, t := range custom {
+			f.types[normalizeExt(ext)] = t
+		}
+	}
+
+	return f, nil
+}
+
+// allows reports whether a file (relative to the source directory) should be listed
+func (f fileFilter) allows(rel string) bool {
+	if len(f.include) > 0 && !f.include[strings.ToLower(filepath.Ext(rel))] {
+		return false
+	}
+
+	slashed := filepath.ToSlash(rel)
+	for _, pattern := range f.exclude {
+		if ok, _ := doublestar.Match(pattern, slashed); ok {
+			return false
+		}
+		if ok, _ := doublestar.Match(pattern, filepath.Base(rel)); ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// typeOf returns the icon and label for a file name
+func (f fileFilter) typeOf(name string) fileType {
+	ext := strings.ToLower(filepath.Ext(name))
+	if t, ok := f.types[ext]; ok {
+		return t
+	}
+
+	label := strings.ToUpper(strings.TrimPrefix(ext, "."))
+	if label == "" {
+		label = "File"
+	}
+	return fileType{Icon: "📎", Label: label}
+}
+
+// normalizeExt lowercases an extension and ensures it has a leading dot
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -1,29 +1,70 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"flag"
 	"fmt"
+	"html/template"
 	"log"
 	"net/url"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
+	"syscall"
 
-	"github.com/kuzik/pandoc-latex-docker/internal/templates"
+	"github.com/kuzik/markdown-pdf-action/internal/gitinfo"
+	"github.com/kuzik/markdown-pdf-action/internal/natsort"
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+	"github.com/kuzik/markdown-pdf-action/internal/templates"
 )
 
-//go:embed dashboard.html dashboard-github.md dashboard-relative.md
+//go:embed dashboard.html dashboard.md
 var templateFS embed.FS
 
 type fileEntry struct {
-	Name    string
-	Path    string
-	Zip     string
-	RepoURL string
-	Branch  string
+	Name string
+	Path string
+	Zip  string
+
+	// Artifact type presentation
+	Icon  string
+	Label string
+
+	// File metadata; the raw values are used for sorting
+	Size         string
+	SizeBytes    int64
+	Modified     string
+	ModifiedUnix int64
+	Pages        int
+
+	// Populated from the render manifest
+	Title     string
+	Generated string
+	Sources   []sourceLink
+
+	// Public URLs of published files; they replace the local links
+	URL    string
+	ZipURL string
+
+	// Change status compared to a previous run: new, updated, or unchanged
+	Status string
+
+	// Hex SHA-256 of the file (when checksums are enabled)
+	SHA256 string
+
+	// First-page preview image (PDFs only, when thumbnails are enabled)
+	Thumbnail string
+
+	// Document text for content search (when a search index is built)
+	Text string
+}
+
+type sourceLink struct {
+	Name string
+	Path string
 }
 
 type section struct {
@@ -33,12 +74,46 @@ type section struct {
 
 type dashboardData struct {
 	Sections []section
+
+	// Tree arranges the same files by folder hierarchy
+	Tree *folderNode
+
+	// HasMetadata is true when entries carry manifest details (titles, pages, sources)
+	HasMetadata bool
+
+	// Changes counts new, updated, and unchanged files (nil without a comparison)
+	Changes *changeSummary
+
+	// SearchIndex maps file links to their lowercased text (nil without a search index)
+	SearchIndex map[string]string
 }
 
 type config struct {
-	source string
-	output string
-	format string
+	source   string
+	output   string
+	format   string
+	manifest string
+	remote   remoteConfig
+	filter   fileFilter
+
+	// Manifest holding the public URLs of published files
+	published string
+
+	// Change detection baselines
+	previousManifest string
+	sinceRef         string
+
+	thumbnails thumbnailConfig
+
+	// Show the SHA-256 of every file
+	checksums bool
+
+	// JSON search index of document contents (disabled if empty)
+	searchIndex string
+
+	// User-supplied templates replacing the embedded ones
+	htmlTemplate     string
+	markdownTemplate string
 }
 
 var tmplLoader *templates.EmbeddedLoader
@@ -47,53 +122,25 @@ func init() {
 	tmplLoader = templates.NewEmbeddedLoader(templateFS)
 }
 
-// urlEncodePath encodes a file path for use in URLs
+// urlEncodePath encodes a file path for use in URLs, keeping path separators
 func urlEncodePath(path string) string {
 	if path == "" {
 		return ""
 	}
-	return url.PathEscape(filepath.ToSlash(path))
-}
-
-// getGitHubURL tries to get the GitHub repository URL and current branch
-func getGitHubURL() (string, string) {
-	repoURL := getGitRemoteURL()
-	if repoURL == "" {
-		return "", ""
-	}
-
-	branch := getGitBranch()
-	return repoURL, branch
-}
-
-func getGitRemoteURL() string {
-	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
 
-	url := strings.TrimSpace(string(output))
-
-	// Convert git@github.com:user/repo.git to https://github.com/user/repo
-	if strings.HasPrefix(url, "git@github.com:") {
-		url = "https://github.com/" + strings.TrimSuffix(strings.TrimPrefix(url, "git@github.com:"), ".git")
-	} else if strings.HasPrefix(url, "https://github.com/") {
-		url = strings.TrimSuffix(url, ".git")
-	} else {
-		return ""
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
 	}
-
-	return url
+	return strings.Join(segments, "/")
 }
 
+// getGitBranch returns the checked-out branch, defaulting to main
 func getGitBranch() string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	output, err := cmd.Output()
-	if err != nil {
-		return "main"
+	if branch := gitinfo.Branch(); branch != "" {
+		return branch
 	}
-	return strings.TrimSpace(string(output))
+	return "main"
 }
 
 // buildPDFToZipMap creates a mapping of PDF files to their source zip files
@@ -125,7 +172,7 @@ func buildPDFToZipMap(source string) (map[string]string, error) {
 }
 
 // scanFiles scans the source directory and builds sections
-func scanFiles(source string, pdfToZip map[string]string) ([]section, error) {
+func scanFiles(source string, pdfToZip map[string]string, filter fileFilter) ([]section, error) {
 	sections := make(map[string][]fileEntry)
 
 	err := filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
@@ -141,6 +188,10 @@ func scanFiles(source string, pdfToZip map[string]string) ([]section, error) {
 		folder := filepath.Dir(path)
 		rel, _ := filepath.Rel(source, path)
 
+		if !filter.allows(rel) {
+			return nil
+		}
+
 		// Check if this PDF has a corresponding source zip
 		zipRel := ""
 		if filepath.Ext(info.Name()) == ".pdf" {
@@ -149,11 +200,14 @@ func scanFiles(source string, pdfToZip map[string]string) ([]section, error) {
 			}
 		}
 
-		sections[folder] = append(sections[folder], fileEntry{
-			Name: info.Name(),
-			Path: rel,
-			Zip:  zipRel,
-		})
+		t := filter.typeOf(info.Name())
+		sections[folder] = append(sections[folder], withFileInfo(fileEntry{
+			Name:  info.Name(),
+			Path:  rel,
+			Zip:   zipRel,
+			Icon:  t.Icon,
+			Label: t.Label,
+		}, path, info))
 
 		return nil
 	})
@@ -165,19 +219,22 @@ func scanFiles(source string, pdfToZip map[string]string) ([]section, error) {
 	return sortSections(sections), nil
 }
 
-// sortSections sorts sections and their files alphabetically
+// compareNames orders folder and file names; set by -sort
+var compareNames = natsort.Compare
+
+// sortSections sorts sections and their files by name
 func sortSections(sections map[string][]fileEntry) []section {
 	var ordered []section
 
 	for folder, files := range sections {
-		sort.Slice(files, func(i, j int) bool {
-			return files[i].Name < files[j].Name
+		slices.SortFunc(files, func(a, b fileEntry) int {
+			return compareNames(a.Name, b.Name)
 		})
 		ordered = append(ordered, section{Folder: folder, Files: files})
 	}
 
-	sort.Slice(ordered, func(i, j int) bool {
-		return ordered[i].Folder < ordered[j].Folder
+	slices.SortFunc(ordered, func(a, b section) int {
+		return compareNames(a.Folder, b.Folder)
 	})
 
 	return ordered
@@ -200,16 +257,32 @@ func adjustPathsForOutput(sections []section, source, outputDir string, urlEncod
 				zipPath, _ = filepath.Rel(outputDir, absZipPath)
 			}
 
+			sources := make([]sourceLink, len(file.Sources))
+			for k, src := range file.Sources {
+				srcPath, _ := filepath.Rel(outputDir, src.Path)
+				if urlEncode {
+					srcPath = urlEncodePath(srcPath)
+				}
+				sources[k] = sourceLink{Name: src.Name, Path: srcPath}
+			}
+
+			thumbPath := ""
+			if file.Thumbnail != "" {
+				thumbPath, _ = filepath.Rel(outputDir, file.Thumbnail)
+			}
+
 			if urlEncode {
 				relPath = urlEncodePath(relPath)
 				zipPath = urlEncodePath(zipPath)
+				thumbPath = urlEncodePath(thumbPath)
 			}
 
-			adjustedFiles[j] = fileEntry{
-				Name: file.Name,
-				Path: relPath,
-				Zip:  zipPath,
-			}
+			adjustedFiles[j] = file
+			adjustedFiles[j].Path = relPath
+			adjustedFiles[j].Zip = zipPath
+			adjustedFiles[j].Sources = sources
+			adjustedFiles[j].Thumbnail = thumbPath
+			adjustedFiles[j] = withPublishedURLs(adjustedFiles[j])
 		}
 
 		adjusted[i] = section{
@@ -221,77 +294,134 @@ func adjustPathsForOutput(sections []section, source, outputDir string, urlEncod
 	return adjusted
 }
 
-// prepareGitHubSections prepares sections with GitHub raw URLs
-func prepareGitHubSections(sections []section, source, repoURL, branch string) []section {
-	githubSections := make([]section, len(sections))
+// prepareRemoteSections prepares sections with links into the hosted repository
+func prepareRemoteSections(sections []section, source string, remote remoteRepo, patterns linkPatterns) []section {
+	remoteSections := make([]section, len(sections))
 
 	for i, sec := range sections {
-		githubFiles := make([]fileEntry, len(sec.Files))
+		remoteFiles := make([]fileEntry, len(sec.Files))
 
 		for j, file := range sec.Files {
 			zipPath := ""
 			if file.Zip != "" {
-				zipPath = urlEncodePath(filepath.Join(source, file.Zip))
+				zipPath = remote.expand(patterns.Raw, filepath.Join(source, file.Zip))
+			}
+
+			sources := make([]sourceLink, len(file.Sources))
+			for k, src := range file.Sources {
+				sources[k] = sourceLink{Name: src.Name, Path: remote.expand(patterns.Blob, src.Path)}
 			}
 
-			githubFiles[j] = fileEntry{
-				Name:    file.Name,
-				Path:    urlEncodePath(filepath.Join(source, file.Path)),
-				Zip:     zipPath,
-				RepoURL: repoURL,
-				Branch:  branch,
+			thumbPath := ""
+			if file.Thumbnail != "" {
+				thumbPath = remote.expand(patterns.Raw, file.Thumbnail)
 			}
+
+			remoteFiles[j] = file
+			remoteFiles[j].Path = remote.expand(patterns.Raw, filepath.Join(source, file.Path))
+			remoteFiles[j].Zip = zipPath
+			remoteFiles[j].Sources = sources
+			remoteFiles[j].Thumbnail = thumbPath
+			remoteFiles[j] = withPublishedURLs(remoteFiles[j])
 		}
 
-		githubSections[i] = section{
+		remoteSections[i] = section{
 			Folder: sec.Folder,
-			Files:  githubFiles,
+			Files:  remoteFiles,
 		}
 	}
 
-	return githubSections
+	return remoteSections
+}
+
+// loadTemplate loads a user-supplied template file, or the embedded default if path is empty
+func loadTemplate(path, embedded string) (*template.Template, error) {
+	if path == "" {
+		return tmplLoader.Load(embedded)
+	}
+	return templates.NewLoader(filepath.Dir(path)).Load(filepath.Base(path))
 }
 
 // generateHTML creates an HTML dashboard
 func generateHTML(cfg config, sections []section) error {
-	htmlOutput := cfg.output
-	if filepath.Ext(cfg.output) != ".html" {
-		htmlOutput = strings.TrimSuffix(cfg.output, filepath.Ext(cfg.output)) + ".html"
-	}
+	htmlOutput := outputPathWithExt(cfg.output, ".html")
 
 	if err := os.MkdirAll(filepath.Dir(htmlOutput), 0o755); err != nil {
 		return fmt.Errorf("create output directory: %w", err)
 	}
 
 	// Adjust paths for HTML output location
-	htmlOutputDir := filepath.Dir(htmlOutput)
-	adjustedSections := adjustPathsForOutput(sections, cfg.source, htmlOutputDir, true)
-
-	f, err := os.Create(htmlOutput)
+	content, err := renderHTMLDashboard(cfg, sections, filepath.Dir(htmlOutput))
 	if err != nil {
-		return fmt.Errorf("create HTML file: %w", err)
+		return err
+	}
+
+	if err := os.WriteFile(htmlOutput, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("write HTML file: %w", err)
+	}
+
+	log.Printf("HTML dashboard written: %s", htmlOutput)
+	return nil
+}
+
+// generatePDF renders the HTML dashboard to a printable PDF
+func generatePDF(ctx context.Context, cfg config, sections []section) error {
+	pdfOutput := outputPathWithExt(cfg.output, ".pdf")
+
+	if err := os.MkdirAll(filepath.Dir(pdfOutput), 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
 	}
-	defer f.Close()
 
-	tmpl, err := tmplLoader.Load("dashboard.html")
+	// Links stay relative to the PDF so they resolve next to the downloaded files
+	content, err := renderHTMLDashboard(cfg, sections, filepath.Dir(pdfOutput))
 	if err != nil {
-		return fmt.Errorf("load HTML template: %w", err)
+		return err
 	}
 
-	if err := tmpl.Execute(f, adjustedSections); err != nil {
-		return fmt.Errorf("execute HTML template: %w", err)
+	if err := pdf.FromHTML(ctx, content, pdfOutput); err != nil {
+		return fmt.Errorf("convert to PDF: %w", err)
 	}
 
-	log.Printf("HTML dashboard written: %s", htmlOutput)
+	log.Printf("PDF dashboard written: %s", pdfOutput)
 	return nil
 }
 
-// generateMarkdown creates a Markdown dashboard
-func generateMarkdown(cfg config, sections []section, repoURL, branch string) error {
-	mdOutput := cfg.output
-	if filepath.Ext(cfg.output) != ".md" {
-		mdOutput = strings.TrimSuffix(cfg.output, filepath.Ext(cfg.output)) + ".md"
+// renderHTMLDashboard executes the HTML template with links relative to outputDir
+func renderHTMLDashboard(cfg config, sections []section, outputDir string) (string, error) {
+	adjustedSections := adjustPathsForOutput(sections, cfg.source, outputDir, true)
+
+	tmpl, err := loadTemplate(cfg.htmlTemplate, "dashboard.html")
+	if err != nil {
+		return "", fmt.Errorf("load HTML template: %w", err)
+	}
+
+	data := dashboardData{
+		Sections:    adjustedSections,
+		Tree:        buildTree(adjustedSections, cfg.source, cfg.manifest != ""),
+		HasMetadata: cfg.manifest != "",
+		Changes:     summarizeChanges(adjustedSections),
+		SearchIndex: pageSearchIndex(adjustedSections),
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute HTML template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// outputPathWithExt returns the output path with its extension replaced by ext
+func outputPathWithExt(output, ext string) string {
+	if filepath.Ext(output) == ext {
+		return output
 	}
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ext
+}
+
+// generateMarkdown creates a Markdown dashboard
+func generateMarkdown(cfg config, sections []section) error {
+	mdOutput := outputPathWithExt(cfg.output, ".md")
 
 	if err := os.MkdirAll(filepath.Dir(mdOutput), 0o755); err != nil {
 		return fmt.Errorf("create output directory: %w", err)
@@ -303,27 +433,29 @@ func generateMarkdown(cfg config, sections []section, repoURL, branch string) er
 	}
 	defer mdFile.Close()
 
-	var tmplName string
-	var data dashboardData
+	var linkedSections []section
 
-	if repoURL != "" && branch != "" {
-		// Use GitHub raw URLs
-		githubSections := prepareGitHubSections(sections, cfg.source, repoURL, branch)
-		tmplName = "dashboard-github.md"
-		data = dashboardData{
-			Sections: githubSections,
+	if remote, ok := getGitRemote(); ok && remote.Branch != "" {
+		if patterns, ok := cfg.remote.patterns(remote); ok {
+			// Link into the hosted repository
+			linkedSections = prepareRemoteSections(sections, cfg.source, remote, patterns)
 		}
-	} else {
+	}
+
+	if linkedSections == nil {
 		// Use relative URLs
 		outputDir := filepath.Dir(mdOutput)
-		adjustedSections := adjustPathsForOutput(sections, cfg.source, outputDir, true)
-		tmplName = "dashboard-relative.md"
-		data = dashboardData{
-			Sections: adjustedSections,
-		}
+		linkedSections = adjustPathsForOutput(sections, cfg.source, outputDir, true)
+	}
+
+	data := dashboardData{
+		Sections:    linkedSections,
+		Tree:        buildTree(linkedSections, cfg.source, cfg.manifest != ""),
+		HasMetadata: cfg.manifest != "",
+		Changes:     summarizeChanges(linkedSections),
 	}
 
-	tmpl, err := tmplLoader.Load(tmplName)
+	tmpl, err := loadTemplate(cfg.markdownTemplate, "dashboard.md")
 	if err != nil {
 		return fmt.Errorf("load markdown template: %w", err)
 	}
@@ -336,38 +468,159 @@ func generateMarkdown(cfg config, sections []section, repoURL, branch string) er
 	return nil
 }
 
+// collectSections builds sections from the manifest if configured, otherwise by scanning the source directory
+func collectSections(cfg config) ([]section, error) {
+	if cfg.manifest != "" {
+		return scanManifest(cfg.source, cfg.manifest, cfg.filter)
+	}
+
+	// Build mapping of PDFs to their source zips
+	pdfToZip, err := buildPDFToZipMap(cfg.source)
+	if err != nil {
+		return nil, fmt.Errorf("build PDF to ZIP mapping: %w", err)
+	}
+
+	return scanFiles(cfg.source, pdfToZip, cfg.filter)
+}
+
+// markChanges sets each file's change status against the configured baseline, if any
+func markChanges(sections []section, cfg config) error {
+	switch {
+	case cfg.previousManifest != "":
+		return markChangesFromManifest(sections, cfg.source, cfg.previousManifest)
+	case cfg.sinceRef != "":
+		return markChangesFromGit(sections, cfg.source, cfg.sinceRef)
+	}
+	return nil
+}
+
 func main() {
-	cfg := config{}
+	// "files-dashboard validate-templates -html-template ..." only checks the templates
+	validateOnly := len(os.Args) > 1 && os.Args[1] == "validate-templates"
+	if validateOnly {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
+	cfg := config{remote: remoteConfig{hosts: hostsFlag{}}}
 	flag.StringVar(&cfg.source, "source", "output", "Directory to scan")
 	flag.StringVar(&cfg.output, "output", "output/files-dashboard.html", "Dashboard output path")
-	flag.StringVar(&cfg.format, "format", "both", "Output format: html, markdown, or both")
+	flag.StringVar(&cfg.format, "format", "both", "Output formats: html, markdown, both, or pdf (comma-separated)")
+	flag.StringVar(&cfg.manifest, "manifest", "", "Render manifest to read instead of scanning the source directory")
+	flag.StringVar(&cfg.published, "published", "", "Manifest whose artifact URLs replace local download links (defaults to -manifest)")
+	flag.StringVar(&cfg.htmlTemplate, "html-template", "", "Custom HTML dashboard template (Go html/template)")
+	flag.StringVar(&cfg.markdownTemplate, "markdown-template", "", "Custom Markdown dashboard template (Go html/template)")
+	flag.Var(hostsFlag(cfg.remote.hosts), "remote-host", "Self-hosted git host and its style, e.g. git.example.com=gitlab (repeatable)")
+	flag.StringVar(&cfg.remote.rawPattern, "raw-url-pattern", "", "Download link pattern, e.g. https://git.example.com/{repo}/raw/{branch}/{path}")
+	include := flag.String("include", "", "File extensions to list, e.g. pdf,zip,docx (default: all files)")
+	exclude := flag.String("exclude", "", "Comma-separated globs of files to hide, relative to -source")
+	flag.StringVar(&cfg.previousManifest, "previous-manifest", "", "Manifest from a previous run; files are marked new, updated, or unchanged")
+	flag.StringVar(&cfg.sinceRef, "since", "", "Git ref to compare against when no previous manifest is given")
+	order := flag.String("sort", natsort.Natural, "Folder and file order: natural (chapter2 before chapter10) or lexical")
+	typesPath := flag.String("file-types", "", "YAML file mapping extensions to dashboard icons and labels")
+	flag.StringVar(&cfg.thumbnails.dir, "thumbnails", "", "Directory to write first-page PNG previews of PDFs to (disabled if empty)")
+	flag.IntVar(&cfg.thumbnails.width, "thumbnail-width", pdf.DefaultThumbnailWidth, "Thumbnail width in pixels")
+	flag.BoolVar(&cfg.checksums, "checksums", false, "Show the SHA-256 of every file so downloads can be verified")
+	flag.StringVar(&cfg.searchIndex, "search-index", "", "Extract document text to this JSON search index and search contents in the HTML dashboard")
 	flag.Parse()
 
-	// Get GitHub repository information
-	repoURL, branch := getGitHubURL()
+	if validateOnly {
+		if validateTemplates(cfg) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Build mapping of PDFs to their source zips
-	pdfToZip, err := buildPDFToZipMap(cfg.source)
+	// Keep generated thumbnails and the search index out of the listing
+	if glob := thumbnailExclude(cfg.source, cfg.thumbnails.dir); glob != "" {
+		*exclude = strings.Join([]string{*exclude, glob}, ",")
+	}
+	if rel, err := filepath.Rel(cfg.source, cfg.searchIndex); cfg.searchIndex != "" && err == nil && !strings.HasPrefix(rel, "..") {
+		*exclude = strings.Join([]string{*exclude, filepath.ToSlash(rel)}, ",")
+	}
+
+	compare, err := natsort.Func(*order)
+	if err != nil {
+		log.Fatalf("Invalid -sort: %v", err)
+	}
+	compareNames = compare
+
+	filter, err := newFileFilter(*include, *exclude, *typesPath)
 	if err != nil {
-		log.Fatalf("Failed to build PDF to ZIP mapping: %v", err)
+		log.Fatalf("Invalid file filter: %v", err)
 	}
+	cfg.filter = filter
 
 	// Scan files and build sections
-	sections, err := scanFiles(cfg.source, pdfToZip)
+	sections, err := collectSections(cfg)
 	if err != nil {
 		log.Fatalf("Failed to scan files: %v", err)
 	}
 
+	if err := applyPublishedURLs(sections, cfg); err != nil {
+		log.Fatalf("Failed to read published URLs: %v", err)
+	}
+
+	if err := markChanges(sections, cfg); err != nil {
+		log.Fatalf("Failed to detect changes: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	addThumbnails(ctx, sections, cfg.source, cfg.thumbnails)
+
+	if cfg.checksums {
+		addChecksums(sections, cfg.source)
+	}
+
+	if cfg.searchIndex != "" {
+		indexContents(ctx, sections, cfg.source)
+		if err := writeSearchIndex(sections, cfg.searchIndex); err != nil {
+			log.Fatalf("Failed to build search index: %v", err)
+		}
+	}
+
+	formats, err := parseFormats(cfg.format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Generate outputs based on format
-	if cfg.format == "html" || cfg.format == "both" {
+	if formats["html"] {
 		if err := generateHTML(cfg, sections); err != nil {
 			log.Fatalf("Failed to generate HTML: %v", err)
 		}
 	}
 
-	if cfg.format == "markdown" || cfg.format == "both" {
-		if err := generateMarkdown(cfg, sections, repoURL, branch); err != nil {
+	if formats["markdown"] {
+		if err := generateMarkdown(cfg, sections); err != nil {
 			log.Fatalf("Failed to generate Markdown: %v", err)
 		}
 	}
+
+	if formats["pdf"] {
+		if err := generatePDF(ctx, cfg, sections); err != nil {
+			stop()
+			log.Fatalf("Failed to generate PDF: %v", err)
+		}
+	}
+}
+
+// parseFormats parses a comma-separated list of output formats; "both" means html and markdown
+func parseFormats(value string) (map[string]bool, error) {
+	formats := make(map[string]bool)
+
+	for _, f := range strings.Split(value, ",") {
+		switch f = strings.TrimSpace(f); f {
+		case "both":
+			formats["html"] = true
+			formats["markdown"] = true
+		case "html", "markdown", "pdf":
+			formats[f] = true
+		default:
+			return nil, fmt.Errorf("unknown format %q (use html, markdown, both, or pdf)", f)
+		}
+	}
+
+	return formats, nil
 }
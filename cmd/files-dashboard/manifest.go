@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+)
+
+// scanManifest builds sections from a render manifest instead of walking the source directory.
+// Only artifacts inside source are listed; source zips are attached to their PDFs.
+func scanManifest(source, manifestPath string, filter fileFilter) ([]section, error) {
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map PDFs to their source zips (e.g., name_src.zip next to name.pdf)
+	pdfToZip := make(map[string]string)
+	for _, a := range m.Artifacts {
+		if a.Kind == "zip" && strings.HasSuffix(a.Output, "_src.zip") {
+			pdfPath := strings.TrimSuffix(a.Output, "_src.zip") + ".pdf"
+			rel, err := filepath.Rel(source, a.Output)
+			if err == nil {
+				pdfToZip[filepath.Clean(pdfPath)] = rel
+			}
+		}
+	}
+
+	sections := make(map[string][]fileEntry)
+
+	for _, a := range m.Artifacts {
+		if a.Kind == "zip" && strings.HasSuffix(a.Output, "_src.zip") {
+			continue
+		}
+
+		rel, err := filepath.Rel(source, a.Output)
+		if err != nil || strings.HasPrefix(rel, "..") || !filter.allows(rel) {
+			continue
+		}
+
+		t := filter.typeOf(a.Output)
+
+		entry := fileEntry{
+			Name:    filepath.Base(a.Output),
+			Path:    rel,
+			Zip:     pdfToZip[filepath.Clean(a.Output)],
+			Title:   a.Title,
+			Pages:   a.Pages,
+			Sources: sourceLinks(a.Sources),
+			Icon:    t.Icon,
+			Label:   t.Label,
+		}
+		if !a.GeneratedAt.IsZero() {
+			entry.Generated = a.GeneratedAt.UTC().Format(time.RFC3339)
+		}
+
+		if info, err := os.Stat(a.Output); err == nil {
+			entry = withFileInfo(entry, a.Output, info)
+		} else {
+			entry.SizeBytes = a.Size
+			entry.Size = humanSize(a.Size)
+		}
+
+		folder := filepath.Dir(a.Output)
+		sections[folder] = append(sections[folder], entry)
+	}
+
+	return sortSections(sections), nil
+}
+
+// sourceLinks converts source paths into links labelled with the file name
+func sourceLinks(sources []string) []sourceLink {
+	links := make([]sourceLink, 0, len(sources))
+	for _, src := range sources {
+		links = append(links, sourceLink{Name: filepath.Base(src), Path: src})
+	}
+	return links
+}
+
+// applyPublishedURLs sets the public URLs recorded in the published manifest,
+// or the render manifest, on the files and zips they were uploaded from
+func applyPublishedURLs(sections []section, cfg config) error {
+	path := cfg.published
+	if path == "" {
+		path = cfg.manifest
+	}
+	if path == "" {
+		return nil
+	}
+
+	m, err := manifest.Load(path)
+	if err != nil {
+		return err
+	}
+
+	urls := make(map[string]string)
+	for _, a := range m.Artifacts {
+		if a.URL != "" {
+			urls[filepath.Clean(a.Output)] = a.URL
+		}
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+
+	for i := range sections {
+		for j := range sections[i].Files {
+			file := &sections[i].Files[j]
+			file.URL = urls[filepath.Join(cfg.source, file.Path)]
+			if file.Zip != "" {
+				file.ZipURL = urls[filepath.Join(cfg.source, file.Zip)]
+			}
+		}
+	}
+	return nil
+}
+
+// withPublishedURLs links a file and its zip to their published copies, if any
+func withPublishedURLs(file fileEntry) fileEntry {
+	if file.URL != "" {
+		file.Path = file.URL
+	}
+	if file.ZipURL != "" {
+		file.Zip = file.ZipURL
+	}
+	return file
+}
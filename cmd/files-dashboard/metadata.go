@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+)
+
+// withFileInfo fills in size, modification time, and (for PDFs) page count
+func withFileInfo(entry fileEntry, path string, info os.FileInfo) fileEntry {
+	entry.SizeBytes = info.Size()
+	entry.Size = humanSize(info.Size())
+	entry.ModifiedUnix = info.ModTime().Unix()
+	entry.Modified = formatTimestamp(info.ModTime())
+
+	if entry.Pages == 0 && strings.EqualFold(filepath.Ext(path), ".pdf") {
+		if data, err := os.ReadFile(path); err == nil {
+			entry.Pages = pdf.PageCount(data)
+		}
+	}
+
+	return entry
+}
+
+// addChecksums sets the SHA-256 of every listed file; files that can't be read
+// are logged and shown without one
+func addChecksums(sections []section, source string) {
+	for i := range sections {
+		for k := range sections[i].Files {
+			f := &sections[i].Files[k]
+			sum, _, err := manifest.Checksum(filepath.Join(source, f.Path))
+			if err != nil {
+				log.Printf("Warning: checksum of %s: %v", f.Path, err)
+				continue
+			}
+			f.SHA256 = sum
+		}
+	}
+}
+
+// humanSize formats a byte count using binary units
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatTimestamp formats a time for display in dashboards
+func formatTimestamp(t time.Time) string {
+	return t.Format("2006-01-02 15:04")
+}
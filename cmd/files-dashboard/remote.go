@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// remoteRepo identifies a repository on a git hosting service
+type remoteRepo struct {
+	Host   string // e.g. gitlab.example.com
+	Repo   string // e.g. group/subgroup/project
+	WebURL string // e.g. https://gitlab.example.com/group/subgroup/project
+	Branch string
+}
+
+// linkPatterns describes how a hosting service builds file URLs.
+// Patterns may use {base}, {repo}, {host}, {branch}, and {path} placeholders.
+type linkPatterns struct {
+	Raw  string // direct download of a file
+	Blob string // rendered view of a file
+}
+
+// hostingStyles maps hosting service kinds to their link patterns
+var hostingStyles = map[string]linkPatterns{
+	"github": {
+		Raw:  "{base}/raw/{branch}/{path}",
+		Blob: "{base}/blob/{branch}/{path}",
+	},
+	"gitlab": {
+		Raw:  "{base}/-/raw/{branch}/{path}",
+		Blob: "{base}/-/blob/{branch}/{path}",
+	},
+	"bitbucket": {
+		Raw:  "{base}/raw/{branch}/{path}",
+		Blob: "{base}/src/{branch}/{path}",
+	},
+}
+
+// knownHosts maps public hosting services to their style
+var knownHosts = map[string]string{
+	"github.com":    "github",
+	"gitlab.com":    "gitlab",
+	"bitbucket.org": "bitbucket",
+}
+
+// remoteConfig holds user overrides for self-hosted remotes
+type remoteConfig struct {
+	// hosts maps self-hosted domains to a style (github, gitlab, bitbucket)
+	hosts map[string]string
+	// rawPattern overrides the download link pattern for any host
+	rawPattern string
+}
+
+// hostsFlag collects repeated -remote-host host=style flags
+type hostsFlag map[string]string
+
+func (h hostsFlag) String() string {
+	var parts []string
+	for host, style := range h {
+		parts = append(parts, host+"="+style)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (h hostsFlag) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		host, style, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("expected host=style, got %q", pair)
+		}
+		if _, known := hostingStyles[style]; !known {
+			return fmt.Errorf("unknown hosting style %q (use github, gitlab, or bitbucket)", style)
+		}
+		h[strings.ToLower(host)] = style
+	}
+	return nil
+}
+
+// getGitRemote detects the origin remote and current branch of the working directory
+func getGitRemote() (remoteRepo, bool) {
+	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+	output, err := cmd.Output()
+	if err != nil {
+		return remoteRepo{}, false
+	}
+
+	remote, ok := parseRemoteURL(strings.TrimSpace(string(output)))
+	if !ok {
+		return remoteRepo{}, false
+	}
+
+	remote.Branch = getGitBranch()
+	return remote, true
+}
+
+// parseRemoteURL parses SSH (git@host:repo.git, ssh://git@host/repo.git) and
+// HTTPS remote URLs into a repository with a browsable web URL
+func parseRemoteURL(raw string) (remoteRepo, bool) {
+	var host, repo string
+
+	if !strings.Contains(raw, "://") {
+		// scp-like syntax: [user@]host:owner/repo.git
+		userHost, path, ok := strings.Cut(raw, ":")
+		if !ok {
+			return remoteRepo{}, false
+		}
+		if at := strings.LastIndex(userHost, "@"); at >= 0 {
+			userHost = userHost[at+1:]
+		}
+		host, repo = userHost, path
+	} else {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return remoteRepo{}, false
+		}
+		host, repo = u.Hostname(), u.Path
+	}
+
+	repo = strings.TrimSuffix(strings.Trim(repo, "/"), ".git")
+	// Bitbucket Server clone URLs carry an scm/ prefix
+	repo = strings.TrimPrefix(repo, "scm/")
+	if host == "" || repo == "" {
+		return remoteRepo{}, false
+	}
+
+	host = strings.ToLower(host)
+	return remoteRepo{
+		Host:   host,
+		Repo:   repo,
+		WebURL: "https://" + host + "/" + repo,
+	}, true
+}
+
+// patterns returns the link patterns for a remote, or false if the host is unknown
+func (c remoteConfig) patterns(r remoteRepo) (linkPatterns, bool) {
+	style, ok := c.hosts[r.Host]
+	if !ok {
+		style, ok = knownHosts[r.Host]
+	}
+
+	p := hostingStyles[style]
+	if c.rawPattern != "" {
+		p.Raw = c.rawPattern
+		if !ok {
+			p.Blob = c.rawPattern
+		}
+		return p, true
+	}
+
+	return p, ok
+}
+
+// expand fills a link pattern for a file path in the repository
+func (r remoteRepo) expand(pattern, path string) string {
+	return strings.NewReplacer(
+		"{base}", r.WebURL,
+		"{host}", r.Host,
+		"{repo}", r.Repo,
+		"{branch}", urlEncodePath(r.Branch),
+		"{path}", urlEncodePath(path),
+	).Replace(pattern)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+)
+
+// searchIndexVersion is bumped when the search index format changes
+const searchIndexVersion = 1
+
+// textExtensions are files indexed as they are, without extraction
+var textExtensions = map[string]bool{".md": true, ".markdown": true, ".txt": true}
+
+// searchIndex is the JSON search index of document contents
+type searchIndex struct {
+	Version   int              `json:"version"`
+	Documents []searchDocument `json:"documents"`
+}
+
+type searchDocument struct {
+	Path  string `json:"path"` // relative to the dashboard source
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text"`
+}
+
+// indexContents extracts the text of every PDF and text file into its entry.
+// Files whose text can't be extracted are logged and stay searchable by name only.
+func indexContents(ctx context.Context, sections []section, source string) {
+	for i := range sections {
+		for j := range sections[i].Files {
+			file := &sections[i].Files[j]
+			path := filepath.Join(source, file.Path)
+
+			text, err := extractText(ctx, path)
+			if err != nil {
+				log.Printf("Warning: index %s: %v", path, err)
+				continue
+			}
+			file.Text = text
+		}
+	}
+}
+
+// extractText returns the searchable text of a file, or "" for unsupported types
+func extractText(ctx context.Context, path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	switch {
+	case ext == ".pdf":
+		return pdf.ExtractText(ctx, path)
+	case textExtensions[ext]:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(strings.Fields(string(data)), " "), nil
+	}
+	return "", nil
+}
+
+// writeSearchIndex saves the extracted text of all files as a JSON index
+func writeSearchIndex(sections []section, path string) error {
+	index := searchIndex{Version: searchIndexVersion, Documents: []searchDocument{}}
+	for _, sec := range sections {
+		for _, file := range sec.Files {
+			if file.Text == "" {
+				continue
+			}
+			index.Documents = append(index.Documents, searchDocument{
+				Path:  filepath.ToSlash(file.Path),
+				Title: file.Title,
+				Text:  file.Text,
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode search index: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create search index directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write search index: %w", err)
+	}
+
+	log.Printf("Search index written: %s (%d documents)", path, len(index.Documents))
+	return nil
+}
+
+// pageSearchIndex maps each file's link in a rendered dashboard to its lowercased
+// text, for the search box; nil when no contents were indexed
+func pageSearchIndex(sections []section) map[string]string {
+	var index map[string]string
+	for _, sec := range sections {
+		for _, file := range sec.Files {
+			if file.Text == "" {
+				continue
+			}
+			if index == nil {
+				index = make(map[string]string)
+			}
+			index[file.Path] = strings.ToLower(file.Text)
+		}
+	}
+	return index
+}
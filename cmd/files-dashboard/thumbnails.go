@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+)
+
+// thumbnailConfig controls first-page previews for PDFs
+type thumbnailConfig struct {
+	dir   string // directory the PNGs are written to; empty disables thumbnails
+	width int
+}
+
+// addThumbnails renders a first-page PNG for every PDF and links it from the entry.
+// Thumbnails newer than their PDF are reused. Failures are logged and leave the
+// entry without a preview.
+func addThumbnails(ctx context.Context, sections []section, source string, cfg thumbnailConfig) {
+	if cfg.dir == "" {
+		return
+	}
+
+	for i := range sections {
+		for j := range sections[i].Files {
+			file := &sections[i].Files[j]
+			if !strings.EqualFold(filepath.Ext(file.Name), ".pdf") {
+				continue
+			}
+
+			pdfPath := filepath.Join(source, file.Path)
+			pngPath := filepath.Join(cfg.dir, strings.TrimSuffix(file.Path, filepath.Ext(file.Path))+".png")
+
+			if !thumbnailFresh(pdfPath, pngPath) {
+				if err := pdf.Thumbnail(ctx, pdfPath, pngPath, cfg.width); err != nil {
+					log.Printf("Warning: thumbnail for %s: %v", pdfPath, err)
+					continue
+				}
+			}
+
+			file.Thumbnail = pngPath
+		}
+	}
+}
+
+// thumbnailFresh reports whether pngPath exists and is newer than pdfPath
+func thumbnailFresh(pdfPath, pngPath string) bool {
+	pngInfo, err := os.Stat(pngPath)
+	if err != nil {
+		return false
+	}
+	pdfInfo, err := os.Stat(pdfPath)
+	if err != nil {
+		return false
+	}
+	return !pngInfo.ModTime().Before(pdfInfo.ModTime())
+}
+
+// thumbnailExclude returns a glob hiding the thumbnail directory from the listing
+// when it lies inside source, or "" otherwise
+func thumbnailExclude(source, dir string) string {
+	if dir == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(source, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.ToSlash(rel) + "/**"
+}
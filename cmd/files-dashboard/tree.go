@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// folderNode is a folder in the dashboard tree
+type folderNode struct {
+	Name     string // last path segment
+	Path     string // folder path, as in section.Folder
+	Depth    int    // 0 for the scanned root
+	Heading  string // markdown heading marker for this depth
+	Files    []fileEntry
+	Children []*folderNode
+
+	// HasMetadata mirrors dashboardData.HasMetadata so recursive templates can use it
+	HasMetadata bool
+}
+
+// FileCount returns the number of files in the folder and all subfolders
+func (n *folderNode) FileCount() int {
+	count := len(n.Files)
+	for _, c := range n.Children {
+		count += c.FileCount()
+	}
+	return count
+}
+
+// buildTree arranges sections into a folder hierarchy rooted at source
+func buildTree(sections []section, source string, hasMetadata bool) *folderNode {
+	root := &folderNode{
+		Name:        filepath.Base(filepath.Clean(source)),
+		Path:        filepath.Clean(source),
+		Heading:     headingFor(0),
+		HasMetadata: hasMetadata,
+	}
+
+	for _, sec := range sections {
+		node := root
+		rel, err := filepath.Rel(root.Path, filepath.Clean(sec.Folder))
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+				node = node.child(part)
+			}
+		}
+		node.Files = append(node.Files, sec.Files...)
+	}
+
+	root.sortChildren()
+	return root
+}
+
+// child returns the named subfolder, creating it if needed
+func (n *folderNode) child(name string) *folderNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	c := &folderNode{
+		Name:        name,
+		Path:        filepath.Join(n.Path, name),
+		Depth:       n.Depth + 1,
+		Heading:     headingFor(n.Depth + 1),
+		HasMetadata: n.HasMetadata,
+	}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// sortChildren orders subfolders by name at every level
+func (n *folderNode) sortChildren() {
+	slices.SortFunc(n.Children, func(a, b *folderNode) int {
+		return compareNames(a.Name, b.Name)
+	})
+	for _, c := range n.Children {
+		c.sortChildren()
+	}
+}
+
+// headingFor returns the markdown heading marker for a tree depth (root is ##, capped at ######)
+func headingFor(depth int) string {
+	level := depth + 2
+	if level > 6 {
+		level = 6
+	}
+	return strings.Repeat("#", level)
+}
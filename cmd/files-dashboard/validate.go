@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log"
+
+	"github.com/kuzik/markdown-pdf-action/internal/templates"
+)
+
+// sampleDashboard returns dashboard data holding one file with every detail
+// filled in, to check custom templates against
+func sampleDashboard() dashboardData {
+	sections := []section{{
+		Folder: "docs",
+		Files: []fileEntry{{
+			Name:         "guide.pdf",
+			Path:         "docs/guide.pdf",
+			Zip:          "docs/guide-src.zip",
+			Icon:         "📄",
+			Label:        "PDF",
+			Size:         "1.2 MB",
+			SizeBytes:    1258291,
+			Modified:     "2024-01-02 15:04",
+			ModifiedUnix: 1704207840,
+			Pages:        12,
+			Title:        "Guide",
+			Generated:    "2024-01-02T15:04:05Z",
+			Sources:      []sourceLink{{Name: "README.md", Path: "docs/README.md"}},
+			Status:       statusUpdated,
+			Thumbnail:    "thumbnails/docs/guide.png",
+			Text:         "sample document text",
+		}},
+	}}
+
+	return dashboardData{
+		Sections:    sections,
+		Tree:        buildTree(sections, ".", true),
+		HasMetadata: true,
+		Changes:     summarizeChanges(sections),
+		SearchIndex: pageSearchIndex(sections),
+	}
+}
+
+// validateTemplates parses the custom dashboard templates and checks them
+// against sample data without scanning files. It returns the number of
+// invalid templates.
+func validateTemplates(cfg config) int {
+	invalid := 0
+	for _, path := range []string{cfg.htmlTemplate, cfg.markdownTemplate} {
+		if path == "" {
+			continue
+		}
+
+		tmpl, err := loadTemplate(path, "")
+		if err != nil {
+			log.Printf("INVALID: %s: %v", path, err)
+			invalid++
+			continue
+		}
+
+		switch report := templates.Check(tmpl, sampleDashboard()); {
+		case !report.OK():
+			log.Printf("INVALID: %s: %s", path, report)
+			invalid++
+		case len(report.Unused) > 0:
+			log.Printf("OK: %s (%s)", path, report)
+		default:
+			log.Printf("OK: %s", path)
+		}
+	}
+
+	if cfg.htmlTemplate == "" && cfg.markdownTemplate == "" {
+		log.Printf("No custom templates given; set -html-template or -markdown-template")
+	}
+	return invalid
+}
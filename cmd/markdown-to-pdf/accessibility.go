@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/a11y"
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
+)
+
+// checkAccessibility runs the accessibility heuristics over the HTML a
+// document was printed from. With warn the issues become document warnings;
+// with error they fail the document and its PDF is removed.
+func checkAccessibility(res *render.Result, mode string) error {
+	if mode == "" {
+		return nil
+	}
+	issues, err := a11y.Check(res.HTML)
+	if err != nil {
+		return fmt.Errorf("accessibility check: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	if mode == checkError {
+		os.Remove(res.OutputPath)
+		messages := make([]string, len(issues))
+		for i, issue := range issues {
+			messages[i] = issue.String()
+		}
+		return fmt.Errorf("accessibility issues in %s: %s", res.OutputPath, strings.Join(messages, "; "))
+	}
+	for _, issue := range issues {
+		res.Warnings = append(res.Warnings, "accessibility: "+issue.String())
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"compress/flate"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+	"github.com/kuzik/markdown-pdf-action/internal/ziputil"
+)
+
+// archiveConfig controls how a job builds zip archives
+type archiveConfig struct {
+	SourceDir     *string  `yaml:"source_dir"`    // subfolders jobs: directory next to each README to zip (default "src", "" disables)
+	Include       []string `yaml:"include"`       // globs of files to include, relative to the zipped directory
+	Exclude       []string `yaml:"exclude"`       // globs of files to leave out
+	Level         *int     `yaml:"level"`         // compression level 0 (store) to 9 (smallest)
+	Deterministic bool     `yaml:"deterministic"` // fixed entry timestamps for reproducible zips
+	PDFs          string   `yaml:"pdfs"`          // also zip the job's generated PDFs to this path
+}
+
+// sourceDir returns the directory zipped next to each README in subfolders jobs
+func (a archiveConfig) sourceDir() string {
+	if a.SourceDir == nil {
+		return "src"
+	}
+	return *a.SourceDir
+}
+
+// options converts the archive settings to ziputil options
+func (a archiveConfig) options() (ziputil.Options, error) {
+	opts := ziputil.DefaultOptions()
+	opts.Include = a.Include
+	opts.Exclude = a.Exclude
+
+	if a.Level != nil {
+		if *a.Level < flate.NoCompression || *a.Level > flate.BestCompression {
+			return opts, fmt.Errorf("archive level must be between 0 and 9, got %d", *a.Level)
+		}
+		opts.Level = *a.Level
+	}
+
+	if a.Deterministic || reproducible {
+		t, err := fixedTimestamp()
+		if err != nil {
+			return opts, err
+		}
+		opts.Modified = t
+	}
+
+	return opts, nil
+}
+
+// fixedTimestamp returns SOURCE_DATE_EPOCH if set, otherwise the earliest time a zip can store
+func fixedTimestamp() (time.Time, error) {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		secs, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q", epoch)
+		}
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	return time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// zipSourceIfExists creates a zip of the job's source directory next to a README, if it exists
+func zipSourceIfExists(folder, outputDir, baseName string, j job) error {
+	dirName := j.Archive.sourceDir()
+	if dirName == "" {
+		return nil
+	}
+
+	srcDir := filepath.Join(folder, dirName)
+	if _, err := os.Stat(srcDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	opts, err := j.Archive.options()
+	if err != nil {
+		return err
+	}
+
+	zipName := filepath.Join(outputDir, baseName+"_src.zip")
+	if err := ziputil.CreateFromFolderWithOptions(srcDir, zipName, opts); err != nil {
+		return err
+	}
+
+	return recordArtifact(manifest.Artifact{
+		Output:  zipName,
+		Kind:    "zip",
+		Sources: []string{srcDir},
+		Job:     j.jobName(),
+	})
+}
+
+// renderZip archives a directory, or the files matching a glob, into a zip file
+func renderZip(j job) error {
+	opts, err := j.Archive.options()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.Output), 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	if info, err := os.Stat(j.Source); err == nil && info.IsDir() {
+		if err := ziputil.CreateFromFolderWithOptions(j.Source, j.Output, opts); err != nil {
+			return err
+		}
+	} else {
+		matches, err := findMatches(j.Source, j.Sort)
+		if err != nil {
+			return err
+		}
+
+		// Paths inside the archive are relative to the glob's static prefix
+		base, _ := doublestar.SplitPattern(filepath.ToSlash(j.Source))
+
+		var files []string
+		for _, m := range matches {
+			rel, err := filepath.Rel(base, m)
+			if err != nil {
+				rel = m
+			}
+			if opts.Matches(rel) {
+				files = append(files, m)
+			}
+		}
+
+		if err := ziputil.CreateFromFilesWithOptions(files, base, j.Output, opts); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Created: %s", j.Output)
+	return recordArtifact(manifest.Artifact{
+		Output:  j.Output,
+		Kind:    "zip",
+		Sources: []string{j.Source},
+		Job:     j.jobName(),
+	})
+}
+
+// zipJobPDFs bundles every PDF the job generated into the archive's pdfs path
+func zipJobPDFs(j job) error {
+	var pdfs []string
+	for _, a := range artifacts.Manifest().Artifacts {
+		if a.Job == j.jobName() && a.Kind == "pdf" {
+			pdfs = append(pdfs, a.Output)
+		}
+	}
+	if len(pdfs) == 0 {
+		return fmt.Errorf("no PDFs to archive")
+	}
+
+	opts, err := j.Archive.options()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.Archive.PDFs), 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	if err := ziputil.CreateFromFilesWithOptions(pdfs, commonDir(pdfs), j.Archive.PDFs, opts); err != nil {
+		return err
+	}
+
+	log.Printf("Created: %s", j.Archive.PDFs)
+	return recordArtifact(manifest.Artifact{
+		Output:  j.Archive.PDFs,
+		Kind:    "zip",
+		Sources: pdfs,
+		Job:     j.jobName(),
+	})
+}
+
+// commonDir returns the deepest directory containing every path
+func commonDir(paths []string) string {
+	dir := filepath.Dir(paths[0])
+	for _, p := range paths[1:] {
+		for dir != "." && dir != string(filepath.Separator) &&
+			!strings.HasPrefix(p, dir+string(filepath.Separator)) {
+			dir = filepath.Dir(dir)
+		}
+	}
+	return dir
+}
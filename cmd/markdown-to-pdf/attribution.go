@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/gitinfo"
+)
+
+var (
+	anyHeadingRegex = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]|$)`)
+	underlineRegex  = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`, "~", `\~`)
+)
+
+// attribute adds a line with the authors and last change of a source under
+// its first heading, or at its top when it has none. Sources without git
+// history are left as they are.
+func attribute(content []byte, path string) []byte {
+	a, ok, err := gitinfo.Attribute(path)
+	if err != nil {
+		log.Printf("Warning: attribution for %s: %v", path, err)
+		return content
+	}
+	if !ok {
+		return content
+	}
+
+	authors := make([]string, len(a.Authors))
+	for i, name := range a.Authors {
+		authors[i] = markdownEscaper.Replace(name)
+	}
+	byline := fmt.Sprintf("*Authors: %s · Last modified %s by %s (%s)*",
+		strings.Join(authors, ", "), a.Last.Date.Format("2006-01-02"), markdownEscaper.Replace(a.Last.Author), a.Last.SHA[:min(7, len(a.Last.SHA))])
+
+	lines := strings.SplitAfter(string(content), "\n")
+	at := attributionLine(lines)
+	var b strings.Builder
+	b.WriteString(strings.Join(lines[:at], ""))
+	if at > 0 && !strings.HasSuffix(lines[at-1], "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("\n" + byline + "\n\n")
+	b.WriteString(strings.Join(lines[at:], ""))
+	return []byte(b.String())
+}
+
+// attributionLine returns the index of the line after the first heading of a
+// source, skipping front matter and code blocks, or after the front matter
+// when there is no heading
+func attributionLine(lines []string) int {
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	fence := ""
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		if fence != "" {
+			if m := codeFenceRegex.FindStringSubmatch(line); m != nil && m[1][0] == fence[0] && len(m[1]) >= len(fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := codeFenceRegex.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			continue
+		}
+
+		if anyHeadingRegex.MatchString(line) {
+			return i + 1
+		}
+		// A setext heading is underlined text
+		if i+1 < len(lines) && strings.TrimSpace(line) != "" && underlineRegex.MatchString(strings.TrimRight(lines[i+1], "\r\n")) {
+			return i + 2
+		}
+	}
+	return start
+}
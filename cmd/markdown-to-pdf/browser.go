@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+)
+
+// browserDownload is set by --browser-download, --browser-version,
+// --browser-sha256, and --browser-cache
+var browserDownload struct {
+	enabled bool
+	pdf.Download
+}
+
+// installBrowser downloads the pinned headless shell and points CHROME_BIN at
+// it when a job prints with a local Chrome and none is installed
+func installBrowser(ctx context.Context, jobs []job) error {
+	if !browserDownload.enabled || os.Getenv("CHROME_BIN") != "" || os.Getenv("CHROME_REMOTE_URL") != "" {
+		return nil
+	}
+	local := false
+	for _, j := range jobs {
+		if (j.PDFBackend == "" || j.PDFBackend == "chrome") && j.ChromeURL == "" && j.Type != "zip" {
+			local = true
+		}
+	}
+	if !local || pdf.FindChrome() != "" {
+		return nil
+	}
+
+	d := browserDownload.Download
+	if d.CacheDir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return err
+		}
+		d.CacheDir = filepath.Join(dir, "markdown-pdf-action")
+	}
+	bin, err := d.Install(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Using headless shell: %s", bin)
+	// Also seen by files-dashboard, which dashboard jobs run
+	return os.Setenv("CHROME_BIN", bin)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/changelog"
+)
+
+// changeTypeRegex matches the Added, Changed, ... headings inside a release
+var changeTypeRegex = regexp.MustCompile(`(?m)^###\s+(\w+)\s*$`)
+
+// changelogCSS sets release headers and dates apart from the notes below them
+const changelogCSS = `<style>
+h2.release { display: flex; align-items: baseline; gap: 0.75em; padding: 0.3em 0.6em; border: none; border-left: 4px solid #0969da; background: #f6f8fa; }
+h2.release a { color: inherit; text-decoration: none; }
+h2.release em { margin-left: auto; font-size: 0.7em; font-style: normal; font-weight: normal; color: #57606a; }
+h2.release.yanked { border-left-color: #cf222e; }
+h2.release.yanked strong { font-size: 0.6em; color: #cf222e; }
+h3.change { display: inline-block; margin: 1em 0 0.4em; padding: 0.1em 0.6em; border-radius: 1em; font-size: 0.8em; text-transform: uppercase; letter-spacing: 0.05em; color: #fff; background: #57606a; }
+h3.change-added { background: #1a7f37; }
+h3.change-changed { background: #0969da; }
+h3.change-deprecated { background: #9a6700; }
+h3.change-removed { background: #cf222e; }
+h3.change-fixed { background: #8250df; }
+h3.change-security { background: #bc4c00; }
+</style>`
+
+// renderChangelog renders the releases of a Keep a Changelog file between the
+// job's since and until versions as release notes
+func renderChangelog(ctx context.Context, j job) error {
+	pdfOpts, err := j.pdfOptions()
+	if err != nil {
+		return err
+	}
+
+	matches, err := j.findSources()
+	if err != nil {
+		return err
+	}
+	if len(matches) > 1 {
+		return fmt.Errorf("changelog jobs take one file, %s matches %d", j.Source, len(matches))
+	}
+
+	cfg := j.renderConfig(pdfOpts)
+	cfg.outPath = j.Output
+	cfg.baseDir = filepath.Dir(matches[0])
+	cfg.sources = matches
+
+	src, err := cfg.preRender(ctx, matches[0])
+	if err != nil {
+		return err
+	}
+	notes := changelog.Parse(src)
+
+	releases := notes.Select(j.Since, j.Until)
+	if len(releases) == 0 {
+		return fmt.Errorf("no releases in %s between %q and %q", matches[0], j.Since, j.Until)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.Output), 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	cfg.inject.Head = changelogCSS + cfg.inject.Head
+
+	// The release classes are set with heading attributes
+	cfg.enableAttributes()
+
+	title := notes.Title
+	if title == "" {
+		title = "Release Notes"
+	}
+	if cfg.title == "" {
+		cfg.title = title
+	}
+
+	return renderCombinedMarkdown(ctx, releaseNotes(title, releases), cfg)
+}
+
+// releaseNotes writes the selected releases as markdown, marking release and
+// change type headings with classes for changelogCSS
+func releaseNotes(title string, releases []changelog.Release) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+
+	for _, r := range releases {
+		version := r.Version
+		if r.Link != "" {
+			version = fmt.Sprintf("[%s](%s)", r.Version, r.Link)
+		}
+
+		heading := "## " + version
+		if r.Yanked {
+			heading += " **YANKED**"
+		}
+		if r.Date != "" {
+			heading += " *" + r.Date + "*"
+		}
+		class := ".release"
+		if r.Yanked {
+			class += " .yanked"
+		}
+		fmt.Fprintf(&b, "%s {%s}\n\n", heading, class)
+
+		body := changeTypeRegex.ReplaceAllStringFunc(string(r.Body), func(h string) string {
+			name := changeTypeRegex.FindStringSubmatch(h)[1]
+			return fmt.Sprintf("### %s {.change .change-%s}", name, strings.ToLower(name))
+		})
+		b.WriteString(body)
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
+)
+
+var (
+	atxHeadingRegex    = regexp.MustCompile(`^ {0,3}#(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextHeadingRegex = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	codeFenceRegex     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// chapter is a part of a combined document that is also rendered to its own PDF
+type chapter struct {
+	title   string
+	sources []string
+
+	// Either the markdown of the chapter (single jobs) or its converted body
+	// (combine jobs), whose images are already embedded
+	markdown string
+	html     string
+	baseDir  string
+}
+
+// splitMarkdownChapters splits markdown at its top-level headings, skipping
+// code blocks. Text before the first heading belongs to the first chapter.
+func splitMarkdownChapters(content, baseDir string, sources []string) []chapter {
+	var (
+		chapters []chapter
+		lines    = strings.SplitAfter(content, "\n")
+		start    = 0
+		title    string
+		fence    string
+	)
+
+	split := func(end int, next string) {
+		if text := strings.Join(lines[start:end], ""); strings.TrimSpace(text) != "" {
+			if len(chapters) == 0 && title == "" && next != "" {
+				// Keep a preamble with the first chapter
+				title = next
+				return
+			}
+			chapters = append(chapters, chapter{title: title, markdown: text, baseDir: baseDir, sources: sources})
+			start = end
+		}
+		title = next
+	}
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+
+		if fence != "" {
+			if m := codeFenceRegex.FindStringSubmatch(line); m != nil && m[1][0] == fence[0] && len(m[1]) >= len(fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := codeFenceRegex.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			continue
+		}
+
+		if m := atxHeadingRegex.FindStringSubmatch(line); m != nil {
+			split(i, headingText(m[1]))
+		} else if i > 0 && setextHeadingRegex.MatchString(line) {
+			prev := strings.TrimRight(lines[i-1], "\r\n")
+			if strings.TrimSpace(prev) != "" && !atxHeadingRegex.MatchString(prev) {
+				split(i-1, headingText(prev))
+			}
+		}
+	}
+	split(len(lines), "")
+
+	return chapters
+}
+
+// headingText strips the common inline markup from heading source
+func headingText(s string) string {
+	s = strings.NewReplacer("*", "", "_", "", "`", "").Replace(strings.TrimSpace(s))
+	// Drop a trailing {#id .class} attribute block
+	if i := strings.LastIndex(s, "{"); i > 0 && strings.HasSuffix(s, "}") {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+// chapterFileName turns a chapter title into a file name, e.g. "01-getting-started.pdf"
+func chapterFileName(n int, title string) string {
+	return fmt.Sprintf("%02d-%s.pdf", n, slug(title, "chapter"))
+}
+
+// slug lowercases text and joins its words with hyphens, e.g.
+// "getting-started", or returns fallback when it has none
+func slug(text, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	if b.Len() == 0 {
+		return fallback
+	}
+	return b.String()
+}
+
+// renderChapters renders each chapter of a combined document to its own PDF in
+// dir. Like subfolder renders, a failed chapter is logged and skipped.
+func renderChapters(ctx context.Context, chapters []chapter, dir string, cfg renderConfig) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create chapters directory: %w", err)
+	}
+
+	for i, ch := range chapters {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		title := ch.title
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+
+		c := cfg
+		c.outPath = filepath.Join(dir, chapterFileName(i+1, ch.title))
+		c.title = title
+		c.baseDir = ch.baseDir
+		c.sources = ch.sources
+		c.confluence = nil // the combined document already has the page
+
+		req := render.RenderRequest{Title: title}
+		if ch.html != "" {
+			req.HTML = ch.html
+		} else {
+			req.Markdown = []byte(ch.markdown)
+			req.BaseDir = ch.baseDir
+		}
+
+		if err := renderDocument(ctx, req, c); err != nil {
+			log.Printf("Render chapter %s: %v", c.outPath, err)
+		}
+	}
+
+	return nil
+}
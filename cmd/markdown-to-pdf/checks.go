@@ -0,0 +1,18 @@
+package main
+
+import "fmt"
+
+// Modes of the optional document checks
+const (
+	checkWarn  = "warn"  // log the findings and record them as document warnings
+	checkError = "error" // fail the document
+)
+
+// validateCheckMode checks the mode of an optional document check
+func validateCheckMode(value string) error {
+	switch value {
+	case "", checkWarn, checkError:
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q (use warn or error)", value)
+}
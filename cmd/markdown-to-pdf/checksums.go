@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+)
+
+// checksumConfig configures the SHA256SUMS file written when the run ends
+type checksumConfig struct {
+	path string // checksums file; it covers every file in its directory
+	sign string // gpg | cosign; sign the checksums file
+	key  string // GPG key ID, or cosign key reference (keyless signing when empty)
+}
+
+// checksums is set by --checksums, --sign, and --sign-key
+var checksums checksumConfig
+
+// signatures returns the paths signing writes next to the checksums file
+func (c checksumConfig) signatures() []string {
+	return []string{c.path + ".asc", c.path + ".sig", c.path + ".sigstore.json"}
+}
+
+// validate checks the signing options
+func (c checksumConfig) validate() error {
+	switch c.sign {
+	case "", "gpg", "cosign":
+	default:
+		return fmt.Errorf("unknown signer %q (use gpg or cosign)", c.sign)
+	}
+	if c.sign != "" && c.path == "" {
+		return fmt.Errorf("--sign needs --checksums")
+	}
+	return nil
+}
+
+// writeChecksums writes the SHA-256 of every file in the checksums file's
+// directory, in the format sha256sum -c reads, and signs it when configured
+func writeChecksums(ctx context.Context, c checksumConfig) error {
+	dir := filepath.Dir(c.path)
+	skip := append(c.signatures(), c.path)
+
+	var lines []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || slices.Contains(skip, path) {
+			return err
+		}
+		sum, _, err := manifest.Checksum(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, sum+"  "+filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sort by path so the file is stable across runs
+	slices.SortFunc(lines, func(a, b string) int {
+		return strings.Compare(a[66:], b[66:])
+	})
+	if err := os.WriteFile(c.path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		return fmt.Errorf("write checksums: %w", err)
+	}
+	log.Printf("Checksums of %d files written: %s", len(lines), c.path)
+
+	if c.sign == "" {
+		return nil
+	}
+	signature, err := signChecksums(ctx, c)
+	if err != nil {
+		return fmt.Errorf("sign %s: %w", c.path, err)
+	}
+	log.Printf("Checksums signed: %s", signature)
+	return nil
+}
+
+// signChecksums signs the checksums file with gpg (GPG_BIN) or cosign
+// (COSIGN_BIN) and returns the signature path
+func signChecksums(ctx context.Context, c checksumConfig) (string, error) {
+	var cmd *exec.Cmd
+	var signature string
+
+	switch c.sign {
+	case "gpg":
+		home, err := importGPGKey(ctx)
+		if err != nil {
+			return "", err
+		}
+		if home != "" {
+			defer os.RemoveAll(home)
+		}
+
+		signature = c.path + ".asc"
+		args := []string{"--batch", "--yes", "--armor", "--detach-sign", "--output", signature}
+		if c.key != "" {
+			args = append(args, "--local-user", c.key)
+		}
+		passphrase, ok := os.LookupEnv("GPG_PASSPHRASE")
+		if ok {
+			args = append(args, "--pinentry-mode", "loopback", "--passphrase-fd", "0")
+		}
+		cmd = exec.CommandContext(ctx, binary("GPG_BIN", "gpg"), append(args, c.path)...)
+		if ok {
+			cmd.Stdin = strings.NewReader(passphrase + "\n")
+		}
+		if home != "" {
+			cmd.Env = append(os.Environ(), "GNUPGHOME="+home)
+		}
+
+	case "cosign":
+		// With a key cosign writes a plain signature; keyless signing uses
+		// the workflow's OIDC identity and writes a bundle with the certificate
+		args := []string{"sign-blob", "--yes"}
+		if c.key != "" {
+			signature = c.path + ".sig"
+			args = append(args, "--key", c.key, "--output-signature", signature)
+		} else {
+			signature = c.path + ".sigstore.json"
+			args = append(args, "--bundle", signature)
+		}
+		cmd = exec.CommandContext(ctx, binary("COSIGN_BIN", "cosign"), append(args, c.path)...)
+	}
+
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("run %s: %w", cmd.Path, err)
+	}
+	return signature, nil
+}
+
+// importGPGKey imports the armored secret key in GPG_PRIVATE_KEY into a
+// temporary keyring and returns its directory, or "" to use the default one
+func importGPGKey(ctx context.Context) (string, error) {
+	key := os.Getenv("GPG_PRIVATE_KEY")
+	if key == "" {
+		return "", nil
+	}
+
+	home, err := os.MkdirTemp("", "markdown-to-pdf-gnupg-*")
+	if err != nil {
+		return "", fmt.Errorf("create keyring: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, binary("GPG_BIN", "gpg"), "--batch", "--import")
+	cmd.Env = append(os.Environ(), "GNUPGHOME="+home)
+	cmd.Stdin = strings.NewReader(key)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(home)
+		return "", fmt.Errorf("import GPG_PRIVATE_KEY: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return home, nil
+}
+
+// binary returns the command named by env, or name on PATH
+func binary(env, name string) string {
+	if bin := os.Getenv(env); bin != "" {
+		return bin
+	}
+	return name
+}
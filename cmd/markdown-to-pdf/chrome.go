@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
+)
+
+// chromeConfig passes network, language, and extra launch options to Chrome
+type chromeConfig struct {
+	Flags             []string `yaml:"flags"`               // extra command line flags, e.g. ["--font-render-hinting=none"]
+	Proxy             string   `yaml:"proxy"`               // proxy server, e.g. http://proxy.corp:3128
+	ProxyBypass       string   `yaml:"proxy_bypass"`        // hosts that skip the proxy, e.g. "localhost;*.corp.example.com"
+	HostResolverRules string   `yaml:"host_resolver_rules"` // e.g. "MAP assets.example.com 10.0.0.5"
+	Lang              string   `yaml:"lang"`                // browser language, e.g. de-DE
+}
+
+// validate checks the proxy URL and flag names
+func (c chromeConfig) validate() error {
+	if c.Proxy != "" {
+		u, err := url.Parse(c.Proxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("proxy must be a URL such as http://proxy:3128, got %q", c.Proxy)
+		}
+	}
+	for _, f := range c.Flags {
+		if name, _ := pdf.ParseChromeFlag(f); name == "" {
+			return fmt.Errorf("invalid flag %q", f)
+		}
+	}
+	return nil
+}
+
+// apply copies the options into the PDF settings
+func (c chromeConfig) apply(opts *render.PDFOptions) {
+	opts.ChromeFlags = c.Flags
+	opts.ProxyServer = c.Proxy
+	opts.ProxyBypass = c.ProxyBypass
+	opts.HostResolverRules = c.HostResolverRules
+	opts.Language = c.Lang
+}
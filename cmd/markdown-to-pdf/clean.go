@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+)
+
+// Values of the clean option
+const (
+	cleanOff    = "false"
+	cleanOn     = "true"
+	cleanDryRun = "dry-run"
+)
+
+// cleanDefault is the clean policy of jobs that don't set their own; set by --clean
+var cleanDefault string
+
+// validateClean checks a clean option
+func validateClean(value string) error {
+	switch value {
+	case "", cleanOff, cleanOn, cleanDryRun:
+		return nil
+	}
+	return fmt.Errorf("unknown clean value %q (use true, false, or dry-run)", value)
+}
+
+// cleanPolicy returns the job's clean option, falling back to --clean
+func (j job) cleanPolicy() string {
+	if j.Clean != "" {
+		return j.Clean
+	}
+	return cleanDefault
+}
+
+// outputClaims returns the files and directories a job writes to, which
+// cleaning other jobs leaves alone
+func (j job) outputClaims() (files, dirs []string) {
+	switch j.Type {
+	case "subfolders":
+		dirs = append(dirs, j.Output)
+	case "dashboard":
+		// files-dashboard writes each format next to the output
+		base := strings.TrimSuffix(j.Output, filepath.Ext(j.Output))
+		files = append(files, j.Output, base+".html", base+".md", base+".pdf")
+	default:
+		files = append(files, j.Output)
+	}
+	if j.Chapters != "" {
+		dirs = append(dirs, j.Chapters)
+	}
+	if j.Archive.PDFs != "" {
+		files = append(files, j.Archive.PDFs)
+	}
+	return files, dirs
+}
+
+// cleanRoots returns the directories whose subfolders a job writes to: the
+// output directory of subfolders jobs and the chapters directory
+func (j job) cleanRoots() []string {
+	var roots []string
+	if j.Type == "subfolders" {
+		roots = append(roots, j.Output)
+	}
+	if j.Chapters != "" {
+		roots = append(roots, j.Chapters)
+	}
+	return roots
+}
+
+// cleanStatePath is where the outputs of cleaning jobs are remembered between
+// runs, as a manifest. Clean only ever removes files listed there.
+const cleanStatePath = ".markdown-to-pdf-outputs.json"
+
+// cleanState holds the outputs the previous run recorded and the jobs whose
+// records this run replaces
+var cleanState struct {
+	mu       sync.Mutex
+	loaded   bool
+	previous []manifest.Artifact
+	cleaned  map[string]bool
+}
+
+// cleans reports whether a job removes or lists stale outputs
+func (j job) cleans() bool {
+	policy := j.cleanPolicy()
+	return policy == cleanOn || policy == cleanDryRun
+}
+
+// loadCleanState reads the outputs recorded by the previous run when any job cleans
+func loadCleanState(jobs []job) {
+	if !slices.ContainsFunc(jobs, job.cleans) {
+		return
+	}
+	cleanState.loaded = true
+	cleanState.cleaned = make(map[string]bool)
+
+	m, err := manifest.Load(cleanStatePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Warning: clean: %v", err)
+		}
+		return
+	}
+	cleanState.previous = m.Artifacts
+}
+
+// saveCleanState records the PDFs and zips of the cleaning jobs for the next
+// run. Jobs that failed or only listed stale files keep their old records too.
+func saveCleanState(jobs []job) {
+	if !cleanState.loaded {
+		return
+	}
+	cleaning := make(map[string]bool)
+	for _, j := range jobs {
+		if j.cleans() {
+			cleaning[j.jobName()] = true
+		}
+	}
+
+	current := artifacts.Manifest()
+	var records []manifest.Artifact
+	seen := make(map[string]bool)
+	keep := func(a manifest.Artifact) {
+		if (a.Kind == "pdf" || a.Kind == "zip") && !seen[filepath.Clean(a.Output)] {
+			seen[filepath.Clean(a.Output)] = true
+			records = append(records, a)
+		}
+	}
+	for _, a := range current.Artifacts {
+		if cleaning[a.Job] {
+			keep(a)
+		}
+	}
+	for _, a := range cleanState.previous {
+		if !cleanState.cleaned[a.Job] {
+			keep(a)
+		}
+	}
+	slices.SortFunc(records, func(a, b manifest.Artifact) int { return strings.Compare(a.Output, b.Output) })
+
+	m := manifest.Manifest{Version: manifest.Version, GeneratedAt: current.GeneratedAt, Artifacts: records}
+	if err := manifest.Save(m, cleanStatePath); err != nil {
+		log.Printf("Warning: clean: %v", err)
+	}
+}
+
+// cleanOutputs removes the PDFs and zips a finished job wrote in an earlier
+// run but not in this one, such as the PDF of a folder that was renamed. Only
+// files recorded for the job in the clean state are candidates, so files the
+// tool never wrote are left alone. With dry-run they are only listed. Files
+// other jobs write to are left alone, and failures are logged rather than
+// failing the job.
+func cleanOutputs(j job, jobs []job) {
+	if !j.cleans() {
+		return
+	}
+	policy := j.cleanPolicy()
+
+	generated := make(map[string]bool)
+	for _, a := range artifacts.Manifest().Artifacts {
+		generated[filepath.Clean(a.Output)] = true
+	}
+
+	ownFiles, ownDirs := j.outputClaims()
+	for _, f := range ownFiles {
+		ownDirs = append(ownDirs, filepath.Dir(f))
+	}
+	var claimedFiles, claimedDirs []string
+	for _, other := range jobs {
+		if other.jobName() == j.jobName() {
+			continue
+		}
+		files, dirs := other.outputClaims()
+		claimedFiles = append(claimedFiles, files...)
+		for _, d := range dirs {
+			// A directory holding this job's own outputs would protect all of them
+			if !slices.ContainsFunc(ownDirs, func(own string) bool { return within(own, d) }) {
+				claimedDirs = append(claimedDirs, d)
+			}
+		}
+	}
+
+	stale := func(path string) bool {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".pdf", ".zip":
+		default:
+			return false
+		}
+		path = filepath.Clean(path)
+		if generated[path] || slices.ContainsFunc(claimedFiles, func(f string) bool { return filepath.Clean(f) == path }) {
+			return false
+		}
+		return !slices.ContainsFunc(claimedDirs, func(d string) bool { return within(path, d) })
+	}
+
+	cleanState.mu.Lock()
+	previous := slices.Clone(cleanState.previous)
+	cleanState.mu.Unlock()
+
+	var removed []string
+	for _, a := range previous {
+		if a.Job != j.jobName() || !stale(a.Output) || slices.Contains(removed, filepath.Clean(a.Output)) {
+			continue
+		}
+		if info, err := os.Lstat(a.Output); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		removed = append(removed, filepath.Clean(a.Output))
+	}
+
+	if policy == cleanDryRun {
+		for _, path := range removed {
+			log.Printf("Clean (dry run) %s: would remove stale %s", j.jobName(), path)
+		}
+		return
+	}
+
+	roots := j.cleanRoots()
+	for _, path := range removed {
+		if err := os.Remove(path); err != nil {
+			log.Printf("Warning: clean %s: %v", j.jobName(), err)
+			continue
+		}
+		log.Printf("Clean %s: removed stale %s", j.jobName(), path)
+
+		// Remove the folders renamed sources leave empty
+		for dir := filepath.Dir(path); slices.ContainsFunc(roots, func(root string) bool {
+			return within(dir, root) && filepath.Clean(dir) != filepath.Clean(root)
+		}); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break // not empty
+			}
+		}
+	}
+
+	cleanState.mu.Lock()
+	cleanState.cleaned[j.jobName()] = true
+	cleanState.mu.Unlock()
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
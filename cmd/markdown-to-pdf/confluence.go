@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/confluence"
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
+)
+
+// confluenceConfig exports a job's documents to Confluence pages
+type confluenceConfig struct {
+	URL     string                      `yaml:"url"`     // site URL, e.g. https://acme.atlassian.net/wiki
+	Space   string                      `yaml:"space"`   // space key of the pages
+	Parent  string                      `yaml:"parent"`  // ID of the page new pages are created under
+	Mode    string                      `yaml:"mode"`    // page (default) | attachment | both
+	Folders map[string]confluenceTarget `yaml:"folders"` // source folders mapped to other spaces or parent pages
+}
+
+// confluenceTarget places the pages of documents from one source folder
+type confluenceTarget struct {
+	Space  string `yaml:"space"`
+	Parent string `yaml:"parent"`
+}
+
+// enabled reports whether the job exports to Confluence
+func (c confluenceConfig) enabled() bool {
+	return c.URL != ""
+}
+
+// validate checks the export settings and credentials
+func (c confluenceConfig) validate() error {
+	if !c.enabled() {
+		return nil
+	}
+	switch c.Mode {
+	case "", "page", "attachment", "both":
+	default:
+		return fmt.Errorf("unknown mode %q (use page, attachment, or both)", c.Mode)
+	}
+	if c.Space == "" {
+		for folder, t := range c.Folders {
+			if t.Space == "" {
+				return fmt.Errorf("space is required (folder %s sets none)", folder)
+			}
+		}
+		if len(c.Folders) == 0 {
+			return fmt.Errorf("space is required")
+		}
+	}
+	_, err := confluence.NewClient(c.URL)
+	return err
+}
+
+// target returns the space and parent page of a document, using the deepest
+// configured folder containing its source
+func (c confluenceConfig) target(source string) confluenceTarget {
+	t := confluenceTarget{Space: c.Space, Parent: c.Parent}
+
+	dir := filepath.ToSlash(filepath.Clean(filepath.Dir(source)))
+	best := -1
+	for folder, ft := range c.Folders {
+		folder = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(folder)), "/")
+		if dir != folder && !strings.HasPrefix(dir, folder+"/") || len(folder) <= best {
+			continue
+		}
+		best = len(folder)
+		if ft.Space != "" {
+			t.Space = ft.Space
+		}
+		t.Parent = ft.Parent
+	}
+	return t
+}
+
+// exportConfluence pushes a rendered document to its Confluence page: the
+// document body, the PDF as an attachment, or both
+func exportConfluence(ctx context.Context, res render.Result, cfg renderConfig) error {
+	c := cfg.confluence
+	client, err := confluence.NewClient(c.URL)
+	if err != nil {
+		return err
+	}
+
+	source := cfg.outPath
+	if len(cfg.sources) > 0 {
+		source = cfg.sources[0]
+	}
+	t := c.target(source)
+
+	page := confluence.Page{
+		Space:  t.Space,
+		Parent: t.Parent,
+		Title:  cfg.title,
+	}
+	if page.Title == "" {
+		page.Title = strings.TrimSuffix(filepath.Base(cfg.outPath), filepath.Ext(cfg.outPath))
+	}
+
+	pdfName := filepath.Base(cfg.outPath)
+	if c.Mode != "attachment" {
+		body, images, err := confluence.Storage(res.HTML)
+		if err != nil {
+			return err
+		}
+		page.Body = body
+		page.Attachments = images
+	}
+	if c.Mode == "attachment" || c.Mode == "both" {
+		data, err := os.ReadFile(cfg.outPath)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", cfg.outPath, err)
+		}
+		page.Attachments = append(page.Attachments, confluence.Attachment{Name: pdfName, ContentType: "application/pdf", Data: data})
+	}
+	switch c.Mode {
+	case "attachment":
+		page.Body = `<ac:structured-macro ac:name="viewpdf"><ac:parameter ac:name="name"><ri:attachment ri:filename="` + attrEscape(pdfName) + `"></ri:attachment></ac:parameter></ac:structured-macro>`
+	case "both":
+		page.Body = `<p><ac:link><ri:attachment ri:filename="` + attrEscape(pdfName) + `"></ri:attachment></ac:link></p>` + page.Body
+	}
+
+	pageURL, err := client.Publish(ctx, page)
+	if err != nil {
+		return fmt.Errorf("confluence: %w", err)
+	}
+	log.Printf("Exported to Confluence: %s -> %s", cfg.outPath, pageURL)
+	return nil
+}
+
+// attrEscape escapes a value for an XML attribute
+func attrEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+)
+
+// runDashboard builds a files dashboard for the job's source directory using the
+// files-dashboard tool (FILES_DASHBOARD_BIN, or files-dashboard on PATH)
+func runDashboard(ctx context.Context, j job) error {
+	bin := os.Getenv("FILES_DASHBOARD_BIN")
+	if bin == "" {
+		bin = "files-dashboard"
+	}
+
+	args := []string{"-source", j.Source, "-output", j.Output}
+	if j.Format != "" {
+		args = append(args, "-format", j.Format)
+	}
+	if j.Sort != "" {
+		args = append(args, "-sort", j.Sort)
+	}
+	if checksums.path != "" {
+		args = append(args, "-checksums")
+	}
+
+	// Link to files earlier jobs published rather than their local copies
+	published, err := publishedManifest()
+	if err != nil {
+		return err
+	}
+	if published != "" {
+		defer os.Remove(published)
+		args = append(args, "-published", published)
+	}
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run %s: %w", bin, err)
+	}
+	return nil
+}
+
+// publishedManifest writes the artifacts recorded so far to a temporary manifest
+// when any of them were published, and returns its path
+func publishedManifest() (string, error) {
+	m := artifacts.Manifest()
+	if !slices.ContainsFunc(m.Artifacts, func(a manifest.Artifact) bool { return a.URL != "" }) {
+		return "", nil
+	}
+
+	f, err := os.CreateTemp("", "published-*.json")
+	if err != nil {
+		return "", fmt.Errorf("create published manifest: %w", err)
+	}
+	f.Close()
+
+	if err := manifest.Save(m, f.Name()); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("write published manifest: %w", err)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+	"github.com/kuzik/markdown-pdf-action/internal/visualdiff"
+)
+
+//go:embed diff_report.html
+var diffReportTemplate string
+
+// diffDPI is the resolution pages are rasterized at for comparison
+const diffDPI = 72
+
+// Document and page statuses in the diff report
+const (
+	diffAdded     = "added"
+	diffRemoved   = "removed"
+	diffChanged   = "changed"
+	diffUnchanged = "unchanged"
+)
+
+// diffConfig configures the visual comparison against previous PDFs
+type diffConfig struct {
+	against   string  // directory holding the previous PDFs
+	root      string  // directory the new PDFs are matched from; defaults to their common directory
+	report    string  // directory the HTML report and page images are written to
+	threshold float64 // pages differing by at most this percentage count as unchanged
+}
+
+type diffPage struct {
+	Number  int
+	Status  string
+	Percent float64
+
+	// Page images relative to the report
+	Old  string
+	New  string
+	Diff string
+}
+
+type diffDocument struct {
+	Path   string // relative to the compared roots
+	Status string
+	Pages  []diffPage
+
+	// Largest page difference in percent
+	MaxPercent float64
+}
+
+type diffReport struct {
+	Against   string
+	Root      string
+	Threshold float64
+	Documents []diffDocument
+	Counts    map[string]int
+}
+
+// runDiff compares the PDFs generated in this run with the same paths under
+// cfg.against and writes an HTML report highlighting changed pages
+func runDiff(ctx context.Context, cfg diffConfig) error {
+	var newPDFs []string
+	for _, a := range artifacts.Manifest().Artifacts {
+		if a.Kind == "pdf" {
+			newPDFs = append(newPDFs, filepath.Clean(a.Output))
+		}
+	}
+	if len(newPDFs) == 0 {
+		return fmt.Errorf("no PDFs were generated")
+	}
+
+	root := cfg.root
+	if root == "" {
+		root = commonDir(newPDFs)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "pdf-diff-*")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.MkdirAll(cfg.report, 0o755); err != nil {
+		return fmt.Errorf("create report directory: %w", err)
+	}
+
+	report := diffReport{Against: cfg.against, Root: root, Threshold: cfg.threshold, Counts: map[string]int{}}
+	seen := make(map[string]bool)
+
+	for i, newPath := range newPDFs {
+		rel, err := filepath.Rel(root, newPath)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			log.Printf("Warning: %s is outside the diff root %s, skipped", newPath, root)
+			continue
+		}
+		seen[filepath.ToSlash(rel)] = true
+
+		oldPath := filepath.Join(cfg.against, rel)
+		if _, err := os.Stat(oldPath); err != nil {
+			oldPath = ""
+		}
+
+		doc, err := diffDocumentPages(ctx, oldPath, newPath, filepath.Join(tmpDir, fmt.Sprint(i)), cfg, fmt.Sprintf("doc%03d", i+1))
+		if err != nil {
+			return fmt.Errorf("compare %s: %w", rel, err)
+		}
+		doc.Path = filepath.ToSlash(rel)
+		report.Documents = append(report.Documents, doc)
+	}
+
+	// PDFs that no longer exist
+	err = filepath.Walk(cfg.against, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".pdf") {
+			return err
+		}
+		rel, _ := filepath.Rel(cfg.against, path)
+		if !seen[filepath.ToSlash(rel)] {
+			report.Documents = append(report.Documents, diffDocument{Path: filepath.ToSlash(rel), Status: diffRemoved})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scan %s: %w", cfg.against, err)
+	}
+
+	sort.Slice(report.Documents, func(i, j int) bool {
+		return report.Documents[i].Path < report.Documents[j].Path
+	})
+	for _, doc := range report.Documents {
+		report.Counts[doc.Status]++
+	}
+
+	if err := writeDiffReport(report, cfg.report); err != nil {
+		return err
+	}
+
+	log.Printf("Visual diff: %d changed, %d added, %d removed, %d unchanged; report written: %s",
+		report.Counts[diffChanged], report.Counts[diffAdded], report.Counts[diffRemoved], report.Counts[diffUnchanged],
+		filepath.Join(cfg.report, "index.html"))
+	return nil
+}
+
+// diffDocumentPages rasterizes both versions of a document and compares them page by page.
+// Images of pages that differ are saved in the report under prefix.
+func diffDocumentPages(ctx context.Context, oldPath, newPath, workDir string, cfg diffConfig, prefix string) (diffDocument, error) {
+	newPages, err := pdf.Rasterize(ctx, newPath, filepath.Join(workDir, "new"), diffDPI)
+	if err != nil {
+		return diffDocument{}, err
+	}
+
+	// New documents are listed without page images
+	if oldPath == "" {
+		doc := diffDocument{Status: diffAdded}
+		for n := range newPages {
+			doc.Pages = append(doc.Pages, diffPage{Number: n + 1, Status: diffAdded})
+		}
+		return doc, nil
+	}
+
+	oldPages, err := pdf.Rasterize(ctx, oldPath, filepath.Join(workDir, "old"), diffDPI)
+	if err != nil {
+		return diffDocument{}, err
+	}
+
+	doc := diffDocument{Status: diffUnchanged}
+
+	for n := 0; n < max(len(oldPages), len(newPages)); n++ {
+		page := diffPage{Number: n + 1, Percent: 100}
+		name := fmt.Sprintf("%s-page%03d", prefix, n+1)
+
+		switch {
+		case n >= len(oldPages):
+			page.Status = diffAdded
+			page.New, err = copyReportImage(newPages[n], cfg.report, name+"-new.png")
+		case n >= len(newPages):
+			page.Status = diffRemoved
+			page.Old, err = copyReportImage(oldPages[n], cfg.report, name+"-old.png")
+		default:
+			var res visualdiff.Result
+			if res, err = visualdiff.CompareFiles(oldPages[n], newPages[n]); err != nil {
+				break
+			}
+			page.Percent = res.Percent
+			page.Status = diffUnchanged
+			if res.Percent <= cfg.threshold {
+				break
+			}
+
+			page.Status = diffChanged
+			if page.Old, err = copyReportImage(oldPages[n], cfg.report, name+"-old.png"); err != nil {
+				break
+			}
+			if page.New, err = copyReportImage(newPages[n], cfg.report, name+"-new.png"); err != nil {
+				break
+			}
+			page.Diff = name + "-diff.png"
+			err = visualdiff.Save(res.Diff, filepath.Join(cfg.report, page.Diff))
+		}
+		if err != nil {
+			return doc, err
+		}
+
+		if page.Status != diffUnchanged && doc.Status == diffUnchanged {
+			doc.Status = diffChanged
+		}
+		doc.MaxPercent = max(doc.MaxPercent, page.Percent)
+		doc.Pages = append(doc.Pages, page)
+	}
+
+	return doc, nil
+}
+
+// copyReportImage copies a page image into the report directory and returns its name
+func copyReportImage(src, reportDir, name string) (string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("read page image: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(reportDir, name), data, 0o644); err != nil {
+		return "", fmt.Errorf("write page image: %w", err)
+	}
+	return name, nil
+}
+
+// writeDiffReport renders the report's index.html
+func writeDiffReport(report diffReport, reportDir string) error {
+	tmpl, err := template.New("diff").Parse(diffReportTemplate)
+	if err != nil {
+		return fmt.Errorf("parse diff report template: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(reportDir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("create diff report: %w", err)
+	}
+	defer f.Close()
+
+	if err := tmpl.Execute(f, report); err != nil {
+		return fmt.Errorf("execute diff report template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/kuzik/markdown-pdf-action/internal/frontmatter"
+)
+
+// includeDrafts renders draft documents too; set by --include-drafts
+var includeDrafts bool
+
+// errAllDrafts is returned when every source of a job is a draft
+var errAllDrafts = errors.New("all sources are drafts")
+
+// findSources finds the job's markdown files (or the downloads of its URL
+// sources), leaving out drafts
+func (j job) findSources() ([]string, error) {
+	var matches []string
+	var err error
+	if j.fetched != nil {
+		matches = j.fetched
+	} else {
+		matches, err = findMatches(j.Source, j.Sort)
+	}
+	if err != nil || includeDrafts {
+		return matches, err
+	}
+
+	var published []string
+	for _, m := range matches {
+		draft, err := j.isDraft(m)
+		if err != nil {
+			return nil, err
+		}
+		if draft {
+			log.Printf("Skipping draft: %s", m)
+			continue
+		}
+		published = append(published, m)
+	}
+
+	if len(published) == 0 {
+		return nil, errAllDrafts
+	}
+	return published, nil
+}
+
+// isDraft reports whether a file matches one of the job's draft globs or
+// declares draft: true in its front matter
+func (j job) isDraft(path string) (bool, error) {
+	slashed := filepath.ToSlash(path)
+	for _, pattern := range j.Drafts {
+		if ok, _ := doublestar.Match(pattern, slashed); ok {
+			return true, nil
+		}
+	}
+
+	fields, err := frontmatter.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("read front matter of %s: %w", path, err)
+	}
+	return frontmatter.Bool(fields, "draft"), nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/mail"
+)
+
+// emailConfig mails a job's PDFs once it succeeds
+type emailConfig struct {
+	To      []string `yaml:"to"`      // recipient addresses
+	Cc      []string `yaml:"cc"`      // copied addresses
+	Subject string   `yaml:"subject"` // defaults to the job name
+	Body    string   `yaml:"body"`    // text before the list of files
+	Attach  *bool    `yaml:"attach"`  // attach the PDFs (default true); false sends only the list
+}
+
+// enabled reports whether the job sends email
+func (e emailConfig) enabled() bool {
+	return len(e.To)+len(e.Cc) > 0
+}
+
+// validate checks the recipients and the SMTP settings
+func (e emailConfig) validate() error {
+	if !e.enabled() {
+		return nil
+	}
+	if _, err := mail.ParseAddresses(strings.Join(slices.Concat(e.To, e.Cc), ",")); err != nil {
+		return err
+	}
+	_, err := mail.ServerFromEnv()
+	return err
+}
+
+// emailJob sends the PDFs the job generated to its recipients, listing each
+// file with its public URL when it was published
+func emailJob(j job) error {
+	e := j.Email
+	if !e.enabled() {
+		return nil
+	}
+
+	server, err := mail.ServerFromEnv()
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+
+	// Entries may themselves be comma-separated lists
+	to, _ := mail.ParseAddresses(strings.Join(e.To, ","))
+	cc, _ := mail.ParseAddresses(strings.Join(e.Cc, ","))
+	msg := mail.Message{To: to, Cc: cc, Subject: e.Subject}
+	if msg.Subject == "" {
+		msg.Subject = j.jobName()
+	}
+
+	var body strings.Builder
+	if e.Body != "" {
+		body.WriteString(strings.TrimSpace(e.Body) + "\n\n")
+	}
+
+	attach := e.Attach == nil || *e.Attach
+	count := 0
+	for _, a := range artifacts.Job(j.jobName()) {
+		if a.Kind != "pdf" {
+			continue
+		}
+		count++
+
+		line := "- " + filepath.Base(a.Output)
+		if a.URL != "" {
+			line += ": " + a.URL
+		}
+		body.WriteString(line + "\n")
+
+		if attach {
+			data, err := os.ReadFile(a.Output)
+			if err != nil {
+				return fmt.Errorf("email: read %s: %w", a.Output, err)
+			}
+			msg.Attachments = append(msg.Attachments, mail.Attachment{Name: filepath.Base(a.Output), ContentType: "application/pdf", Data: data})
+		}
+	}
+	if count == 0 {
+		log.Printf("Job %s: no PDFs to email", j.jobName())
+		return nil
+	}
+	msg.Body = body.String()
+
+	if err := server.Send(msg); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	log.Printf("Emailed %d PDFs to %s", count, strings.Join(append(to, cc...), ", "))
+	return nil
+}
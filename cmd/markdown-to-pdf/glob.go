@@ -0,0 +1,94 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// sourceFS is os.DirFS(root) without the symlinks that point back at a folder
+// they are inside of, so ** patterns follow symlinked docs trees without looping
+type sourceFS struct {
+	fs.FS
+	root string
+}
+
+func newSourceFS(root string) sourceFS {
+	// Resolved symlinks are only comparable as absolute paths
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		abs = root
+	}
+	return sourceFS{FS: os.DirFS(root), root: abs}
+}
+
+// ReadDir lists the named directory, leaving out symlinks to it or to one of
+// the folders on its path
+func (s sourceFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(s.FS, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var ancestors []string
+	for p := name; ; p = path.Dir(p) {
+		if real, err := filepath.EvalSymlinks(filepath.Join(s.root, filepath.FromSlash(p))); err == nil {
+			ancestors = append(ancestors, real)
+		}
+		if p == "." {
+			break
+		}
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.Type()&fs.ModeSymlink != 0 {
+			link := filepath.Join(s.root, filepath.FromSlash(path.Join(name, e.Name())))
+			if target, err := filepath.EvalSymlinks(link); err == nil && containsAny(target, ancestors) {
+				continue
+			}
+		}
+		kept = append(kept, e)
+	}
+	return kept, nil
+}
+
+// containsAny reports whether dir is one of paths or a parent of one
+func containsAny(dir string, paths []string) bool {
+	for _, p := range paths {
+		if p == dir || strings.HasPrefix(p, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isREADME reports whether path names a README.md (or Org-mode README.org), in
+// any letter case
+func isREADME(path string) bool {
+	base := filepath.Base(path)
+	return strings.EqualFold(base, "README.md") || strings.EqualFold(base, "README.org")
+}
+
+// caselessREADME makes a trailing README.md (or README.org) in a glob pattern
+// match readme.md, Readme.md, and other spellings
+func caselessREADME(pattern string) string {
+	name := path.Base(pattern)
+	if !isREADME(name) {
+		return pattern
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		lower, upper := unicode.ToLower(r), unicode.ToUpper(r)
+		if lower == upper {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString("[" + string(upper) + string(lower) + "]")
+	}
+	return strings.TrimSuffix(pattern, name) + b.String()
+}
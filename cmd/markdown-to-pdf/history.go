@@ -0,0 +1,40 @@
+package main
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/gitinfo"
+)
+
+// historyTemplate renders the "Document history" page appended to a document
+var historyTemplate = template.Must(template.New("history").Parse(`
+<section class="document-history" style="page-break-before: always">
+<h2>Document history</h2>
+<table>
+<thead><tr><th>Date</th><th>Author</th><th>Commit</th><th>Change</th></tr></thead>
+<tbody>
+{{range .}}<tr><td>{{.Date.Format "2006-01-02"}}</td><td>{{.Author}}</td><td><code>{{slice .SHA 0 7}}</code></td><td>{{.Subject}}</td></tr>
+{{end}}</tbody>
+</table>
+</section>
+`))
+
+// historyAppendix returns a page listing the last n commits touching sources,
+// or "" when history is disabled or none is found
+func historyAppendix(sources []string, n int) (string, error) {
+	if n <= 0 || len(sources) == 0 {
+		return "", nil
+	}
+
+	commits, err := gitinfo.History(sources, n)
+	if err != nil || len(commits) == 0 {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := historyTemplate.Execute(&buf, commits); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kuzik/markdown-pdf-action/internal/frontmatter"
+	"github.com/kuzik/markdown-pdf-action/internal/orgmode"
+)
+
+// builtinPrefix marks a hook as a built-in transform instead of a shell command
+const builtinPrefix = "builtin:"
+
+var htmlCommentRegex = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// builtinTransforms are hooks available without external commands
+var builtinTransforms = map[string]func([]byte) []byte{
+	// Drop a leading YAML front matter block
+	"strip-front-matter": func(b []byte) []byte {
+		_, body, _ := frontmatter.Split(b)
+		return body
+	},
+	// Remove <!-- ... --> comments, e.g. review notes
+	"strip-html-comments": func(b []byte) []byte {
+		return htmlCommentRegex.ReplaceAll(b, nil)
+	},
+}
+
+// hookContext identifies the document a hook runs for
+type hookContext struct {
+	source string
+	output string
+}
+
+// runHooks pipes content through each hook in order. Commands run with sh, read
+// the content on stdin, and write the replacement to stdout; SOURCE_PATH and
+// OUTPUT_PATH tell them which document they are transforming.
+func runHooks(ctx context.Context, hooks []string, content []byte, hc hookContext) ([]byte, error) {
+	for _, hook := range hooks {
+		if name, ok := strings.CutPrefix(hook, builtinPrefix); ok {
+			transform, known := builtinTransforms[name]
+			if !known {
+				return nil, fmt.Errorf("unknown built-in hook %q", name)
+			}
+			content = transform(content)
+			continue
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", hook)
+		cmd.Stdin = bytes.NewReader(content)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		cmd.Env = append(os.Environ(), "SOURCE_PATH="+hc.source, "OUTPUT_PATH="+hc.output)
+
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("hook %q: %w: %s", hook, err, strings.TrimSpace(stderr.String()))
+		}
+		content = stdout.Bytes()
+	}
+	return content, nil
+}
+
+// preRender reads a markdown file, converting Org-mode documents to markdown,
+// and runs the pre_render hooks over it
+func (cfg renderConfig) preRender(ctx context.Context, mdPath string) ([]byte, error) {
+	start := time.Now()
+	content, err := os.ReadFile(mdPath)
+	if err != nil {
+		return nil, fmt.Errorf("read markdown: %w", err)
+	}
+	traceStage(cfg.outPath, "read "+mdPath, start)
+
+	if isOrgSource(mdPath) {
+		start = time.Now()
+		content = orgmode.ToMarkdown(content)
+		traceStage(cfg.outPath, "convert org "+mdPath, start)
+	}
+
+	if len(cfg.preHooks) == 0 {
+		return content, nil
+	}
+	start = time.Now()
+	content, err = runHooks(ctx, cfg.preHooks, content, hookContext{source: mdPath, output: cfg.outPath})
+	traceStage(cfg.outPath, "pre_render "+mdPath, start)
+	return content, err
+}
+
+// isOrgSource reports whether path is an Org-mode document, converted to
+// markdown when it is read
+func isOrgSource(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".org")
+}
+
+// postRender returns a transform running the post_render hooks over the final
+// HTML document, or nil when there are none
+func (cfg renderConfig) postRender(ctx context.Context) func(string) (string, error) {
+	if len(cfg.postHooks) == 0 {
+		return nil
+	}
+
+	return func(html string) (string, error) {
+		out, err := runHooks(ctx, cfg.postHooks, []byte(html), hookContext{source: strings.Join(cfg.sources, " "), output: cfg.outPath})
+		return string(out), err
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
+)
+
+// isHTMLSource reports whether path is a pre-generated HTML file, which is
+// printed without markdown conversion
+func isHTMLSource(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return true
+	}
+	return false
+}
+
+// htmlSources reports whether the matched files are HTML, failing when
+// markdown and HTML are mixed
+func htmlSources(files []string) (bool, error) {
+	n := 0
+	for _, f := range files {
+		if isHTMLSource(f) {
+			n++
+		}
+	}
+	if n > 0 && n < len(files) {
+		return false, fmt.Errorf("sources mix markdown and HTML files")
+	}
+	return n > 0, nil
+}
+
+// renderHTMLFiles prints the HTML sources of a single job into one PDF. A
+// single complete document is printed as it is unless wrap_html is set;
+// fragments, and several files, are wrapped in the template, each file
+// starting a new page.
+func renderHTMLFiles(ctx context.Context, files []string, wrap *bool, cfg renderConfig) error {
+	if len(files) > 1 && wrap != nil && !*wrap {
+		return fmt.Errorf("wrap_html: false needs a single HTML source, found %d", len(files))
+	}
+
+	// Title the document after its output
+	title := cfg.title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(cfg.outPath), filepath.Ext(cfg.outPath))
+	}
+
+	var parts []string
+	standalone := false
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", f, err)
+		}
+
+		wrapped := len(files) > 1 || cfg.safe || !render.IsHTMLDocument(src)
+		if wrap != nil && len(files) == 1 {
+			wrapped = *wrap || cfg.safe
+		}
+		standalone = !wrapped
+
+		body := render.PrepareHTML(src, filepath.Dir(f), wrapped, cfg.safe)
+		if len(parts) > 0 {
+			body = `<div style="page-break-before: always"></div>` + "\n" + body
+		}
+		parts = append(parts, body)
+	}
+
+	return renderDocument(ctx, render.RenderRequest{
+		HTML:       strings.Join(parts, "\n"),
+		Standalone: standalone,
+		Title:      title,
+	}, cfg)
+}
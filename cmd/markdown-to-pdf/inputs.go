@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jobFromInputs builds a single job from GitHub Action inputs, which the runner
+// exposes as INPUT_<NAME> environment variables. Every job option can be given,
+// using its YAML key (INPUT_MAX_WAIT) or the hyphenated input form (INPUT_MAX-WAIT).
+// ok is false when no source input is set.
+func jobFromInputs() (j job, ok bool, err error) {
+	if actionInput("source") == "" {
+		return job{}, false, nil
+	}
+
+	v := reflect.ValueOf(&j).Elem()
+	t := v.Type()
+
+	for i := range t.NumField() {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		raw := actionInput(key)
+		if raw == "" {
+			continue
+		}
+
+		if err := setField(v.Field(i), raw); err != nil {
+			return job{}, false, fmt.Errorf("input %s: %w", key, err)
+		}
+	}
+
+	return j, true, nil
+}
+
+// actionInput returns the trimmed value of an action input by its YAML key
+func actionInput(key string) string {
+	name := strings.ToUpper(key)
+	if value, ok := os.LookupEnv("INPUT_" + name); ok {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(os.Getenv("INPUT_" + strings.ReplaceAll(name, "_", "-")))
+}
+
+// setField parses raw into a string, integer, float, boolean, or list struct
+// field, where lists are comma-separated or a YAML list; other fields are parsed as YAML
+func setField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported option type %s", field.Type())
+		}
+		var items []string
+		if strings.HasPrefix(raw, "[") {
+			if err := yaml.Unmarshal([]byte(raw), &items); err != nil {
+				return fmt.Errorf("invalid YAML list: %w", err)
+			}
+			field.Set(reflect.ValueOf(items))
+			return nil
+		}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		field.SetBool(b)
+	default:
+		// Nested options such as archive are given as YAML
+		if err := yaml.Unmarshal([]byte(raw), field.Addr().Interface()); err != nil {
+			return fmt.Errorf("invalid YAML: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetFieldList(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{"comma-separated", "bare-url, trailing-spaces", []string{"bare-url", "trailing-spaces"}},
+		{"YAML list", "[bare-url, trailing-spaces]", []string{"bare-url", "trailing-spaces"}},
+		{"quoted YAML list", `["bare-url"]`, []string{"bare-url"}},
+		{"empty items", "bare-url,,", []string{"bare-url"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var j job
+			if err := setField(reflect.ValueOf(&j).Elem().FieldByName("LintDisable"), tt.raw); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(j.LintDisable, tt.want) {
+				t.Errorf("setField(%q) = %q, want %q", tt.raw, j.LintDisable, tt.want)
+			}
+			if err := validateLintRules(j.LintDisable); err != nil {
+				t.Errorf("setField(%q): %v", tt.raw, err)
+			}
+		})
+	}
+}
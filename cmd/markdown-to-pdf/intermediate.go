@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
+)
+
+// keepIntermediate is set by --keep-intermediate: the directory the markdown
+// and HTML every document is rendered from are kept in
+var keepIntermediate string
+
+// intermediatePath returns where the intermediate file of the document at
+// outPath is kept: its output path below the directory, with ext
+func intermediatePath(outPath, ext string) string {
+	rel := filepath.Base(outPath)
+	if wd, err := os.Getwd(); err == nil {
+		if abs, err := filepath.Abs(outPath); err == nil {
+			if r, err := filepath.Rel(wd, abs); err == nil && filepath.IsLocal(r) {
+				rel = r
+			}
+		}
+	}
+	return filepath.Join(keepIntermediate, strings.TrimSuffix(rel, filepath.Ext(rel))+ext)
+}
+
+// writeIntermediate writes an intermediate file, logging failures since they
+// don't affect the document
+func writeIntermediate(path string, content []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Printf("Warning: keep intermediate %s: %v", path, err)
+		return
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		log.Printf("Warning: keep intermediate %s: %v", path, err)
+	}
+}
+
+// keepIntermediates writes the markdown of a request and sets it up to write
+// the complete HTML, after the post_render hooks and before printing, so both
+// are kept when the render fails
+func keepIntermediates(req *render.RenderRequest, outPath string) {
+	if keepIntermediate == "" {
+		return
+	}
+	if len(req.Markdown) > 0 {
+		writeIntermediate(intermediatePath(outPath, ".md"), req.Markdown)
+	}
+
+	transform := req.TransformHTML
+	req.TransformHTML = func(html string) (string, error) {
+		if transform != nil {
+			var err error
+			if html, err = transform(html); err != nil {
+				return "", err
+			}
+		}
+		writeIntermediate(intermediatePath(outPath, ".html"), []byte(html))
+		return html, nil
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"cmp"
+	"html/template"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/kuzik/markdown-pdf-action/internal/licenses"
+)
+
+// licensesConfig appends a dependency and license report to the job's documents
+type licensesConfig struct {
+	Files   []string `yaml:"files"`   // go.mod, package.json, package-lock.json, or CycloneDX/SPDX JSON SBOMs, resolved like source
+	Dev     bool     `yaml:"dev"`     // also list development dependencies
+	Exclude []string `yaml:"exclude"` // globs of package names to leave out, e.g. our own modules
+	Title   string   `yaml:"title"`   // section heading, "Third-party licenses" by default
+}
+
+// licenseCount is one row of the license summary
+type licenseCount struct {
+	License string
+	Count   int
+}
+
+// licensesTemplate renders the dependency and license report appended to a document
+var licensesTemplate = template.Must(template.New("licenses").Parse(`
+<section class="license-report" style="page-break-before: always">
+<style>
+.license-report .license-unknown { color: #cf222e; font-weight: 600; }
+.license-report .license-scope { color: #57606a; font-size: 0.9em; }
+</style>
+<h2>{{.Title}}</h2>
+<p>{{len .Dependencies}} {{if eq (len .Dependencies) 1}}dependency{{else}}dependencies{{end}} under {{len .Summary}} {{if eq (len .Summary) 1}}license{{else}}licenses{{end}}.</p>
+<table class="license-summary">
+<thead><tr><th>License</th><th>Packages</th></tr></thead>
+<tbody>
+{{range .Summary}}<tr><td>{{if eq .License "Unknown"}}<span class="license-unknown">{{.License}}</span>{{else}}{{.License}}{{end}}</td><td>{{.Count}}</td></tr>
+{{end}}</tbody>
+</table>
+<table class="license-table">
+<thead><tr><th>Package</th><th>Version</th><th>License</th><th>Scope</th>{{if .ShowSource}}<th>Source</th>{{end}}</tr></thead>
+<tbody>
+{{range .Dependencies}}<tr><td><code>{{.Name}}</code></td><td>{{.Version}}</td><td>{{if eq .License "Unknown"}}<span class="license-unknown">{{.License}}</span>{{else}}{{.License}}{{end}}</td><td class="license-scope">{{.Scope}}</td>{{if $.ShowSource}}<td>{{.Source}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+</section>
+`))
+
+// licensesAppendix returns a section listing the dependencies declared in the
+// configured files with their licenses, or "" when no files are set
+func licensesAppendix(c licensesConfig) (string, error) {
+	if len(c.Files) == 0 {
+		return "", nil
+	}
+
+	var lists [][]licenses.Dependency
+	for _, f := range c.Files {
+		deps, err := licenses.Read(f)
+		if err != nil {
+			return "", err
+		}
+		lists = append(lists, deps)
+	}
+
+	deps := slices.DeleteFunc(licenses.Merge(lists...), func(d licenses.Dependency) bool {
+		return (d.Scope == "dev" && !c.Dev) || c.excludes(d.Name)
+	})
+
+	counts := make(map[string]int)
+	for _, d := range deps {
+		counts[d.License]++
+	}
+	var summary []licenseCount
+	for license, n := range counts {
+		summary = append(summary, licenseCount{License: license, Count: n})
+	}
+	slices.SortFunc(summary, func(a, b licenseCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.License, b.License))
+	})
+
+	data := struct {
+		Title        string
+		Dependencies []licenses.Dependency
+		Summary      []licenseCount
+		ShowSource   bool
+	}{Title: cmp.Or(c.Title, "Third-party licenses"), Dependencies: deps, Summary: summary, ShowSource: len(c.Files) > 1}
+
+	var buf strings.Builder
+	if err := licensesTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// excludes reports whether a package name matches one of the exclude globs
+func (c licensesConfig) excludes(name string) bool {
+	for _, pattern := range c.Exclude {
+		if ok, _ := doublestar.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
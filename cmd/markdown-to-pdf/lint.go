@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/mdlint"
+)
+
+// validateLintRules checks the rules a job disables
+func validateLintRules(rules []string) error {
+	for _, rule := range rules {
+		if !slices.Contains(mdlint.Rules, rule) {
+			return fmt.Errorf("unknown rule %q (use %s)", rule, strings.Join(mdlint.Rules, ", "))
+		}
+	}
+	return nil
+}
+
+// lintSources lints the markdown sources of a document before it renders
+func lintSources(sources []string, mode string, disabled []string) ([]string, error) {
+	return checkSources("lint", sources, mode, func(content []byte, _ string) []mdlint.Issue {
+		return mdlint.Lint(content, disabled)
+	})
+}
+
+// checkImages checks that the images of the markdown sources have alt text
+// and exist, resolved against baseDir or else the folder of each source
+func checkImages(sources []string, mode, baseDir string) ([]string, error) {
+	return checkSources("images", sources, mode, func(content []byte, src string) []mdlint.Issue {
+		dir := baseDir
+		if dir == "" {
+			dir = filepath.Dir(src)
+		}
+		return mdlint.Images(content, dir)
+	})
+}
+
+// checkSources runs a check over the markdown sources of a document. With
+// error any issue fails the document; with warn the issues are returned to be
+// recorded as document warnings.
+func checkSources(name string, sources []string, mode string, check func(content []byte, src string) []mdlint.Issue) ([]string, error) {
+	if mode == "" {
+		return nil, nil
+	}
+
+	var issues []string
+	for _, src := range sources {
+		switch strings.ToLower(filepath.Ext(src)) {
+		case ".md", ".markdown":
+		default:
+			continue
+		}
+		content, err := os.ReadFile(src)
+		if err != nil {
+			log.Printf("Warning: %s check of %s: %v", name, src, err)
+			continue
+		}
+		for _, issue := range check(content, src) {
+			issues = append(issues, src+":"+issue.String())
+		}
+	}
+
+	if len(issues) > 0 && mode == checkError {
+		return nil, fmt.Errorf("%s check found %d issues:\n  %s", name, len(issues), strings.Join(issues, "\n  "))
+	}
+	for i, issue := range issues {
+		issues[i] = name + ": " + issue
+	}
+	return issues, nil
+}
@@ -1,69 +1,741 @@
 package main
 
 import (
-	"embed"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"html/template"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
-	"github.com/kuzik/pandoc-latex-docker/internal/images"
-	"github.com/kuzik/pandoc-latex-docker/internal/markdown"
-	"github.com/kuzik/pandoc-latex-docker/internal/pdf"
-	"github.com/kuzik/pandoc-latex-docker/internal/templates"
-	"github.com/kuzik/pandoc-latex-docker/internal/ziputil"
+	"github.com/kuzik/markdown-pdf-action/internal/gitinfo"
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+	"github.com/kuzik/markdown-pdf-action/internal/natsort"
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+	"github.com/kuzik/markdown-pdf-action/internal/templates"
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
 	"gopkg.in/yaml.v3"
 )
 
-//go:embed template.html
-var templateFS embed.FS
-
 type job struct {
-	Source string `yaml:"source"`
-	Output string `yaml:"output"`
-	Type   string `yaml:"type"` // single | subfolders | combine
+	Name       string `yaml:"name"`
+	Source     string `yaml:"source"`
+	Output     string `yaml:"output"`
+	Title      string `yaml:"title"`       // document title; defaults to the first h1, the front matter title, or the file name
+	Type       string `yaml:"type"`        // single | subfolders | combine | changelog | openapi | zip | dashboard
+	Sort       string `yaml:"sort"`        // natural (default) | lexical; order of matched files
+	SourceRoot string `yaml:"source_root"` // directory a relative source is resolved against
+	Repo       string `yaml:"repo"`        // owner/name or clone URL of another repository holding the sources
+	Ref        string `yaml:"ref"`         // branch, tag, or commit of repo; default branch when empty
+	MaxWait    string `yaml:"max_wait"`    // e.g. "15s"; max time to wait for images/fonts/scripts
+	Timeout    string `yaml:"timeout"`     // e.g. "2m"; per-document render timeout
+
+	MaxPages    int     `yaml:"max_pages"`
+	MaxSizeMB   float64 `yaml:"max_size_mb"`
+	LimitAction string  `yaml:"limit_action"` // warn (default) | fail
+
+	Safe bool `yaml:"safe"` // treat sources as untrusted: no raw HTML, sanitized output, sandboxed Chrome
+
+	Accessibility string           `yaml:"accessibility"` // warn | error: check the generated HTML for accessibility issues
+	Spellcheck    spellcheckConfig `yaml:"spellcheck"`    // check the prose of markdown sources with hunspell or Vale
+	Lint          string           `yaml:"lint"`          // warn | error: lint markdown sources before rendering
+	LintDisable   []string         `yaml:"lint_disable"`  // lint rules to skip, e.g. ["trailing-spaces"]
+	ImageCheck    string           `yaml:"image_check"`   // warn | error: images without alt text or a file
+
+	ImagePlaceholders bool `yaml:"image_placeholders"` // print a "Missing image" box for images that can't be embedded
+
+	WrapHTML *bool `yaml:"wrap_html"` // .html sources: wrap in the template (default: fragments and multiple files only)
+
+	Lang string `yaml:"lang"` // document language, e.g. "ja" or "ar"; sets fonts and hyphenation
+	Dir  string `yaml:"dir"`  // text direction: ltr | rtl | auto (default from lang)
+
+	Theme       string `yaml:"theme"`        // built-in theme: github (default) | latex | minimal | letterhead
+	TemplateDir string `yaml:"template_dir"` // folder with a template.html replacing the built-in one; its assets are inlined
+	Layout      string `yaml:"layout"`       // single-column (default) | two-column | three-column
+	WideTables  string `yaml:"wide_tables"`  // shrink | landscape | slice; default leaves wide tables overflowing
+	CodeWrap    string `yaml:"code_wrap"`    // wrap | shrink; default cuts long code lines off
+	Footnotes   string `yaml:"footnotes"`    // end | page; enables the footnote extension
+	MarginInner string `yaml:"margin_inner"` // binding-side margin for duplex printing, e.g. 25mm; mirrored on left-hand pages
+	MarginOuter string `yaml:"margin_outer"` // margin opposite the binding, e.g. 15mm
+
+	Pagination paginationConfig `yaml:"pagination"` // page break rules
+
+	Markdown markdownConfig `yaml:"markdown"` // goldmark extensions to enable or disable
+
+	ListOfFigures bool `yaml:"list_of_figures"` // insert a List of Figures; enables the figures extension
+	ListOfTables  bool `yaml:"list_of_tables"`  // insert a List of Tables; enables the table_captions extension
+
+	LinkURLs string `yaml:"link_urls"` // inline | endnotes; print external link targets
+	QRCode   string `yaml:"qr_code"`   // URL for a QR code on the first page; {output} and {name} name the PDF
+
+	Chapters string `yaml:"chapters"` // single and combine jobs: directory for one PDF per chapter alongside the combined output
+
+	Drafts []string `yaml:"drafts"` // globs of draft files or folders, e.g. "**/drafts/**"
+
+	PreRender  []string `yaml:"pre_render"`  // commands or builtin: transforms run over each markdown source
+	PostRender []string `yaml:"post_render"` // commands or builtin: transforms run over the final HTML
+
+	Attribution bool `yaml:"attribution"` // print the authors and last change of each source under its first heading
+
+	History  int            `yaml:"history"`  // append a page listing the last N commits touching the sources
+	Tree     treeConfig     `yaml:"tree"`     // append a listing of a folder, like tree(1)
+	Licenses licensesConfig `yaml:"licenses"` // append a table of third-party dependencies and their licenses
+
+	Header string `yaml:"header"` // HTML printed at the top of every page (chrome backend)
+	Footer string `yaml:"footer"` // HTML printed at the bottom of every page (chrome backend)
+
+	InjectHead      string `yaml:"inject_head"`       // raw HTML/CSS/JS added at the end of <head>
+	InjectBodyStart string `yaml:"inject_body_start"` // raw HTML added before the content
+	InjectBodyEnd   string `yaml:"inject_body_end"`   // raw HTML/JS added after the content
+
+	PDFBackend    string `yaml:"pdf_backend"`     // chrome (default) | wkhtmltopdf | gotenberg
+	PDFBackendURL string `yaml:"pdf_backend_url"` // wkhtmltopdf binary path or Gotenberg service URL
+	ChromeURL     string `yaml:"chrome_url"`      // DevTools endpoint of a running Chrome (ws:// or http://)
+
+	Chrome        chromeConfig `yaml:"chrome"`         // extra flags, proxy, host resolver rules, and language of a local Chrome
+	StrictOffline bool         `yaml:"strict_offline"` // fail when the page requests anything over the network (chrome backend)
+	LogResources  bool         `yaml:"log_resources"`  // record the requests each document makes in the manifest (chrome backend)
+	SourceMap     bool         `yaml:"source_map"`     // write <output>.sourcemap.json with the pages of each source (chrome backend)
+
+	DependsOn []string `yaml:"depends_on"` // names of jobs that must succeed first
+	Format    string   `yaml:"format"`     // dashboard jobs: html, markdown, both, pdf
+	Since     string   `yaml:"since"`      // changelog jobs: first release to include, e.g. v1.2.0
+	Until     string   `yaml:"until"`      // changelog jobs: last release to include; Unreleased adds upcoming changes
+	Webhook   string   `yaml:"webhook"`    // URL receiving a JSON completion payload when the job finishes
+	Clean     string   `yaml:"clean"`      // true | false | dry-run: remove PDFs and zips no source produced anymore
+
+	Permissions permissionsConfig `yaml:"permissions"` // mode and owner of the generated files and their folders
+
+	Archive    archiveConfig    `yaml:"archive"`    // source zips, zip jobs, and zipping generated PDFs
+	Publish    publishConfig    `yaml:"publish"`    // upload generated files to S3, GCS, or Azure
+	Confluence confluenceConfig `yaml:"confluence"` // push rendered documents to Confluence pages
+	Email      emailConfig      `yaml:"email"`      // mail the generated PDFs over SMTP
+
+	fetched []string // local copies of URL sources, in the order listed
+}
+
+// layoutColumns maps layout names to column counts
+var layoutColumns = map[string]int{
+	"":              1,
+	"single-column": 1,
+	"two-column":    2,
+	"three-column":  3,
+}
+
+// paginationConfig relaxes the default page break rules
+type paginationConfig struct {
+	KeepHeadings *bool `yaml:"keep_headings"` // keep headings with the following content (default true)
+	KeepItems    *bool `yaml:"keep_items"`    // keep list items and table rows on one page (default true)
+	Orphans      int   `yaml:"orphans"`       // minimum paragraph lines at the bottom of a page (default 3)
+	Widows       int   `yaml:"widows"`        // minimum paragraph lines at the top of a page (default 3)
+}
+
+// pagination converts the config to render options
+func (p paginationConfig) pagination() render.Pagination {
+	return render.Pagination{
+		SplitHeadings: p.KeepHeadings != nil && !*p.KeepHeadings,
+		SplitItems:    p.KeepItems != nil && !*p.KeepItems,
+		Orphans:       p.Orphans,
+		Widows:        p.Widows,
+	}
+}
+
+// markdownConfig selects markdown extensions on top of the defaults
+type markdownConfig struct {
+	Enable  []string `yaml:"enable"`  // e.g. footnote, typographer, cjk, wikilinks
+	Disable []string `yaml:"disable"` // e.g. linkify, math
+
+	Wikilinks wikilinkConfig `yaml:"wikilinks"`
+}
+
+// wikilinkConfig configures how [[Page]] links resolve when wikilinks are enabled
+type wikilinkConfig struct {
+	Root      string `yaml:"root"`      // vault root; defaults to the directory part of the job source
+	Resolve   string `yaml:"resolve"`   // shortest (default) | relative | absolute
+	Extension string `yaml:"extension"` // extension note links point to; defaults to .pdf
+}
+
+// markdownOptions converts the job's markdown config to render options
+func (j job) markdownOptions() render.MarkdownOptions {
+	root := j.Markdown.Wikilinks.Root
+	if root == "" {
+		root = j.Source
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			root, _ = doublestar.SplitPattern(filepath.ToSlash(j.Source))
+			root = filepath.FromSlash(root)
+		}
+	}
+
+	enable := slices.Clone(j.Markdown.Enable)
+	if j.ListOfFigures && !slices.Contains(enable, "figures") {
+		enable = append(enable, "figures")
+	}
+	if j.ListOfTables && !slices.Contains(enable, "table_captions") {
+		enable = append(enable, "table_captions")
+	}
+	if j.Footnotes != "" && !slices.Contains(enable, "footnote") {
+		enable = append(enable, "footnote")
+	}
+
+	return render.MarkdownOptions{
+		Enable:  enable,
+		Disable: j.Markdown.Disable,
+		Wikilinks: render.WikilinkOptions{
+			Root:      filepath.FromSlash(root),
+			Resolve:   j.Markdown.Wikilinks.Resolve,
+			Extension: j.Markdown.Wikilinks.Extension,
+		},
+	}
 }
 
 type renderConfig struct {
-	mdPath  string
-	outPath string
-	baseDir string
+	mdPath   string
+	outPath  string
+	baseDir  string
+	pdfOpts  render.PDFOptions
+	limits   outputLimits
+	safe     bool
+	requests bool // record the requests the page makes
+	a11y     string
+	spelling spellcheckConfig
+	lint     string
+	lintOff  []string // lint rules to skip
+	images   string   // image check mode
+	missing  bool     // print placeholders for images that can't be embedded
+	srcMap   bool     // write a source map next to the PDF
+	breaks   bool     // each source starts a new page
+	sources  []string
+	jobName  string
+	title    string // document title; defaults to the first h1, the front matter title, or the file name
+	locale   render.Locale
+	layout   render.Layout
+	history  int
+	authors  bool // attribute each source under its first heading
+	tree     treeConfig
+	licenses licensesConfig
+	sort     string
+
+	preHooks  []string
+	postHooks []string
+
+	markdown      render.MarkdownOptions
+	listOfFigures bool
+	listOfTables  bool
+	linkURLs      string
+	qrCode        string
+	theme         string
+	templateDir   string
+	inject        render.Injection
+
+	confluence *confluenceConfig // set when documents are exported to Confluence
 }
 
-type pageData struct {
-	Title   string
-	Content template.HTML
+// renderConfig returns the job-wide render settings shared by every document in the job
+func (j job) renderConfig(pdfOpts render.PDFOptions) renderConfig {
+	cfg := renderConfig{
+		pdfOpts:  pdfOpts,
+		limits:   j.outputLimits(),
+		safe:     j.Safe,
+		requests: j.LogResources,
+		a11y:     j.Accessibility,
+		spelling: j.Spellcheck,
+		lint:     j.Lint,
+		lintOff:  j.LintDisable,
+		images:   j.ImageCheck,
+		missing:  j.ImagePlaceholders,
+		srcMap:   j.SourceMap,
+		jobName:  j.jobName(),
+		title:    j.Title,
+		locale:   render.Locale{Lang: j.Lang, Dir: j.Dir},
+		layout: render.Layout{
+			Columns:     layoutColumns[j.Layout],
+			WideTables:  j.WideTables,
+			CodeWrap:    j.CodeWrap,
+			Pagination:  j.Pagination.pagination(),
+			Footnotes:   j.Footnotes,
+			MarginInner: j.MarginInner,
+			MarginOuter: j.MarginOuter,
+		},
+		history:  j.History,
+		authors:  j.Attribution,
+		tree:     j.Tree,
+		licenses: j.Licenses,
+		sort:     j.Sort,
+
+		preHooks:  j.PreRender,
+		postHooks: j.PostRender,
+
+		markdown:      j.markdownOptions(),
+		listOfFigures: j.ListOfFigures,
+		listOfTables:  j.ListOfTables,
+		linkURLs:      j.LinkURLs,
+		qrCode:        j.QRCode,
+		theme:         j.Theme,
+		templateDir:   j.TemplateDir,
+		inject: render.Injection{
+			Head:      j.InjectHead,
+			BodyStart: j.InjectBodyStart,
+			BodyEnd:   j.InjectBodyEnd,
+		},
+	}
+	if j.Confluence.enabled() {
+		cfg.confluence = &j.Confluence
+	}
+	return cfg
 }
 
-var (
-	tmplLoader  *templates.EmbeddedLoader
-	mdConverter *markdown.Converter
-)
+// withGitInfo expands git placeholders such as {short_sha} and {tag} in the
+// job's output paths, header, and footer
+func (j job) withGitInfo(git gitinfo.Info) job {
+	j.Output = git.Expand(j.Output)
+	j.Header = git.Expand(j.Header)
+	j.Footer = git.Expand(j.Footer)
+	j.QRCode = git.Expand(j.QRCode)
+	j.Chapters = git.Expand(j.Chapters)
+	j.Archive.PDFs = git.Expand(j.Archive.PDFs)
+	j.Publish.Prefix = git.Expand(j.Publish.Prefix)
+	j.Email.Subject = git.Expand(j.Email.Subject)
+	return j
+}
+
+// jobName returns the job's name, falling back to its type and source
+func (j job) jobName() string {
+	if j.Name != "" {
+		return j.Name
+	}
+	return j.Type + " " + j.Source
+}
 
-func init() {
-	tmplLoader = templates.NewEmbeddedLoader(templateFS)
-	mdConverter = markdown.DefaultConverter()
+// outputLimits bounds the size of generated PDFs
+type outputLimits struct {
+	maxPages  int
+	maxSizeMB float64
+	fail      bool
+}
+
+// outputLimits returns the page and size limits configured for the job
+func (j job) outputLimits() outputLimits {
+	return outputLimits{
+		maxPages:  j.MaxPages,
+		maxSizeMB: j.MaxSizeMB,
+		fail:      j.LimitAction == "fail",
+	}
+}
+
+// validateLimitAction checks a limit_action option
+func validateLimitAction(value string) error {
+	switch value {
+	case "", "warn", "fail":
+		return nil
+	}
+	return fmt.Errorf("unknown limit_action %q (use warn or fail)", value)
+}
+
+// check compares a generated PDF against the limits. Violations are logged as
+// warnings, or returned as an error when the job is configured to fail.
+func (l outputLimits) check(res render.Result) error {
+	var violations []string
+
+	if l.maxPages > 0 && res.Pages > l.maxPages {
+		violations = append(violations, fmt.Sprintf("%d pages exceeds max_pages %d", res.Pages, l.maxPages))
+	}
+
+	sizeMB := float64(len(res.PDF)) / (1024 * 1024)
+	if l.maxSizeMB > 0 && sizeMB > l.maxSizeMB {
+		violations = append(violations, fmt.Sprintf("%.2f MB exceeds max_size_mb %.2f", sizeMB, l.maxSizeMB))
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	msg := strings.Join(violations, "; ")
+	if l.fail {
+		return fmt.Errorf("output limits exceeded for %s: %s", res.OutputPath, msg)
+	}
+
+	log.Printf("Warning: %s: %s", res.OutputPath, msg)
+	return nil
+}
+
+// pdfOptions builds PDF generation settings from job options
+func (j job) pdfOptions() (render.PDFOptions, error) {
+	opts := render.DefaultPDFOptions()
+
+	if j.MaxWait != "" {
+		d, err := time.ParseDuration(j.MaxWait)
+		if err != nil {
+			return opts, fmt.Errorf("parse max_wait: %w", err)
+		}
+		opts.MaxWait = d
+	}
+
+	if j.Timeout != "" {
+		d, err := time.ParseDuration(j.Timeout)
+		if err != nil {
+			return opts, fmt.Errorf("parse timeout: %w", err)
+		}
+		opts.Timeout = d
+	}
+
+	if j.ChromeURL != "" {
+		opts.RemoteDebuggingURL = j.ChromeURL
+	}
+	j.Chrome.apply(&opts)
+	opts.StrictOffline = j.StrictOffline
+	if j.ChromeURL != "" && (len(j.Chrome.Flags) > 0 || j.Chrome.HostResolverRules != "" || j.Chrome.Lang != "" || j.Chrome.Proxy != "") {
+		log.Printf("Warning: %s: chrome options only apply to a local Chrome, not chrome_url", j.jobName())
+	}
+
+	opts.HeaderTemplate = j.Header
+	opts.FooterTemplate = j.Footer
+	if (j.Header != "" || j.Footer != "") && j.PDFBackend != "" && j.PDFBackend != "chrome" {
+		log.Printf("Warning: %s: header and footer are only printed by the chrome backend", j.jobName())
+	}
+
+	if reproducible {
+		t, err := fixedTimestamp()
+		if err != nil {
+			return opts, err
+		}
+		opts.FixedDate = t
+	}
+
+	backend, err := render.NewPDFBackend(j.PDFBackend, j.PDFBackendURL)
+	if err != nil {
+		return opts, err
+	}
+	opts.Backend = backend
+
+	return opts, nil
+}
+
+// artifacts collects every file generated during the run for the manifest
+var artifacts = manifest.NewRecorder()
+
+// reproducible makes PDFs, zips, and the manifest byte-identical across runs
+// with the same inputs; enabled by --reproducible or SOURCE_DATE_EPOCH
+var reproducible bool
+
+// recordPDF adds a rendered PDF to the manifest
+func recordPDF(res render.Result, cfg renderConfig) error {
+	var resources []manifest.Resource
+	var external []string
+	for _, r := range res.Resources {
+		resources = append(resources, manifest.Resource{URL: r.URL, Type: r.Type, Status: r.Status, Bytes: r.Bytes, External: r.External, Error: r.Error})
+		if r.External {
+			external = append(external, r.URL)
+		}
+	}
+	if len(external) > 0 {
+		log.Printf("Warning: %s loaded %d external resources: %s", res.OutputPath, len(external), strings.Join(external, ", "))
+	}
+	for _, w := range res.Warnings {
+		log.Printf("Warning: %s: %s", res.OutputPath, w)
+	}
+
+	return recordArtifact(manifest.Artifact{
+		Output:    res.OutputPath,
+		Kind:      "pdf",
+		Sources:   cfg.sources,
+		Title:     res.Title,
+		Pages:     res.Pages,
+		Job:       cfg.jobName,
+		Resources: resources,
+		Warnings:  res.Warnings,
+	})
+}
+
+// recordArtifact adds a generated file to the manifest, computing its checksum and size
+func recordArtifact(a manifest.Artifact) error {
+	if err := artifacts.AddFile(a); err != nil {
+		return fmt.Errorf("record artifact: %w", err)
+	}
+	return nil
 }
 
 func main() {
-	var configYAML string
+	// "markdown-to-pdf validate-templates --config ..." only checks the jobs' templates
+	validateOnly := len(os.Args) > 1 && os.Args[1] == "validate-templates"
+	if validateOnly {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
+	var (
+		configYAML   string
+		deadline     time.Duration
+		manifestPath string
+		parallel     int
+		listThemes   bool
+		diff         diffConfig
+		summary      summaryConfig
+	)
 	flag.StringVar(&configYAML, "config", "", "YAML config string describing render jobs")
+	flag.StringVar(&manifestPath, "manifest", "", "Write a JSON manifest of generated artifacts to this path")
+	flag.StringVar(&checksums.path, "checksums", "", "Write a SHA256SUMS file covering every file in its directory to this path")
+	flag.StringVar(&checksums.sign, "sign", "", "Sign the checksums file: gpg or cosign")
+	flag.StringVar(&checksums.key, "sign-key", "", "GPG key ID, or cosign key reference (cosign signs keyless when empty)")
+	flag.DurationVar(&deadline, "deadline", 0, "Overall time budget for all jobs, e.g. 20m (0 means no limit)")
+	flag.IntVar(&parallel, "parallel", 1, "Maximum number of jobs run at once; jobs still wait for their depends_on")
+	flag.StringVar(&sourceCache, "source-cache", "", "Directory keeping downloaded URL sources between runs (default: a temporary directory)")
+	flag.StringVar(&cleanDefault, "clean", "", "Remove stale PDFs and zips from job output directories: true, false, or dry-run (jobs can override)")
+	flag.StringVar(&umask, "umask", "", "Octal umask for every file the run creates, e.g. 002")
+	flag.StringVar(&permissionDefaults.FileMode, "file-mode", "", "Octal mode of generated files, e.g. 0664 (jobs can override)")
+	flag.StringVar(&permissionDefaults.DirMode, "dir-mode", "", "Octal mode of the folders holding generated files, e.g. 2775 (jobs can override)")
+	flag.StringVar(&permissionDefaults.Owner, "owner", "", "Owner of generated files: user, user:group, or :group (jobs can override)")
+	flag.BoolVar(&browserDownload.enabled, "browser-download", false, "Download a pinned headless shell when no Chrome is installed and CHROME_BIN is unset")
+	flag.StringVar(&browserDownload.Version, "browser-version", "", "Chrome for Testing version to download (default "+pdf.HeadlessShellVersion+")")
+	flag.StringVar(&browserDownload.SHA256, "browser-sha256", "", "SHA-256 of the headless shell zip, or platform=digest pairs, e.g. linux64=...,mac-arm64=...")
+	flag.StringVar(&browserDownload.CacheDir, "browser-cache", "", "Directory keeping downloaded browsers (default: the user cache directory)")
+	flag.BoolVar(&includeDrafts, "include-drafts", false, "Render documents marked as drafts too")
+	flag.BoolVar(&reproducible, "reproducible", false, "Produce byte-identical outputs across runs (implied by SOURCE_DATE_EPOCH)")
+	flag.StringVar(&diff.against, "diff-against", "", "Directory of previous PDFs to compare the generated PDFs with visually")
+	flag.StringVar(&diff.root, "diff-root", "", "Directory the generated PDFs are matched from (default: their common directory)")
+	flag.StringVar(&diff.report, "diff-report", "diff-report", "Directory to write the visual diff report to")
+	flag.Float64Var(&diff.threshold, "diff-threshold", 0.1, "Pages differing by at most this percentage of pixels count as unchanged")
+	flag.BoolVar(&listThemes, "list-themes", false, "List the built-in themes and exit")
+	flag.StringVar(&summary.url, "notify", "", "Slack or Teams incoming webhook that receives a summary of the run")
+	flag.StringVar(&summary.format, "notify-format", "auto", "Summary format: auto (from the webhook host), slack, or teams")
+	flag.StringVar(&summary.on, "notify-on", "always", "When to send the summary: always or failure")
+	flag.StringVar(&summary.dashboard, "dashboard-url", "", "Dashboard or docs portal linked from the summary")
+	flag.BoolVar(&trace, "trace", false, "Log how long each stage of every document takes")
+	flag.StringVar(&keepIntermediate, "keep-intermediate", "", "Directory to keep the markdown and HTML every document is rendered from, for debugging")
+	flag.BoolVar(&trace, "v", false, "Shorthand for --trace")
 	flag.Parse()
 
-	if configYAML == "" {
-		log.Fatal("--config must be provided")
+	if listThemes {
+		for _, theme := range templates.Themes() {
+			fmt.Printf("%-12s %s\n", theme.Name, theme.Description)
+		}
+		return
 	}
 
-	jobs, err := parseConfig([]byte(configYAML))
+	if umask != "" {
+		if err := setUmask(umask); err != nil {
+			log.Fatalf("Invalid --umask: %v", err)
+		}
+	}
+
+	if os.Getenv("SOURCE_DATE_EPOCH") != "" {
+		reproducible = true
+	}
+	if reproducible {
+		t, err := fixedTimestamp()
+		if err != nil {
+			log.Fatalf("Failed to enable reproducible output: %v", err)
+		}
+		artifacts.SetTime(t)
+	}
+
+	jobs, err := loadJobs(configYAML)
 	if err != nil {
-		log.Fatalf("Failed to parse config: %v", err)
+		log.Fatalf("Failed to load jobs: %v", err)
+	}
+
+	// Stamp the revision being built into output paths, headers, and footers
+	git := gitinfo.Load()
+	for i := range jobs {
+		jobs[i] = jobs[i].withGitInfo(git)
+
+		// Relative sources are matched from source_root
+		if root := jobs[i].SourceRoot; root != "" && !filepath.IsAbs(jobs[i].Source) {
+			jobs[i].Source = filepath.Join(root, jobs[i].Source)
+		}
+		if root := jobs[i].SourceRoot; root != "" {
+			jobs[i].resolvePaths(root)
+		}
+
+		if err := jobs[i].markdownOptions().Validate(); err != nil {
+			log.Fatalf("Invalid job %s: %v", jobs[i].jobName(), err)
+		}
+		if _, err := templates.LookupTheme(jobs[i].Theme); err != nil {
+			log.Fatalf("Invalid job %s: %v", jobs[i].jobName(), err)
+		}
+		if dir := jobs[i].TemplateDir; dir != "" {
+			if _, err := os.Stat(filepath.Join(dir, "template.html")); err != nil {
+				log.Fatalf("Invalid job %s: template_dir must contain template.html: %v", jobs[i].jobName(), err)
+			}
+		}
+		if hook := jobs[i].Webhook; hook != "" && !strings.HasPrefix(hook, "http://") && !strings.HasPrefix(hook, "https://") {
+			log.Fatalf("Invalid job %s: webhook must be an http:// or https:// URL", jobs[i].jobName())
+		}
+		if err := jobs[i].Email.validate(); err != nil {
+			log.Fatalf("Invalid job %s: email: %v", jobs[i].jobName(), err)
+		}
+		if err := jobs[i].Confluence.validate(); err != nil {
+			log.Fatalf("Invalid job %s: confluence: %v", jobs[i].jobName(), err)
+		}
+		if _, err := jobs[i].Publish.publisher(); err != nil {
+			log.Fatalf("Invalid job %s: publish: %v", jobs[i].jobName(), err)
+		}
+		if err := jobs[i].validateURLSources(); err != nil {
+			log.Fatalf("Invalid job %s: %v", jobs[i].jobName(), err)
+		}
+		if err := jobs[i].validateRepo(); err != nil {
+			log.Fatalf("Invalid job %s: repo: %v", jobs[i].jobName(), err)
+		}
+		if _, err := natsort.Func(jobs[i].Sort); err != nil {
+			log.Fatalf("Invalid job %s: %v", jobs[i].jobName(), err)
+		}
+		if err := validateLimitAction(jobs[i].LimitAction); err != nil {
+			log.Fatalf("Invalid job %s: %v", jobs[i].jobName(), err)
+		}
+		if err := validateClean(jobs[i].Clean); err != nil {
+			log.Fatalf("Invalid job %s: %v", jobs[i].jobName(), err)
+		}
+		if jobs[i].StrictOffline && jobs[i].PDFBackend != "" && jobs[i].PDFBackend != "chrome" {
+			log.Fatalf("Invalid job %s: strict_offline needs the chrome backend", jobs[i].jobName())
+		}
+		if jobs[i].LogResources && jobs[i].PDFBackend != "" && jobs[i].PDFBackend != "chrome" {
+			log.Fatalf("Invalid job %s: log_resources needs the chrome backend", jobs[i].jobName())
+		}
+		if jobs[i].SourceMap && jobs[i].PDFBackend != "" && jobs[i].PDFBackend != "chrome" {
+			log.Fatalf("Invalid job %s: source_map needs the chrome backend", jobs[i].jobName())
+		}
+		if err := validateCheckMode(jobs[i].Accessibility); err != nil {
+			log.Fatalf("Invalid job %s: accessibility: %v", jobs[i].jobName(), err)
+		}
+		if err := validateCheckMode(jobs[i].Lint); err != nil {
+			log.Fatalf("Invalid job %s: lint: %v", jobs[i].jobName(), err)
+		}
+		if err := validateCheckMode(jobs[i].ImageCheck); err != nil {
+			log.Fatalf("Invalid job %s: image_check: %v", jobs[i].jobName(), err)
+		}
+		if err := validateLintRules(jobs[i].LintDisable); err != nil {
+			log.Fatalf("Invalid job %s: lint_disable: %v", jobs[i].jobName(), err)
+		}
+		if err := jobs[i].Chrome.validate(); err != nil {
+			log.Fatalf("Invalid job %s: chrome: %v", jobs[i].jobName(), err)
+		}
+		if err := jobs[i].Permissions.validate(); err != nil {
+			log.Fatalf("Invalid job %s: permissions: %v", jobs[i].jobName(), err)
+		}
+		if _, ok := layoutColumns[jobs[i].Layout]; !ok {
+			log.Fatalf("Invalid job %s: unknown layout %q (use single-column, two-column, or three-column)", jobs[i].jobName(), jobs[i].Layout)
+		}
+	}
+
+	if validateOnly {
+		if validateTemplates(jobs) > 0 {
+			os.Exit(1)
+		}
+		return
 	}
 
-	executeJobs(jobs)
+	deps, err := resolveDependencies(jobs)
+	if err != nil {
+		log.Fatalf("Invalid job dependencies: %v", err)
+	}
+	if err := summary.validate(); err != nil {
+		log.Fatalf("Invalid run summary: %v", err)
+	}
+	if err := checksums.validate(); err != nil {
+		log.Fatalf("Invalid checksums: %v", err)
+	}
+	if err := validateClean(cleanDefault); err != nil {
+		log.Fatalf("Invalid --clean: %v", err)
+	}
+	if err := permissionDefaults.validate(); err != nil {
+		log.Fatalf("Invalid permissions: %v", err)
+	}
+
+	// Cancel in-flight renders on SIGINT/SIGTERM so Chrome and temp files are cleaned up
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+		defer cancel()
+	}
+
+	if err := installBrowser(ctx, jobs); err != nil {
+		log.Fatalf("Failed to download a browser: %v", err)
+	}
+
+	loadCleanState(jobs)
+	start := time.Now()
+	outcomes := runPipeline(ctx, jobs, deps, parallel)
+	removeCheckouts()
+	removeFetched()
+
+	if manifestPath != "" {
+		if err := artifacts.Write(manifestPath); err != nil {
+			log.Printf("Failed to write manifest: %v", err)
+		} else {
+			log.Printf("Manifest written: %s", manifestPath)
+		}
+	}
+	saveCleanState(jobs)
+
+	if diff.against != "" && ctx.Err() == nil {
+		if err := runDiff(ctx, diff); err != nil {
+			log.Printf("Failed to write visual diff: %v", err)
+		}
+	}
+
+	if checksums.path != "" && ctx.Err() == nil {
+		if err := writeChecksums(ctx, checksums); err != nil {
+			log.Printf("Failed to write checksums: %v", err)
+		}
+	}
+
+	// The manifest and checksums belong to the run rather than a job
+	var runFiles []string
+	if manifestPath != "" {
+		runFiles = append(runFiles, manifestPath)
+	}
+	if checksums.path != "" {
+		for _, f := range append([]string{checksums.path}, checksums.signatures()...) {
+			if _, err := os.Stat(f); err == nil {
+				runFiles = append(runFiles, f)
+			}
+		}
+	}
+	if err := permissionDefaults.set(runFiles, nil); err != nil {
+		log.Printf("Failed to set permissions: %v", err)
+	}
+
+	postSummary(summary, jobs, outcomes, time.Since(start))
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		stop()
+		log.Printf("Run deadline of %s exceeded after %s, remaining jobs skipped", deadline, time.Since(start).Round(time.Second))
+		os.Exit(1)
+	}
+	if ctx.Err() != nil {
+		stop()
+		log.Printf("Interrupted, remaining jobs skipped")
+		os.Exit(130)
+	}
+}
+
+// loadJobs parses the YAML config, or synthesizes a single job from action inputs
+// when no config is given
+func loadJobs(configYAML string) ([]job, error) {
+	if strings.TrimSpace(configYAML) != "" {
+		jobs, err := parseConfig([]byte(configYAML))
+		if err != nil {
+			return nil, fmt.Errorf("parse config: %w", err)
+		}
+		return jobs, nil
+	}
+
+	j, ok, err := jobFromInputs()
+	if err != nil {
+		return nil, fmt.Errorf("read action inputs: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("--config or a source input must be provided")
+	}
+	return []job{j}, nil
 }
 
 // parseConfig parses YAML config bytes into jobs
@@ -76,32 +748,56 @@ func parseConfig(cfgBytes []byte) ([]job, error) {
 	return jobs, nil
 }
 
-// executeJobs processes all jobs from the configuration
-func executeJobs(jobs []job) {
-	for _, j := range jobs {
-		if err := executeJob(j); err != nil {
-			log.Printf("Job failed (%s %s): %v", j.Type, j.Source, err)
-		}
+// executeJob routes a job to the appropriate handler based on its type
+func executeJob(ctx context.Context, j job) error {
+	j, err := j.withCheckout(ctx)
+	if err != nil {
+		return err
+	}
+	if j, err = j.withURLSources(ctx); err != nil {
+		return err
 	}
-}
 
-// executeJob routes a job to the appropriate handler based on its type
-func executeJob(j job) error {
 	switch j.Type {
 	case "subfolders":
-		return renderSubfolders(j)
+		err = renderSubfolders(ctx, j)
 	case "single":
-		return renderSingle(j)
+		err = renderSingle(ctx, j)
 	case "combine":
-		return renderCombine(j)
+		err = renderCombine(ctx, j)
+	case "zip":
+		return renderZip(j)
+	case "changelog":
+		err = renderChangelog(ctx, j)
+	case "openapi":
+		err = renderOpenAPI(ctx, j)
+	case "dashboard":
+		return runDashboard(ctx, j)
 	default:
 		return fmt.Errorf("unknown job type %q", j.Type)
 	}
+	if errors.Is(err, errAllDrafts) {
+		log.Printf("Job %s: all sources are drafts, nothing to render", j.jobName())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if j.Archive.PDFs != "" {
+		return zipJobPDFs(j)
+	}
+	return nil
 }
 
 // renderSubfolders renders each README.md in matched subdirectories as a separate PDF
-func renderSubfolders(j job) error {
-	matches, err := findMatches(j.Source)
+func renderSubfolders(ctx context.Context, j job) error {
+	pdfOpts, err := j.pdfOptions()
+	if err != nil {
+		return err
+	}
+
+	matches, err := j.findSources()
 	if err != nil {
 		return err
 	}
@@ -110,26 +806,47 @@ func renderSubfolders(j job) error {
 		return fmt.Errorf("create output directory: %w", err)
 	}
 
+	// Front matter routing can send two documents to the same file
+	written := make(map[string]string)
+
 	for _, m := range matches {
-		if filepath.Base(m) != "README.md" {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !isREADME(m) {
 			continue
 		}
 
 		folder := filepath.Dir(m)
 		folderName := filepath.Base(folder)
-		outPDF := filepath.Join(j.Output, folderName+".pdf")
+		outPDF, err := documentOutput(m, j.Output, folderName+".pdf")
+		if err != nil {
+			log.Printf("Render %s: %v", m, err)
+			continue
+		}
+		if other, ok := written[outPDF]; ok {
+			log.Printf("Render %s: output %s is already written by %s", m, outPDF, other)
+			continue
+		}
+		written[outPDF] = m
+		if err := os.MkdirAll(filepath.Dir(outPDF), 0o755); err != nil {
+			return fmt.Errorf("create output directory: %w", err)
+		}
 
-		if err := renderMarkdownToPDF(renderConfig{
-			mdPath:  m,
-			outPath: outPDF,
-			baseDir: folder,
-		}); err != nil {
+		cfg := j.renderConfig(pdfOpts)
+		cfg.mdPath = m
+		cfg.outPath = outPDF
+		cfg.baseDir = folder
+		cfg.sources = []string{m}
+
+		if err := renderMarkdownToPDF(ctx, cfg); err != nil {
 			log.Printf("Render %s: %v", m, err)
 			continue
 		}
 
-		// Create source zip if src directory exists
-		if err := zipSourceIfExists(folder, j.Output, folderName); err != nil {
+		// Create source zip next to the PDF if src directory exists
+		baseName := strings.TrimSuffix(filepath.Base(outPDF), filepath.Ext(outPDF))
+		if err := zipSourceIfExists(folder, filepath.Dir(outPDF), baseName, j); err != nil {
 			log.Printf("Zip src %s: %v", folder, err)
 		}
 	}
@@ -138,8 +855,13 @@ func renderSubfolders(j job) error {
 }
 
 // renderSingle combines multiple markdown files into a single PDF
-func renderSingle(j job) error {
-	matches, err := findMatches(j.Source)
+func renderSingle(ctx context.Context, j job) error {
+	pdfOpts, err := j.pdfOptions()
+	if err != nil {
+		return err
+	}
+
+	matches, err := j.findSources()
 	if err != nil {
 		return err
 	}
@@ -148,24 +870,53 @@ func renderSingle(j job) error {
 		return fmt.Errorf("create output directory: %w", err)
 	}
 
+	// Determine base directory for image resolution
+	baseDir := ""
+	if len(matches) > 0 {
+		baseDir = filepath.Dir(matches[0])
+	}
+
+	cfg := j.renderConfig(pdfOpts)
+	cfg.outPath = j.Output
+	cfg.baseDir = baseDir
+	cfg.sources = matches
+
+	// Pre-generated HTML skips markdown conversion
+	html, err := htmlSources(matches)
+	if err != nil {
+		return err
+	}
+	if html {
+		if j.Chapters != "" {
+			log.Printf("Warning: job %s: chapters are not split from HTML sources", j.jobName())
+		}
+		return renderHTMLFiles(ctx, matches, j.WrapHTML, cfg)
+	}
+
 	// Combine all matched markdown files
-	combined, err := combineMarkdownFiles(matches, "\n\n")
+	combined, sections, err := combineMarkdownFiles(ctx, matches, "\n\n", cfg)
 	if err != nil {
 		return err
 	}
 
-	// Determine base directory for image resolution
-	baseDir := ""
-	if len(matches) > 0 {
-		baseDir = filepath.Dir(matches[0])
+	if err := renderCombinedMarkdown(ctx, combined, cfg, sections...); err != nil {
+		return err
 	}
 
-	return renderCombinedMarkdown(combined, j.Output, baseDir)
+	if j.Chapters == "" {
+		return nil
+	}
+	return renderChapters(ctx, splitMarkdownChapters(combined, baseDir, matches), j.Chapters, cfg)
 }
 
 // renderCombine merges multiple README.md files with folder headers into a single PDF
-func renderCombine(j job) error {
-	matches, err := findMatches(j.Source)
+func renderCombine(ctx context.Context, j job) error {
+	pdfOpts, err := j.pdfOptions()
+	if err != nil {
+		return err
+	}
+
+	matches, err := j.findSources()
 	if err != nil {
 		return err
 	}
@@ -182,193 +933,319 @@ func renderCombine(j job) error {
 
 	// Combine with folder headers, converting markdown to HTML for each README individually
 	// This ensures images are resolved relative to each README's directory
-	combined, err := combineREADMEsAsHTML(readmes)
-	if err != nil {
+	cfg := j.renderConfig(pdfOpts)
+	cfg.outPath = j.Output
+	cfg.sources = readmes
+	cfg.breaks = true
+
+	chapters := readmeChapters(ctx, readmes, cfg)
+	if err := renderCombinedHTML(ctx, combineChapterHTML(chapters), cfg); err != nil {
 		return err
 	}
 
-	return renderCombinedHTML(combined, j.Output)
+	// The chapters reuse the converted READMEs
+	if j.Chapters == "" {
+		return nil
+	}
+	return renderChapters(ctx, chapters, j.Chapters, cfg)
 }
 
-// findMatches finds all files matching the glob pattern
-func findMatches(pattern string) ([]string, error) {
-	matches, err := doublestar.Glob(os.DirFS("."), pattern)
+// findMatches finds all files matching the glob pattern, sorted by order
+// (natural or lexical). The pattern may be absolute or reach outside the working
+// directory, symlinked folders are followed, and README.md matches in any case.
+func findMatches(pattern, order string) ([]string, error) {
+	base, glob := doublestar.SplitPattern(filepath.ToSlash(filepath.Clean(pattern)))
+	matches, err := doublestar.Glob(newSourceFS(base), caselessREADME(glob))
 	if err != nil {
 		return nil, fmt.Errorf("glob pattern: %w", err)
 	}
 	if len(matches) == 0 {
 		return nil, fmt.Errorf("no matches for %s", pattern)
 	}
+	for i, m := range matches {
+		matches[i] = filepath.Join(base, filepath.FromSlash(m))
+	}
+	if err := natsort.Order(matches, order); err != nil {
+		return nil, err
+	}
 	return matches, nil
 }
 
-// filterREADMEs returns only README.md files from the list
+// filterREADMEs returns only README.md files (in any case) from the list
 func filterREADMEs(files []string) []string {
 	var readmes []string
 	for _, f := range files {
-		if filepath.Base(f) == "README.md" {
+		if isREADME(f) {
 			readmes = append(readmes, f)
 		}
 	}
 	return readmes
 }
 
-// combineMarkdownFiles reads and combines multiple markdown files
-func combineMarkdownFiles(files []string, separator string) (string, error) {
-	var parts []string
-	for _, f := range files {
-		content, err := os.ReadFile(f)
+// combineMarkdownFiles reads multiple markdown files, runs the pre_render hooks
+// over each, and combines them. The sections of several files namespace
+// their heading IDs after the file.
+func combineMarkdownFiles(ctx context.Context, files []string, separator string, cfg renderConfig) (string, []render.Section, error) {
+	var (
+		b          strings.Builder
+		sections   []render.Section
+		namespaces = sourceNamespaces(files)
+	)
+	for i, f := range files {
+		content, err := cfg.preRender(ctx, f)
 		if err != nil {
-			return "", fmt.Errorf("read %s: %w", f, err)
+			return "", nil, fmt.Errorf("read %s: %w", f, err)
+		}
+		if cfg.authors {
+			content = attribute(content, f)
+		}
+		if i > 0 {
+			b.WriteString(separator)
 		}
-		parts = append(parts, string(content))
+		if len(files) > 1 {
+			sections = append(sections, render.Section{Offset: b.Len(), Namespace: namespaces[i]})
+		}
+		b.Write(content)
 	}
-	return strings.Join(parts, separator), nil
+	return b.String(), sections, nil
 }
 
-// combineREADMEsAsHTML converts each README to HTML (with images embedded) and combines them
-func combineREADMEsAsHTML(readmes []string) (string, error) {
-	var htmlParts []string
+// sourceNamespaces names each source for its heading IDs: after its file, or
+// its folder for README and index files, made unique with a number
+func sourceNamespaces(files []string) []string {
+	namespaces := make([]string, len(files))
+	used := make(map[string]bool)
+	for i, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		if strings.EqualFold(name, "readme") || strings.EqualFold(name, "index") {
+			if dir := filepath.Base(filepath.Dir(f)); dir != "." && dir != string(filepath.Separator) {
+				name = dir
+			}
+		}
+		ns := slug(name, "doc")
+		for n := 2; used[ns]; n++ {
+			ns = fmt.Sprintf("%s-%d", slug(name, "doc"), n)
+		}
+		used[ns] = true
+		namespaces[i] = ns
+	}
+	return namespaces
+}
+
+// readmeChapters converts each README to HTML (with images embedded), one chapter per folder
+func readmeChapters(ctx context.Context, readmes []string, cfg renderConfig) []chapter {
+	var chapters []chapter
 
-	for _, readme := range readmes {
+	// Namespace the heading IDs of several READMEs, which share section names
+	namespaces := sourceNamespaces(readmes)
+	for i, readme := range readmes {
 		folder := filepath.Dir(readme)
-		folderName := filepath.Base(folder)
+		var sections []render.Section
+		if len(readmes) > 1 {
+			sections = []render.Section{{Namespace: namespaces[i]}}
+		}
 
-		// Read markdown content
-		content, err := os.ReadFile(readme)
+		// Read markdown content and run the pre_render hooks
+		content, err := cfg.preRender(ctx, readme)
 		if err != nil {
 			log.Printf("Warning: failed to read %s: %v", readme, err)
 			continue
 		}
+		if cfg.authors {
+			content = attribute(content, readme)
+		}
 
-		// Convert markdown to HTML
-		htmlBody, err := mdConverter.ToHTML(content)
+		// Convert markdown to HTML with images embedded relative to this README's directory
+		htmlWithImages, err := render.MarkdownBodyHTML(content, folder, cfg.markdown, cfg.safe, sections...)
 		if err != nil {
 			log.Printf("Warning: failed to convert markdown %s: %v", readme, err)
 			continue
 		}
 
-		// Embed images relative to this README's directory
-		htmlWithImages, err := images.EmbedImagesAsBase64(htmlBody, folder)
-		if err != nil {
-			log.Printf("Warning: failed to embed images for %s: %v", readme, err)
-			// Continue anyway with non-embedded images
-			htmlWithImages = htmlBody
-		}
-
-		// Add folder name as HTML header and the content
-		htmlParts = append(htmlParts, fmt.Sprintf("<div id=\"%s\" style=\"page-break-before: always; visibility:hidden\"></div>\n%s", folderName, htmlWithImages))
+		chapters = append(chapters, chapter{
+			title:   filepath.Base(folder),
+			sources: []string{readme},
+			html:    htmlWithImages,
+			baseDir: folder,
+		})
 	}
 
-	return strings.Join(htmlParts, "\n\n"), nil
+	return chapters
 }
 
-// renderCombinedHTML wraps combined HTML content and renders it to PDF
-func renderCombinedHTML(htmlContent, outputPath string) error {
-	// Wrap in styled HTML template
-	fullHTML, err := wrapHTML(htmlContent, "Combined")
-	if err != nil {
-		return fmt.Errorf("wrap HTML: %w", err)
+// combineChapterHTML combines converted READMEs, each starting a new page
+// behind an anchor named after its folder
+func combineChapterHTML(chapters []chapter) string {
+	var htmlParts []string
+	for _, ch := range chapters {
+		htmlParts = append(htmlParts, fmt.Sprintf("<div id=\"%s\" style=\"page-break-before: always; visibility:hidden\"></div>\n%s", ch.title, ch.html))
 	}
+	return strings.Join(htmlParts, "\n\n")
+}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
-		return fmt.Errorf("create output directory: %w", err)
-	}
+// renderCombinedHTML wraps combined HTML content and renders it to PDF
+func renderCombinedHTML(ctx context.Context, htmlContent string, cfg renderConfig) error {
+	return renderDocument(ctx, render.RenderRequest{
+		HTML:  htmlContent,
+		Title: cfg.title,
+	}, cfg)
+}
 
-	// Convert HTML to PDF
-	if err := pdf.FromHTML(fullHTML, outputPath); err != nil {
-		return fmt.Errorf("convert to PDF: %w", err)
-	}
+// renderCombinedMarkdown renders already combined and preprocessed markdown
+func renderCombinedMarkdown(ctx context.Context, content string, cfg renderConfig, sections ...render.Section) error {
+	return renderDocument(ctx, render.RenderRequest{
+		Markdown: []byte(content),
+		Sections: sections,
+		BaseDir:  cfg.baseDir,
+		Title:    cfg.title,
+	}, cfg)
+}
 
-	log.Printf("Rendered: %s", outputPath)
-	return nil
+// enableAttributes turns the attributes extension back on for generated
+// markdown that styles its headings with classes
+func (cfg *renderConfig) enableAttributes() {
+	cfg.markdown.Disable = slices.DeleteFunc(slices.Clone(cfg.markdown.Disable), func(ext string) bool {
+		return ext == "attributes"
+	})
 }
 
-// renderCombinedMarkdown writes combined markdown to a temp file and renders it
-func renderCombinedMarkdown(content, outputPath, baseDir string) error {
-	tmpFile, err := os.CreateTemp("", "combined-*.md")
+// appendix returns the generated pages appended to the document; failures are
+// logged so a missing git history doesn't fail the render
+func (cfg renderConfig) appendix() string {
+	tree, err := treeAppendix(cfg.tree, cfg.sort)
 	if err != nil {
-		return fmt.Errorf("create temp file: %w", err)
+		log.Printf("Warning: directory tree for %s: %v", cfg.outPath, err)
 	}
-	defer os.Remove(tmpFile.Name())
-
-	if _, err := tmpFile.WriteString(content); err != nil {
-		tmpFile.Close()
-		return fmt.Errorf("write temp file: %w", err)
+	licenses, err := licensesAppendix(cfg.licenses)
+	if err != nil {
+		log.Printf("Warning: license report for %s: %v", cfg.outPath, err)
 	}
-	tmpFile.Close()
-
-	return renderMarkdownToPDF(renderConfig{
-		mdPath:  tmpFile.Name(),
-		outPath: outputPath,
-		baseDir: baseDir,
-	})
+	history, err := historyAppendix(cfg.sources, cfg.history)
+	if err != nil {
+		log.Printf("Warning: document history for %s: %v", cfg.outPath, err)
+	}
+	return tree + licenses + history
 }
 
-// zipSourceIfExists creates a zip of the src directory if it exists
-func zipSourceIfExists(folder, outputDir, baseName string) error {
-	srcDir := filepath.Join(folder, "src")
-	if _, err := os.Stat(srcDir); os.IsNotExist(err) {
-		return nil
+// resolvePaths resolves the relative paths the tree and licenses appendices
+// and the spellcheck read against dir, as source is
+func (j *job) resolvePaths(dir string) {
+	for _, p := range []*string{&j.Spellcheck.Dictionary, &j.Spellcheck.Vale} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	if j.Tree.Path != "" && !filepath.IsAbs(j.Tree.Path) {
+		j.Tree.Path = filepath.Join(dir, j.Tree.Path)
 	}
+	files := make([]string, len(j.Licenses.Files))
+	for k, f := range j.Licenses.Files {
+		files[k] = f
+		if !filepath.IsAbs(f) {
+			files[k] = filepath.Join(dir, f)
+		}
+	}
+	j.Licenses.Files = files
+}
 
-	zipName := filepath.Join(outputDir, baseName+"_src.zip")
-	return ziputil.CreateFromFolder(srcDir, zipName)
+// documentQRCode returns the QR code link of the document, naming its PDF in
+// place of {output} (the output path) and {name} (the file name)
+func (cfg renderConfig) documentQRCode() string {
+	return strings.NewReplacer(
+		"{output}", filepath.ToSlash(cfg.outPath),
+		"{name}", filepath.Base(cfg.outPath),
+	).Replace(cfg.qrCode)
 }
 
 // renderMarkdownToPDF converts a markdown file to PDF
-func renderMarkdownToPDF(cfg renderConfig) error {
-	// Read markdown source
-	src, err := os.ReadFile(cfg.mdPath)
+func renderMarkdownToPDF(ctx context.Context, cfg renderConfig) error {
+	content, err := cfg.preRender(ctx, cfg.mdPath)
 	if err != nil {
-		return fmt.Errorf("read markdown: %w", err)
+		return err
 	}
 
-	// Convert markdown to HTML
-	htmlBody, err := mdConverter.ToHTML(src)
+	return renderDocument(ctx, render.RenderRequest{
+		Markdown:   content,
+		SourcePath: cfg.mdPath,
+		BaseDir:    cfg.baseDir,
+		Title:      cfg.title,
+	}, cfg)
+}
+
+// renderDocument fills in the job-wide settings of a request, renders it, and
+// records the PDF
+func renderDocument(ctx context.Context, req render.RenderRequest, cfg renderConfig) error {
+	req.OutputPath = cfg.outPath
+	req.Locale = cfg.locale
+	req.Layout = cfg.layout
+	req.MarkdownOptions = cfg.markdown
+	req.ListOfFigures = cfg.listOfFigures
+	req.ListOfTables = cfg.listOfTables
+	req.LinkURLs = cfg.linkURLs
+	req.QRCode = cfg.documentQRCode()
+	req.Theme = cfg.theme
+	req.TemplateDir = cfg.templateDir
+	req.Inject = cfg.inject
+	req.Appendix = cfg.appendix()
+	req.TransformHTML = cfg.postRender(ctx)
+	req.PDF = &cfg.pdfOpts
+	req.Safe = cfg.safe
+	req.Trace = renderTrace(cfg.outPath)
+	req.LogResources = cfg.requests
+	req.ImagePlaceholders = cfg.missing
+	req.LocateHeadings = cfg.srcMap
+	keepIntermediates(&req, cfg.outPath)
+
+	lintWarnings, err := lintSources(cfg.sources, cfg.lint, cfg.lintOff)
 	if err != nil {
-		return fmt.Errorf("convert markdown: %w", err)
+		return err
 	}
-
-	// Determine base directory for resolving images
-	baseDir := cfg.baseDir
-	if baseDir == "" {
-		baseDir = filepath.Dir(cfg.mdPath)
+	imageWarnings, err := checkImages(cfg.sources, cfg.images, cfg.baseDir)
+	if err != nil {
+		return err
 	}
 
-	// Embed images as base64 data URLs
-	htmlWithImages, err := images.EmbedImagesAsBase64(htmlBody, baseDir)
+	start := time.Now()
+	res, err := render.Render(ctx, req)
 	if err != nil {
-		return fmt.Errorf("embed images: %w", err)
+		return err
 	}
+	traceStage(cfg.outPath, "total", start)
+	res.Warnings = slices.Concat(lintWarnings, imageWarnings, res.Warnings)
 
-	// Wrap in styled HTML template
-	htmlContent, err := wrapHTML(htmlWithImages, filepath.Base(cfg.mdPath))
-	if err != nil {
-		return fmt.Errorf("wrap HTML: %w", err)
+	if err := enforceLimits(res, cfg.limits); err != nil {
+		return err
 	}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(filepath.Dir(cfg.outPath), 0o755); err != nil {
-		return fmt.Errorf("create output directory: %w", err)
+	if err := checkAccessibility(&res, cfg.a11y); err != nil {
+		return err
+	}
+	spellcheck(ctx, &res, cfg.sources, cfg.spelling)
+
+	if err := recordPDF(res, cfg); err != nil {
+		return err
+	}
+	if cfg.srcMap {
+		if err := writeSourceMap(res, cfg); err != nil {
+			log.Printf("Warning: source map for %s: %v", cfg.outPath, err)
+		}
 	}
 
-	// Convert HTML to PDF
-	if err := pdf.FromHTML(htmlContent, cfg.outPath); err != nil {
-		return fmt.Errorf("convert to PDF: %w", err)
+	if cfg.confluence != nil {
+		if err := exportConfluence(ctx, res, cfg); err != nil {
+			return err
+		}
 	}
 
 	log.Printf("Rendered: %s", cfg.outPath)
 	return nil
 }
 
-// wrapHTML wraps HTML content in a styled template
-func wrapHTML(content, title string) (string, error) {
-	data := pageData{
-		Title:   title,
-		Content: template.HTML(content),
+// enforceLimits checks a rendered PDF against the job limits and removes it if the job must fail
+func enforceLimits(res render.Result, limits outputLimits) error {
+	if err := limits.check(res); err != nil {
+		os.Remove(res.OutputPath)
+		return err
 	}
-
-	return tmplLoader.Render("template.html", data)
+	return nil
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kuzik/markdown-pdf-action/internal/openapi"
+)
+
+// openAPICSS sets operations apart with colored method badges and keeps the
+// parameter and schema tables compact
+const openAPICSS = `<style>
+h3.operation { padding: 0.4em 0.6em; border-radius: 4px; background: #f6f8fa; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 1em; break-after: avoid; }
+h3.operation code { display: inline-block; min-width: 4.5em; margin-right: 0.5em; padding: 0.1em 0.4em; border-radius: 3px; text-align: center; color: #fff; background: #57606a; }
+h3.get code { background: #0969da; }
+h3.post code { background: #1a7f37; }
+h3.put code { background: #9a6700; }
+h3.patch code { background: #bc4c00; }
+h3.delete code { background: #cf222e; }
+h3.schema { border-bottom: 1px solid #d0d7de; padding-bottom: 0.2em; }
+table td:first-child code { white-space: nowrap; }
+pre { break-inside: avoid; }
+</style>`
+
+// renderOpenAPI renders an OpenAPI or Swagger spec as an API reference
+func renderOpenAPI(ctx context.Context, j job) error {
+	pdfOpts, err := j.pdfOptions()
+	if err != nil {
+		return err
+	}
+
+	matches, err := j.findSources()
+	if err != nil {
+		return err
+	}
+	if len(matches) > 1 {
+		return fmt.Errorf("openapi jobs take one spec, %s matches %d", j.Source, len(matches))
+	}
+
+	src, err := os.ReadFile(matches[0])
+	if err != nil {
+		return fmt.Errorf("read spec: %w", err)
+	}
+	spec, err := openapi.Parse(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", matches[0], err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.Output), 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	cfg := j.renderConfig(pdfOpts)
+	cfg.outPath = j.Output
+	cfg.baseDir = filepath.Dir(matches[0])
+	cfg.sources = matches
+	cfg.inject.Head = openAPICSS + cfg.inject.Head
+
+	// Operations and schemas are styled with heading attributes
+	cfg.enableAttributes()
+
+	if cfg.title == "" {
+		cfg.title = spec.Title
+	}
+
+	return renderCombinedMarkdown(ctx, spec.Markdown(), cfg)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// permissionsConfig sets the mode and owner of the files a job generates and
+// the folders holding them, e.g. group-writable outputs on a shared volume
+type permissionsConfig struct {
+	FileMode string `yaml:"file_mode"` // octal mode of generated files, e.g. "0664"
+	DirMode  string `yaml:"dir_mode"`  // octal mode of their folders, e.g. "2775"
+	Owner    string `yaml:"owner"`     // user, user:group, or :group, by name or numeric ID
+}
+
+// permissionDefaults applies to jobs that don't set their own permissions and
+// to the manifest and checksums; set by --file-mode, --dir-mode, and --owner
+var permissionDefaults permissionsConfig
+
+// umask is set by --umask and applies to every file the run creates
+var umask string
+
+// or fills the options p leaves empty from defaults
+func (p permissionsConfig) or(defaults permissionsConfig) permissionsConfig {
+	return permissionsConfig{
+		FileMode: cmp.Or(p.FileMode, defaults.FileMode),
+		DirMode:  cmp.Or(p.DirMode, defaults.DirMode),
+		Owner:    cmp.Or(p.Owner, defaults.Owner),
+	}
+}
+
+// validate checks the modes and looks up the owner
+func (p permissionsConfig) validate() error {
+	if _, err := parseMode(p.FileMode); err != nil {
+		return fmt.Errorf("file_mode: %w", err)
+	}
+	if _, err := parseMode(p.DirMode); err != nil {
+		return fmt.Errorf("dir_mode: %w", err)
+	}
+	if _, _, err := lookupOwner(p.Owner); err != nil {
+		return fmt.Errorf("owner: %w", err)
+	}
+	return nil
+}
+
+// set applies the configured modes and owner to files and dirs
+func (p permissionsConfig) set(files, dirs []string) error {
+	fileMode, _ := parseMode(p.FileMode)
+	dirMode, _ := parseMode(p.DirMode)
+	uid, gid, err := lookupOwner(p.Owner)
+	if err != nil {
+		return err
+	}
+
+	apply := func(path string, mode os.FileMode) error {
+		if mode != 0 {
+			if err := os.Chmod(path, mode); err != nil {
+				return err
+			}
+		}
+		if uid != -1 || gid != -1 {
+			if err := os.Lchown(path, uid, gid); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, f := range files {
+		if err := apply(f, fileMode); err != nil {
+			return err
+		}
+	}
+	for _, d := range dirs {
+		if err := apply(d, dirMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setPermissions applies the job's permissions to the files it generated and
+// the folders holding them, up to and including its output folder
+func setPermissions(j job) error {
+	p := j.Permissions.or(permissionDefaults)
+	if p == (permissionsConfig{}) {
+		return nil
+	}
+
+	var files []string
+	for _, a := range artifacts.Job(j.jobName()) {
+		files = append(files, a.Output)
+	}
+	// Dashboard outputs aren't recorded as artifacts
+	claimed, _ := j.outputClaims()
+	for _, f := range claimed {
+		if info, err := os.Stat(f); err == nil && info.Mode().IsRegular() {
+			files = append(files, f)
+		}
+	}
+	files = compactPaths(files)
+
+	roots := []string{filepath.Dir(j.Output)}
+	if j.Type == "subfolders" {
+		roots = []string{j.Output}
+	}
+	if j.Chapters != "" {
+		roots = append(roots, j.Chapters)
+	}
+
+	var dirs []string
+	for _, f := range files {
+		for dir := filepath.Dir(f); ; dir = filepath.Dir(dir) {
+			root := slices.ContainsFunc(roots, func(r string) bool { return filepath.Clean(r) == dir })
+			if !slices.ContainsFunc(roots, func(r string) bool { return within(dir, r) }) {
+				break
+			}
+			// Never change the working directory the job writes into
+			if dir != "." {
+				dirs = append(dirs, dir)
+			}
+			if root {
+				break
+			}
+		}
+	}
+
+	if err := p.set(files, compactPaths(dirs)); err != nil {
+		return fmt.Errorf("set permissions: %w", err)
+	}
+	return nil
+}
+
+// compactPaths returns the cleaned paths sorted without duplicates
+func compactPaths(paths []string) []string {
+	for i := range paths {
+		paths[i] = filepath.Clean(paths[i])
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+// parseMode parses an octal mode such as 0664 or 2775, returning 0 for ""
+func parseMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || n > 0o7777 {
+		return 0, fmt.Errorf("invalid mode %q (use octal, e.g. 0664)", s)
+	}
+	mode := os.FileMode(n & 0o777)
+	if n&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if n&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if n&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode, nil
+}
+
+// lookupOwner resolves user, user:group, or :group to IDs; -1 leaves one unchanged
+func lookupOwner(owner string) (uid, gid int, err error) {
+	uid, gid = -1, -1
+	if owner == "" {
+		return uid, gid, nil
+	}
+
+	name, group, _ := strings.Cut(owner, ":")
+	if name != "" {
+		if uid, err = strconv.Atoi(name); err != nil {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return -1, -1, err
+			}
+			if uid, err = strconv.Atoi(u.Uid); err != nil {
+				return -1, -1, fmt.Errorf("user %s has no numeric ID", name)
+			}
+		}
+	}
+	if group != "" {
+		if gid, err = strconv.Atoi(group); err != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return -1, -1, err
+			}
+			if gid, err = strconv.Atoi(g.Gid); err != nil {
+				return -1, -1, fmt.Errorf("group %s has no numeric ID", group)
+			}
+		}
+	}
+	return uid, gid, nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// jobState tracks a job through the pipeline
+type jobState int
+
+const (
+	jobPending jobState = iota
+	jobRunning
+	jobSucceeded
+	jobFailed
+	jobSkipped
+)
+
+// jobResult reports a finished job back to the dispatcher
+type jobResult struct {
+	index int
+	err   error
+}
+
+// jobOutcome is how a job ended; jobs never started keep jobPending
+type jobOutcome struct {
+	state jobState
+	err   error
+}
+
+// resolveDependencies maps each job's depends_on names to job indexes and rejects
+// unknown or ambiguous names and dependency cycles
+func resolveDependencies(jobs []job) ([][]int, error) {
+	byName := make(map[string]int, len(jobs))
+	ambiguous := make(map[string]bool)
+	for i, j := range jobs {
+		name := j.jobName()
+		if _, exists := byName[name]; exists {
+			ambiguous[name] = true
+		}
+		byName[name] = i
+	}
+
+	deps := make([][]int, len(jobs))
+	for i, j := range jobs {
+		for _, name := range j.DependsOn {
+			if ambiguous[name] {
+				return nil, fmt.Errorf("job %q: dependency %q matches several jobs; give them unique names", j.jobName(), name)
+			}
+			d, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("job %q: unknown dependency %q", j.jobName(), name)
+			}
+			if d == i {
+				return nil, fmt.Errorf("job %q depends on itself", j.jobName())
+			}
+			deps[i] = append(deps[i], d)
+		}
+	}
+
+	if cycle := findCycle(jobs, deps); cycle != nil {
+		return nil, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	return deps, nil
+}
+
+// findCycle returns the job names forming a dependency cycle, or nil
+func findCycle(jobs []job, deps [][]int) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	marks := make([]int, len(jobs))
+	var stack []int
+	var cycle []string
+
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		marks[i] = visiting
+		stack = append(stack, i)
+
+		for _, d := range deps[i] {
+			switch marks[d] {
+			case visiting:
+				// Report the cycle from the first occurrence of d on the stack
+				for k := len(stack) - 1; k >= 0; k-- {
+					if stack[k] == d {
+						for _, idx := range stack[k:] {
+							cycle = append(cycle, jobs[idx].jobName())
+						}
+						cycle = append(cycle, jobs[d].jobName())
+						return true
+					}
+				}
+			case unvisited:
+				if visit(d) {
+					return true
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		marks[i] = visited
+		return false
+	}
+
+	for i := range jobs {
+		if marks[i] == unvisited && visit(i) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// runPipeline runs jobs once their dependencies have succeeded, starting ready
+// jobs in config order with at most parallel jobs running at once. Jobs whose
+// dependencies failed or were skipped are skipped. Each finished job notifies
+// its webhook. The outcomes are returned in job order.
+func runPipeline(ctx context.Context, jobs []job, deps [][]int, parallel int) []jobOutcome {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	states := make([]jobState, len(jobs))
+	errs := make([]error, len(jobs))
+	results := make(chan jobResult)
+	running := 0
+
+	for {
+		// Skipping a job can unblock others, so repeat until nothing changes
+		for changed := true; changed; {
+			changed = false
+			for i, j := range jobs {
+				if states[i] != jobPending {
+					continue
+				}
+
+				ready, blockedBy := true, -1
+				for _, d := range deps[i] {
+					switch states[d] {
+					case jobSucceeded:
+					case jobFailed, jobSkipped:
+						blockedBy = d
+					default:
+						ready = false
+					}
+				}
+
+				if blockedBy >= 0 {
+					errs[i] = fmt.Errorf("dependency %q did not succeed", jobs[blockedBy].jobName())
+					log.Printf("Job skipped (%s): %v", j.jobName(), errs[i])
+					states[i] = jobSkipped
+					notifyJob(ctx, j, jobSkipped, errs[i])
+					changed = true
+					continue
+				}
+
+				if !ready || running >= parallel || ctx.Err() != nil {
+					continue
+				}
+
+				states[i] = jobRunning
+				running++
+				go func(i int, j job) {
+					err := executeJob(ctx, j)
+					if err == nil && ctx.Err() == nil {
+						cleanOutputs(j, jobs)
+					}
+					if err == nil {
+						err = setPermissions(j)
+					}
+					if err == nil {
+						err = publishJob(ctx, j)
+					}
+					if err == nil {
+						err = emailJob(j)
+					}
+					if err != nil {
+						log.Printf("Job failed (%s): %v", j.jobName(), err)
+						notifyJob(ctx, j, jobFailed, err)
+					} else {
+						notifyJob(ctx, j, jobSucceeded, nil)
+					}
+					results <- jobResult{index: i, err: err}
+				}(i, j)
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		if res.err != nil {
+			states[res.index] = jobFailed
+			errs[res.index] = res.err
+		} else {
+			states[res.index] = jobSucceeded
+		}
+	}
+
+	outcomes := make([]jobOutcome, len(jobs))
+	for i := range jobs {
+		outcomes[i] = jobOutcome{state: states[i], err: errs[i]}
+	}
+	return outcomes
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/kuzik/markdown-pdf-action/internal/publish"
+)
+
+// publishConfig uploads a job's generated files to cloud storage
+type publishConfig struct {
+	Provider     string            `yaml:"provider"`      // s3 | gcs | azure
+	Bucket       string            `yaml:"bucket"`        // bucket, or Azure container
+	Prefix       string            `yaml:"prefix"`        // key prefix, e.g. docs/{branch}/
+	Region       string            `yaml:"region"`        // S3 region (default AWS_REGION or us-east-1)
+	Endpoint     string            `yaml:"endpoint"`      // S3-compatible or Azure endpoint, e.g. http://minio:9000
+	PublicURL    string            `yaml:"public_url"`    // base URL of the published files, e.g. a CDN
+	ContentTypes map[string]string `yaml:"content_types"` // extension to Content-Type overrides
+}
+
+// publisher returns the configured uploader, or nil when publishing is disabled
+func (p publishConfig) publisher() (*publish.Publisher, error) {
+	if p.Provider == "" {
+		return nil, nil
+	}
+	return publish.New(publish.Config{
+		Provider:     p.Provider,
+		Bucket:       p.Bucket,
+		Prefix:       p.Prefix,
+		Region:       p.Region,
+		Endpoint:     p.Endpoint,
+		PublicURL:    p.PublicURL,
+		ContentTypes: p.ContentTypes,
+	})
+}
+
+// publishJob uploads the files the job recorded in the manifest and records
+// their public URLs. Keys are the file paths relative to their common folder.
+func publishJob(ctx context.Context, j job) error {
+	pub, err := j.Publish.publisher()
+	if err != nil || pub == nil {
+		return err
+	}
+
+	files := artifacts.Job(j.jobName())
+	if len(files) == 0 {
+		return nil
+	}
+
+	outputs := make([]string, len(files))
+	for i, a := range files {
+		outputs[i] = filepath.Clean(a.Output)
+	}
+	root := commonDir(outputs)
+
+	for _, a := range files {
+		name, err := filepath.Rel(root, a.Output)
+		if err != nil {
+			name = filepath.Base(a.Output)
+		}
+
+		url, err := pub.Upload(ctx, a.Output, name)
+		if err != nil {
+			return fmt.Errorf("publish: %w", err)
+		}
+		artifacts.SetURL(a.Output, url)
+		log.Printf("Published: %s -> %s", a.Output, url)
+	}
+	return nil
+}
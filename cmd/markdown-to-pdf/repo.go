@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// repoShorthand matches owner/name repositories on the GitHub server
+var repoShorthand = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+
+// checkout is a shallow clone shared by the jobs rendering the same repo and ref
+type checkout struct {
+	once sync.Once
+	dir  string
+	err  error
+}
+
+// checkouts caches clones by repository URL and ref for the whole run
+var checkouts = struct {
+	sync.Mutex
+	byKey map[string]*checkout
+}{byKey: make(map[string]*checkout)}
+
+// repoURL returns the clone URL of the job's repo: owner/name on the GitHub
+// server (GITHUB_SERVER_URL, default github.com), or any URL git accepts
+func (j job) repoURL() string {
+	if !repoShorthand.MatchString(j.Repo) {
+		return j.Repo
+	}
+	server := os.Getenv("GITHUB_SERVER_URL")
+	if server == "" {
+		server = "https://github.com"
+	}
+	return strings.TrimSuffix(server, "/") + "/" + strings.TrimSuffix(j.Repo, ".git") + ".git"
+}
+
+// validateRepo checks the repo options of a job
+func (j job) validateRepo() error {
+	if j.Repo == "" {
+		if j.Ref != "" {
+			return fmt.Errorf("ref needs a repo")
+		}
+		return nil
+	}
+	if !repoShorthand.MatchString(j.Repo) && !strings.Contains(j.Repo, "://") && !strings.Contains(j.Repo, "@") {
+		return fmt.Errorf("%q is neither owner/name nor a clone URL", j.Repo)
+	}
+	if filepath.IsAbs(j.Source) {
+		return fmt.Errorf("source must be a path inside the repository")
+	}
+	if strings.HasPrefix(j.Ref, "-") {
+		return fmt.Errorf("invalid ref %q", j.Ref)
+	}
+	return nil
+}
+
+// withCheckout fetches the job's repo at its ref, if it has one, and points the
+// source into the clone
+func (j job) withCheckout(ctx context.Context) (job, error) {
+	if j.Repo == "" {
+		return j, nil
+	}
+
+	key := j.repoURL() + "@" + j.Ref
+	checkouts.Lock()
+	c, ok := checkouts.byKey[key]
+	if !ok {
+		c = &checkout{}
+		checkouts.byKey[key] = c
+	}
+	checkouts.Unlock()
+
+	c.once.Do(func() {
+		c.dir, c.err = cloneRepo(ctx, j.repoURL(), j.Ref)
+	})
+	if c.err != nil {
+		return j, fmt.Errorf("fetch %s: %w", j.Repo, c.err)
+	}
+
+	j.Source = filepath.Join(c.dir, j.Source)
+	j.resolvePaths(c.dir)
+	return j, nil
+}
+
+// cloneRepo fetches a single commit of repo at ref (default branch when empty)
+// into a temporary directory. Branches, tags, and commit SHAs all work.
+func cloneRepo(ctx context.Context, repo, ref string) (string, error) {
+	dir, err := os.MkdirTemp("", "markdown-to-pdf-repo-*")
+	if err != nil {
+		return "", fmt.Errorf("create checkout directory: %w", err)
+	}
+
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", repo},
+		{"fetch", "--quiet", "--depth", "1", "--no-tags", "origin", ref},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		if err := runGit(ctx, dir, repo, args...); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+
+	sha, _ := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--short", "HEAD").Output()
+	log.Printf("Fetched %s at %s (%s)", repo, ref, strings.TrimSpace(string(sha)))
+	return dir, nil
+}
+
+// runGit runs a git command in dir, authenticating requests to the GitHub
+// server with GITHUB_TOKEN when it is set. The header is passed through the
+// environment, so the token doesn't show on the command line other processes
+// can list.
+func runGit(ctx context.Context, dir, repo string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if header := authHeader(repo); header != "" {
+		// Add to any configuration the environment already passes this way
+		n, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+		cmd.Env = append(cmd.Env,
+			fmt.Sprintf("GIT_CONFIG_COUNT=%d", n+1),
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=http.extraHeader", n),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", n, header),
+		)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// authHeader returns the Authorization header for cloning repo with
+// GITHUB_TOKEN, or "" when there is no token or repo is on another host
+func authHeader(repo string) string {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return ""
+	}
+
+	server := os.Getenv("GITHUB_SERVER_URL")
+	if server == "" {
+		server = "https://github.com"
+	}
+	s, err := url.Parse(server)
+	if err != nil {
+		return ""
+	}
+	r, err := url.Parse(repo)
+	if err != nil || r.Scheme != "https" || r.Host != s.Host {
+		return ""
+	}
+
+	creds := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+	return "Authorization: Basic " + creds
+}
+
+// removeCheckouts deletes the repositories cloned during the run
+func removeCheckouts() {
+	checkouts.Lock()
+	defer checkouts.Unlock()
+	for _, c := range checkouts.byKey {
+		if c.dir != "" {
+			os.RemoveAll(c.dir)
+		}
+	}
+}
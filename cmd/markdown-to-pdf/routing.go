@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/frontmatter"
+)
+
+// documentOutput returns where the PDF of a source is written: defaultName in
+// dir, unless the source's front matter routes it with an output path relative
+// to dir. A path ending in "/" keeps the default name in that subdirectory.
+func documentOutput(src, dir, defaultName string) (string, error) {
+	fields, err := frontmatter.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+
+	route := frontmatter.String(fields, "output")
+	if route == "" {
+		return filepath.Join(dir, defaultName), nil
+	}
+
+	rel := filepath.Clean(filepath.FromSlash(route))
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("front matter output %q must be a path inside the job output", route)
+	}
+	if strings.HasSuffix(route, "/") {
+		rel = filepath.Join(rel, defaultName)
+	} else if !strings.EqualFold(filepath.Ext(rel), ".pdf") {
+		rel += ".pdf"
+	}
+	return filepath.Join(dir, rel), nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
+)
+
+// sourceMap is the sidecar telling which pages of a document came from which
+// source, written next to the PDF as <name>.sourcemap.json
+type sourceMap struct {
+	PDF     string            `json:"pdf"`
+	Pages   int               `json:"pages"`
+	Sources []sourceMapSource `json:"sources"`
+}
+
+type sourceMapSource struct {
+	Source   string             `json:"source"`
+	From     int                `json:"from,omitempty"` // first page
+	To       int                `json:"to,omitempty"`   // last page
+	Headings []sourceMapHeading `json:"headings,omitempty"`
+}
+
+type sourceMapHeading struct {
+	Level int    `json:"level"`
+	Title string `json:"title"`
+	ID    string `json:"id,omitempty"`
+	Page  int    `json:"page"`
+}
+
+// sourceMapPath returns where the source map of the PDF at outPath is written
+func sourceMapPath(outPath string) string {
+	return strings.TrimSuffix(outPath, filepath.Ext(outPath)) + ".sourcemap.json"
+}
+
+// writeSourceMap writes the source map of a rendered document. Headings are
+// attributed to sources by the namespace of their IDs (see sourceNamespaces),
+// and a source runs from the page of its first heading to where the next one
+// starts: the page before with breaks, or the same page without.
+func writeSourceMap(res render.Result, cfg renderConfig) error {
+	m := sourceMap{PDF: res.OutputPath, Pages: res.Pages}
+	for _, src := range cfg.sources {
+		m.Sources = append(m.Sources, sourceMapSource{Source: src})
+	}
+	if len(m.Sources) == 0 {
+		return nil
+	}
+
+	namespaces := sourceNamespaces(cfg.sources)
+	current := 0
+	for _, h := range res.Headings {
+		if len(cfg.sources) > 1 {
+			if i := namespaceOf(h.ID, namespaces); i >= 0 {
+				current = i
+			}
+		}
+		if h.Page == 0 {
+			continue
+		}
+		s := &m.Sources[current]
+		if s.From == 0 {
+			s.From = h.Page
+		}
+		s.Headings = append(s.Headings, sourceMapHeading{Level: h.Level, Title: h.Title, ID: h.ID, Page: h.Page})
+	}
+
+	// Text before the first heading belongs to the first source
+	m.Sources[0].From = 1
+	last := -1
+	for i := range m.Sources {
+		if m.Sources[i].From == 0 {
+			continue
+		}
+		if last >= 0 {
+			m.Sources[last].To = max(m.Sources[last].From, m.Sources[i].From)
+			if cfg.breaks && m.Sources[i].From > m.Sources[last].From {
+				m.Sources[last].To = m.Sources[i].From - 1
+			}
+		}
+		last = i
+	}
+	m.Sources[last].To = max(m.Sources[last].From, res.Pages)
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := sourceMapPath(res.OutputPath)
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write source map: %w", err)
+	}
+	log.Printf("Source map: %s", path)
+	return recordArtifact(manifest.Artifact{Output: path, Kind: "sourcemap", Sources: cfg.sources, Job: cfg.jobName})
+}
+
+// namespaceOf returns the index of the longest namespace prefixing id, or -1
+func namespaceOf(id string, namespaces []string) int {
+	found := -1
+	for i, ns := range namespaces {
+		if strings.HasPrefix(id, ns+"-") && (found < 0 || len(ns) > len(namespaces[found])) {
+			found = i
+		}
+	}
+	return found
+}
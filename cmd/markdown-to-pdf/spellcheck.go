@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/spell"
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
+)
+
+// spellcheckConfig checks the prose of a job's markdown sources
+type spellcheckConfig struct {
+	Lang       string `yaml:"lang"`       // hunspell dictionary, e.g. en_US
+	Dictionary string `yaml:"dictionary"` // file of accepted words, one per line, resolved like source
+	Vale       string `yaml:"vale"`       // .vale.ini of Vale style rules, resolved like source
+}
+
+func (s spellcheckConfig) enabled() bool {
+	return s.Lang != "" || s.Vale != ""
+}
+
+// spellcheck checks the markdown sources of a document and records what it
+// finds as document warnings. Problems running the checkers are logged
+// rather than failing the document.
+func spellcheck(ctx context.Context, res *render.Result, sources []string, s spellcheckConfig) {
+	if !s.enabled() {
+		return
+	}
+	var paths []string
+	for _, src := range sources {
+		switch strings.ToLower(filepath.Ext(src)) {
+		case ".md", ".markdown":
+			paths = append(paths, src)
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	var words map[string]bool
+	if s.Dictionary != "" {
+		var err error
+		if words, err = spell.LoadDictionary(s.Dictionary); err != nil {
+			log.Printf("Warning: spellcheck dictionary: %v", err)
+		}
+	}
+
+	var findings []spell.Finding
+	if s.Lang != "" {
+		bin := binary("HUNSPELL_BIN", "hunspell")
+		for _, path := range paths {
+			found, err := spell.Hunspell(ctx, bin, s.Lang, words, path)
+			if err != nil {
+				log.Printf("Warning: spellcheck %s: %v", path, err)
+				break
+			}
+			findings = append(findings, found...)
+		}
+	}
+	if s.Vale != "" {
+		found, err := spell.Vale(ctx, binary("VALE_BIN", "vale"), s.Vale, words, paths)
+		if err != nil {
+			log.Printf("Warning: style check of %s: %v", res.OutputPath, err)
+		}
+		findings = append(findings, found...)
+	}
+
+	for _, f := range findings {
+		res.Warnings = append(res.Warnings, f.String())
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kuzik/markdown-pdf-action/internal/webhook"
+)
+
+// summaryConfig posts a run summary to a chat webhook
+type summaryConfig struct {
+	url       string // Slack or Teams incoming webhook
+	format    string // auto | slack | teams
+	on        string // always | failure
+	dashboard string // link to the dashboard or docs portal
+}
+
+// validate checks the summary settings before the run starts
+func (c summaryConfig) validate() error {
+	switch c.format {
+	case "", "auto", "slack", "teams":
+	default:
+		return fmt.Errorf("unknown format %q (use auto, slack, or teams)", c.format)
+	}
+	switch c.on {
+	case "", "always", "failure":
+	default:
+		return fmt.Errorf("unknown notify-on %q (use always or failure)", c.on)
+	}
+	return nil
+}
+
+// maxSummaryLinks bounds how many published files a summary lists
+const maxSummaryLinks = 10
+
+// maxSummaryError bounds the failure details shown per job
+const maxSummaryError = 500
+
+// runSummary counts job outcomes and collects the lines of a summary
+type runSummary struct {
+	title     string
+	failed    bool
+	jobs      []string      // one line per job
+	failures  []string      // job name and error of failed jobs
+	links     []summaryLink // published files
+	moreLinks int
+	runURL    string
+	dashboard string
+}
+
+// summaryLink is a published file listed in a summary
+type summaryLink struct {
+	name, url string
+}
+
+// summarize builds the summary of a finished run
+func summarize(jobs []job, outcomes []jobOutcome, elapsed time.Duration, dashboard string) runSummary {
+	counts := make(map[jobState]int)
+	s := runSummary{runURL: runURL(), dashboard: dashboard}
+
+	for i, j := range jobs {
+		o := outcomes[i]
+		state := o.state
+		if state == jobPending || state == jobRunning {
+			state = jobSkipped
+		}
+		counts[state]++
+
+		line := summaryIcons[state] + " " + j.jobName()
+		switch n := len(artifacts.Job(j.jobName())); n {
+		case 0:
+		case 1:
+			line += " (1 file)"
+		default:
+			line += fmt.Sprintf(" (%d files)", n)
+		}
+		s.jobs = append(s.jobs, line)
+
+		if state == jobFailed && o.err != nil {
+			msg := o.err.Error()
+			if len(msg) > maxSummaryError {
+				msg = msg[:maxSummaryError] + "…"
+			}
+			s.failures = append(s.failures, j.jobName()+": "+msg)
+		}
+	}
+
+	for _, a := range artifacts.Manifest().Artifacts {
+		if a.URL == "" {
+			continue
+		}
+		if len(s.links) == maxSummaryLinks {
+			s.moreLinks++
+			continue
+		}
+		s.links = append(s.links, summaryLink{name: a.Output, url: a.URL})
+	}
+
+	s.failed = counts[jobFailed] > 0 || counts[jobSkipped] > 0
+	status := "succeeded"
+	if s.failed {
+		status = "failed"
+	}
+	s.title = fmt.Sprintf("Docs build %s: %d succeeded, %d failed, %d skipped in %s",
+		status, counts[jobSucceeded], counts[jobFailed], counts[jobSkipped], elapsed.Round(time.Second))
+	return s
+}
+
+// summaryIcons marks job states in summaries
+var summaryIcons = map[jobState]string{
+	jobSucceeded: "✅",
+	jobFailed:    "❌",
+	jobSkipped:   "⏭️",
+}
+
+// postSummary sends the run summary to the configured webhook. Delivery
+// failures are logged and never fail the run.
+func postSummary(cfg summaryConfig, jobs []job, outcomes []jobOutcome, elapsed time.Duration) {
+	if cfg.url == "" {
+		return
+	}
+
+	s := summarize(jobs, outcomes, elapsed, cfg.dashboard)
+	if cfg.on == "failure" && !s.failed {
+		return
+	}
+
+	var payload any
+	if summaryFormat(cfg) == "teams" {
+		payload = s.teams()
+	} else {
+		payload = s.slack()
+	}
+
+	if err := webhook.Post(context.Background(), cfg.url, payload); err != nil {
+		log.Printf("Warning: run summary not sent: %v", err)
+		return
+	}
+	log.Printf("Run summary sent")
+}
+
+// summaryFormat picks the payload format, detecting it from the webhook host when auto
+func summaryFormat(cfg summaryConfig) string {
+	if cfg.format != "" && cfg.format != "auto" {
+		return cfg.format
+	}
+	u, err := url.Parse(cfg.url)
+	if err != nil {
+		return "slack"
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, suffix := range []string{".office.com", ".office365.com", ".logic.azure.com", ".powerplatform.com"} {
+		if strings.HasSuffix(host, suffix) {
+			return "teams"
+		}
+	}
+	return "slack"
+}
+
+// slack returns the summary as a Slack incoming webhook message
+func (s runSummary) slack() map[string]any {
+	var b strings.Builder
+	b.WriteString("*" + slackEscape(s.title) + "*\n")
+	for _, line := range s.jobs {
+		b.WriteString(slackEscape(line) + "\n")
+	}
+	if len(s.failures) > 0 {
+		b.WriteString("\n*Failures*\n")
+		for _, f := range s.failures {
+			b.WriteString("```" + strings.ReplaceAll(slackEscape(f), "```", "'''") + "```\n")
+		}
+	}
+	if len(s.links) > 0 {
+		b.WriteString("\n*Published*\n")
+		for _, l := range s.links {
+			b.WriteString("• <" + l.url + "|" + slackEscape(l.name) + ">\n")
+		}
+		if s.moreLinks > 0 {
+			fmt.Fprintf(&b, "…and %d more\n", s.moreLinks)
+		}
+	}
+
+	var links []string
+	if s.dashboard != "" {
+		links = append(links, "<"+s.dashboard+"|Dashboard>")
+	}
+	if s.runURL != "" {
+		links = append(links, "<"+s.runURL+"|Workflow run>")
+	}
+	if len(links) > 0 {
+		b.WriteString("\n" + strings.Join(links, " · "))
+	}
+
+	return map[string]any{"text": strings.TrimSpace(b.String())}
+}
+
+// slackEscape escapes the characters Slack treats as markup
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// teams returns the summary as an Adaptive Card message for a Teams webhook
+func (s runSummary) teams() map[string]any {
+	text := func(value string, extra map[string]any) map[string]any {
+		block := map[string]any{"type": "TextBlock", "text": value, "wrap": true}
+		for k, v := range extra {
+			block[k] = v
+		}
+		return block
+	}
+
+	color := "Good"
+	if s.failed {
+		color = "Attention"
+	}
+	body := []any{text(s.title, map[string]any{"weight": "Bolder", "size": "Medium", "color": color})}
+	for _, line := range s.jobs {
+		body = append(body, text(line, map[string]any{"spacing": "None"}))
+	}
+	if len(s.failures) > 0 {
+		body = append(body, text("Failures", map[string]any{"weight": "Bolder", "separator": true}))
+		for _, f := range s.failures {
+			body = append(body, text(f, map[string]any{"fontType": "Monospace", "size": "Small"}))
+		}
+	}
+	if len(s.links) > 0 {
+		body = append(body, text("Published", map[string]any{"weight": "Bolder", "separator": true}))
+		for _, l := range s.links {
+			body = append(body, text("["+l.name+"]("+l.url+")", map[string]any{"spacing": "None"}))
+		}
+		if s.moreLinks > 0 {
+			body = append(body, text(fmt.Sprintf("…and %d more", s.moreLinks), map[string]any{"spacing": "None"}))
+		}
+	}
+
+	var actions []any
+	if s.dashboard != "" {
+		actions = append(actions, map[string]any{"type": "Action.OpenUrl", "title": "Dashboard", "url": s.dashboard})
+	}
+	if s.runURL != "" {
+		actions = append(actions, map[string]any{"type": "Action.OpenUrl", "title": "Workflow run", "url": s.runURL})
+	}
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if len(actions) > 0 {
+		card["actions"] = actions
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []any{map[string]any{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	}
+}
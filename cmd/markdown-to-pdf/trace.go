@@ -0,0 +1,27 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// trace logs how long each stage of every document takes; enabled by -v or --trace
+var trace bool
+
+// traceStage logs the duration of a stage of the document at outPath begun at start
+func traceStage(outPath, stage string, start time.Time) {
+	if trace {
+		log.Printf("Trace: %s: %s %s", outPath, stage, time.Since(start).Round(10*time.Microsecond))
+	}
+}
+
+// renderTrace returns the render trace hook for the document at outPath, or
+// nil when tracing is off
+func renderTrace(outPath string) func(string, time.Duration) {
+	if !trace {
+		return nil
+	}
+	return func(stage string, d time.Duration) {
+		log.Printf("Trace: %s: %s %s", outPath, stage, d.Round(10*time.Microsecond))
+	}
+}
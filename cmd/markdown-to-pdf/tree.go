@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/kuzik/markdown-pdf-action/internal/natsort"
+)
+
+// treeConfig appends a listing of a folder to the job's documents
+type treeConfig struct {
+	Path    string   `yaml:"path"`    // folder to list, resolved like source
+	Depth   int      `yaml:"depth"`   // levels below path to list; 0 lists everything
+	Exclude []string `yaml:"exclude"` // globs of files or folders to leave out, matched against the relative path or the name
+	Hidden  bool     `yaml:"hidden"`  // also list dot files and folders
+	Title   string   `yaml:"title"`   // section heading, "Directory tree" by default
+}
+
+// treeEntry is one line of the listing
+type treeEntry struct {
+	Prefix string // box-drawing guides of the parent levels and the branch
+	Name   string
+	Dir    bool
+	Target string // where a symlink points
+}
+
+// treeTemplate renders the directory tree section appended to a document
+var treeTemplate = template.Must(template.New("tree").Parse(`
+<section class="directory-tree" style="page-break-before: always">
+<style>
+.directory-tree pre { line-height: 1.35; }
+.directory-tree .tree-guide { color: #8c959f; }
+.directory-tree .tree-dir { font-weight: 600; color: #0969da; }
+.directory-tree .tree-link { color: #57606a; font-style: italic; }
+.directory-tree .tree-summary { color: #57606a; font-size: 0.9em; }
+</style>
+<h2>{{.Title}}</h2>
+<pre><code><span class="tree-dir">{{.Root}}</span>
+{{range .Entries}}<span class="tree-guide">{{.Prefix}}</span>{{if .Dir}}<span class="tree-dir">{{.Name}}/</span>{{else}}{{.Name}}{{end}}{{if .Target}} <span class="tree-link">-&gt; {{.Target}}</span>{{end}}
+{{end}}</code></pre>
+<p class="tree-summary">{{.Dirs}} {{if eq .Dirs 1}}directory{{else}}directories{{end}}, {{.Files}} {{if eq .Files 1}}file{{else}}files{{end}}</p>
+</section>
+`))
+
+// treeAppendix returns a section listing the configured folder like tree(1),
+// or "" when no folder is set
+func treeAppendix(t treeConfig, order string) (string, error) {
+	if t.Path == "" {
+		return "", nil
+	}
+	compare, err := natsort.Func(order)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(t.Path); err != nil {
+		return "", err
+	} else if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", t.Path)
+	}
+
+	abs, err := filepath.Abs(t.Path)
+	if err != nil {
+		return "", err
+	}
+	data := struct {
+		Title       string
+		Root        string
+		Entries     []treeEntry
+		Dirs, Files int
+	}{Title: t.Title, Root: filepath.Base(abs) + "/"}
+	if data.Title == "" {
+		data.Title = "Directory tree"
+	}
+
+	var walk func(dir, rel, prefix string, depth int) error
+	walk = func(dir, rel, prefix string, depth int) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		entries = slices.DeleteFunc(entries, func(e os.DirEntry) bool {
+			return !t.lists(e.Name(), filepath.ToSlash(filepath.Join(rel, e.Name())))
+		})
+		slices.SortFunc(entries, func(a, b os.DirEntry) int {
+			return compare(a.Name(), b.Name())
+		})
+
+		for i, e := range entries {
+			branch, guide := "├── ", "│   "
+			if i == len(entries)-1 {
+				branch, guide = "└── ", "    "
+			}
+			path := filepath.Join(dir, e.Name())
+			entry := treeEntry{Prefix: prefix + branch, Name: e.Name(), Dir: e.IsDir()}
+
+			// Symlinks are shown with their target and not followed
+			if e.Type()&os.ModeSymlink != 0 {
+				entry.Target, _ = os.Readlink(path)
+				if info, err := os.Stat(path); err == nil && info.IsDir() {
+					entry.Dir = true
+				}
+			}
+			data.Entries = append(data.Entries, entry)
+
+			if !entry.Dir {
+				data.Files++
+				continue
+			}
+			data.Dirs++
+			if entry.Target == "" && (t.Depth <= 0 || depth < t.Depth) {
+				if err := walk(path, filepath.Join(rel, e.Name()), prefix+guide, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(t.Path, "", "", 1); err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := treeTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// lists reports whether an entry is shown: dot files only with hidden, and
+// nothing matching an exclude glob
+func (t treeConfig) lists(name, rel string) bool {
+	if strings.HasPrefix(name, ".") && !t.Hidden {
+		return false
+	}
+	for _, pattern := range t.Exclude {
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			return false
+		}
+		if ok, _ := doublestar.Match(pattern, name); ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "fmt"
+
+// setUmask fails on platforms without a umask
+func setUmask(s string) error {
+	return fmt.Errorf("umask is not supported on this platform")
+}
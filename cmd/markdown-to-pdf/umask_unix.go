@@ -0,0 +1,19 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"syscall"
+)
+
+// setUmask sets the process umask from an octal string such as 002
+func setUmask(s string) error {
+	n, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || n > 0o777 {
+		return fmt.Errorf("invalid umask %q (use octal, e.g. 002)", s)
+	}
+	syscall.Umask(int(n))
+	return nil
+}
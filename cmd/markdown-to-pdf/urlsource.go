@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sourceCache keeps downloaded URL sources between runs; set by --source-cache.
+// Without it they go to a temporary directory removed when the run ends.
+var sourceCache string
+
+// maxSourceBytes bounds the size of a downloaded markdown source
+const maxSourceBytes = 32 << 20
+
+var sourceClient = &http.Client{Timeout: 30 * time.Second}
+
+// urlSource is one markdown document to download, optionally pinned to the
+// SHA-256 of its content
+type urlSource struct {
+	url    string
+	sha256 string
+}
+
+// cachedSource describes a download, stored next to it as <file>.cache.json
+type cachedSource struct {
+	URL          string `json:"url"`
+	SHA256       string `json:"sha256"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// fetches downloads each URL once per run, however many jobs use it
+var fetches = struct {
+	sync.Mutex
+	byURL map[string]*fetch
+	temp  string
+}{byURL: make(map[string]*fetch)}
+
+type fetch struct {
+	once sync.Once
+	path string
+	err  error
+}
+
+// parseURLSources splits a source made of http(s) URLs separated by spaces or
+// newlines, each optionally pinned with #sha256=<hex>. ok is false when the
+// source is a path or glob.
+func parseURLSources(source string) (sources []urlSource, ok bool, err error) {
+	fields := strings.Fields(source)
+	urls := 0
+	for _, f := range fields {
+		if strings.HasPrefix(f, "http://") || strings.HasPrefix(f, "https://") {
+			urls++
+		}
+	}
+	switch urls {
+	case 0:
+		return nil, false, nil
+	case len(fields):
+	default:
+		return nil, true, fmt.Errorf("source mixes URLs and paths")
+	}
+
+	for _, f := range fields {
+
+		src := urlSource{url: f}
+		if base, pin, found := strings.Cut(f, "#sha256="); found {
+			if _, err := hex.DecodeString(pin); err != nil || len(pin) != sha256.Size*2 {
+				return nil, true, fmt.Errorf("%s: sha256 must be 64 hex digits", f)
+			}
+			src = urlSource{url: base, sha256: strings.ToLower(pin)}
+		}
+		if _, err := url.Parse(src.url); err != nil {
+			return nil, true, fmt.Errorf("parse %s: %w", src.url, err)
+		}
+		sources = append(sources, src)
+	}
+	return sources, true, nil
+}
+
+// validateURLSources checks the URL sources of a job, if it has any
+func (j job) validateURLSources() error {
+	_, ok, err := parseURLSources(j.Source)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return nil
+	case j.Repo != "" || j.SourceRoot != "":
+		return fmt.Errorf("URL sources cannot be combined with repo or source_root")
+	case j.Type != "single" && j.Type != "subfolders" && j.Type != "combine":
+		return fmt.Errorf("URL sources need a single, subfolders, or combine job")
+	}
+	return nil
+}
+
+// withURLSources downloads the job's URL sources, if it has any, so they are
+// rendered in the order listed
+func (j job) withURLSources(ctx context.Context) (job, error) {
+	sources, ok, err := parseURLSources(j.Source)
+	if err != nil || !ok {
+		return j, err
+	}
+
+	j.fetched = nil
+	for _, src := range sources {
+		local, err := fetchSource(ctx, src)
+		if err != nil {
+			return j, fmt.Errorf("fetch %s: %w", src.url, err)
+		}
+		j.fetched = append(j.fetched, local)
+	}
+	return j, nil
+}
+
+// fetchSource returns the local copy of src, downloading it at most once per run
+func fetchSource(ctx context.Context, src urlSource) (string, error) {
+	fetches.Lock()
+	f, ok := fetches.byURL[src.url]
+	if !ok {
+		f = &fetch{}
+		fetches.byURL[src.url] = f
+	}
+	fetches.Unlock()
+
+	f.once.Do(func() {
+		f.path, f.err = download(ctx, src.url, src.sha256)
+	})
+	if f.err != nil {
+		return "", f.err
+	}
+
+	if src.sha256 != "" {
+		meta, _ := readCachedSource(f.path)
+		if meta.SHA256 != src.sha256 {
+			return "", fmt.Errorf("checksum mismatch: pinned sha256 %s, downloaded %s", src.sha256, meta.SHA256)
+		}
+	}
+	return f.path, nil
+}
+
+// download saves rawURL under the cache directory, mirroring its host and path.
+// A cached copy matching the pinned checksum is used as is; others are
+// revalidated with their ETag or Last-Modified date, and reused when the server
+// cannot be reached.
+func download(ctx context.Context, rawURL, pinned string) (string, error) {
+	dir, err := sourceCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	name := path.Clean("/" + u.Path)
+	if strings.HasSuffix(u.Path, "/") || name == "/" {
+		name = path.Join(name, "index.md")
+	}
+	local := filepath.Join(dir, strings.ReplaceAll(u.Host, ":", "_"), filepath.FromSlash(name))
+
+	cached, err := readCachedSource(local)
+	if _, statErr := os.Stat(local); err != nil || statErr != nil || cached.URL != rawURL {
+		cached = cachedSource{}
+	}
+	if pinned != "" && cached.SHA256 == pinned {
+		return local, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	resp, err := sourceClient.Do(req)
+	if err != nil {
+		if cached.SHA256 != "" {
+			log.Printf("Warning: %v; using the cached copy of %s", err, rawURL)
+			return local, nil
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached.SHA256 != "":
+		return local, nil
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+	if len(content) > maxSourceBytes {
+		return "", fmt.Errorf("document is larger than %d MB", maxSourceBytes>>20)
+	}
+
+	sum := sha256.Sum256(content)
+	meta := cachedSource{
+		URL:          rawURL,
+		SHA256:       hex.EncodeToString(sum[:]),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+		return "", fmt.Errorf("create cache directory: %w", err)
+	}
+	if err := os.WriteFile(local, absoluteLinks(content, u), 0o644); err != nil {
+		return "", fmt.Errorf("write %s: %w", local, err)
+	}
+	if err := writeCachedSource(local, meta); err != nil {
+		return "", err
+	}
+	return local, nil
+}
+
+// sourceCacheDir returns --source-cache, or a temporary directory for this run
+func sourceCacheDir() (string, error) {
+	if sourceCache != "" {
+		return sourceCache, nil
+	}
+
+	fetches.Lock()
+	defer fetches.Unlock()
+	if fetches.temp == "" {
+		dir, err := os.MkdirTemp("", "markdown-to-pdf-sources-*")
+		if err != nil {
+			return "", fmt.Errorf("create source cache: %w", err)
+		}
+		fetches.temp = dir
+	}
+	return fetches.temp, nil
+}
+
+// removeFetched deletes the temporary source cache, if one was created
+func removeFetched() {
+	fetches.Lock()
+	defer fetches.Unlock()
+	if fetches.temp != "" {
+		os.RemoveAll(fetches.temp)
+	}
+}
+
+func readCachedSource(local string) (cachedSource, error) {
+	var meta cachedSource
+	data, err := os.ReadFile(local + ".cache.json")
+	if err != nil {
+		return meta, err
+	}
+	err = json.Unmarshal(data, &meta)
+	return meta, err
+}
+
+func writeCachedSource(local string, meta cachedSource) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(local+".cache.json", data, 0o644); err != nil {
+		return fmt.Errorf("write cache metadata: %w", err)
+	}
+	return nil
+}
+
+// relativeTarget matches the targets of markdown links and images, and of
+// HTML src and href attributes
+var relativeTarget = regexp.MustCompile(`(\]\(\s*<?|\b(?:src|href)\s*=\s*["'])([^)\s"'>]+)`)
+
+// absoluteLinks resolves relative link and image targets in downloaded markdown
+// against the document's URL, so they keep working from the local copy
+func absoluteLinks(content []byte, base *url.URL) []byte {
+	return relativeTarget.ReplaceAllFunc(content, func(m []byte) []byte {
+		parts := relativeTarget.FindSubmatch(m)
+		target := string(parts[2])
+		if strings.HasPrefix(target, "#") || strings.Contains(target, ":") {
+			return m
+		}
+		ref, err := url.Parse(target)
+		if err != nil {
+			return m
+		}
+		return []byte(string(parts[1]) + base.ResolveReference(ref).String())
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/pkg/render"
+)
+
+// placeholderRegex matches {name} placeholders, which are left as is when unknown
+var placeholderRegex = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// validateTemplates checks the user templates of each job without rendering:
+// the template_dir wrapper against sample document data, and the header,
+// footer, and qr_code for placeholders nothing replaces. Jobs must have their
+// git placeholders expanded. It returns the number of jobs with problems.
+func validateTemplates(jobs []job) int {
+	invalid := 0
+	for _, j := range jobs {
+		var problems, notes []string
+
+		if j.TemplateDir != "" {
+			report, err := render.CheckTemplate(j.TemplateDir)
+			switch {
+			case err != nil:
+				problems = append(problems, fmt.Sprintf("template_dir: %v", err))
+			case !report.OK():
+				problems = append(problems, "template_dir: "+report.String())
+			case len(report.Unused) > 0:
+				notes = append(notes, "template_dir: "+report.String())
+			}
+		}
+
+		for _, t := range []struct {
+			option, value string
+			known         []string
+		}{
+			{"header", j.Header, nil},
+			{"footer", j.Footer, nil},
+			{"qr_code", j.QRCode, []string{"{output}", "{name}"}},
+		} {
+			if unknown := unknownPlaceholders(t.value, t.known); len(unknown) > 0 {
+				problems = append(problems, fmt.Sprintf("%s: undefined placeholders: %s", t.option, strings.Join(unknown, ", ")))
+			}
+		}
+
+		switch {
+		case len(problems) > 0:
+			log.Printf("INVALID: %s: %s", j.jobName(), strings.Join(problems, "; "))
+			invalid++
+		case len(notes) > 0:
+			log.Printf("OK: %s (%s)", j.jobName(), strings.Join(notes, "; "))
+		default:
+			log.Printf("OK: %s", j.jobName())
+		}
+	}
+
+	log.Printf("Validated %d jobs, %d invalid", len(jobs), invalid)
+	return invalid
+}
+
+// unknownPlaceholders returns the placeholders in s other than known ones
+func unknownPlaceholders(s string, known []string) []string {
+	var unknown []string
+	for _, p := range placeholderRegex.FindAllString(s, -1) {
+		if !slices.Contains(known, p) && !slices.Contains(unknown, p) {
+			unknown = append(unknown, p)
+		}
+	}
+	return unknown
+}
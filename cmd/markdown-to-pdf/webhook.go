@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/kuzik/markdown-pdf-action/internal/manifest"
+	"github.com/kuzik/markdown-pdf-action/internal/webhook"
+)
+
+// jobCompletion is the payload POSTed to a job's webhook when it finishes
+type jobCompletion struct {
+	Job       string              `json:"job"`
+	Status    string              `json:"status"` // succeeded | failed | skipped
+	Error     string              `json:"error,omitempty"`
+	Artifacts []manifest.Artifact `json:"artifacts"`
+	RunURL    string              `json:"run_url,omitempty"` // workflow run holding the uploaded artifacts
+}
+
+// jobStatusNames maps finished pipeline states to webhook status values
+var jobStatusNames = map[jobState]string{
+	jobSucceeded: "succeeded",
+	jobFailed:    "failed",
+	jobSkipped:   "skipped",
+}
+
+// notifyJob POSTs the job's completion payload to its webhook, if one is configured.
+// Delivery failures are logged and never fail the job.
+func notifyJob(ctx context.Context, j job, state jobState, jobErr error) {
+	if j.Webhook == "" {
+		return
+	}
+
+	payload := jobCompletion{
+		Job:       j.jobName(),
+		Status:    jobStatusNames[state],
+		Artifacts: artifacts.Job(j.jobName()),
+		RunURL:    runURL(),
+	}
+	if jobErr != nil {
+		payload.Error = jobErr.Error()
+	}
+	if payload.Artifacts == nil {
+		payload.Artifacts = []manifest.Artifact{}
+	}
+
+	// Deliver even when the run is being canceled so receivers hear about it
+	if err := webhook.Post(context.WithoutCancel(ctx), j.Webhook, payload); err != nil {
+		log.Printf("Warning: webhook for job %s failed: %v", j.jobName(), err)
+	}
+}
+
+// runURL links to the GitHub Actions run, or returns "" outside Actions
+func runURL() string {
+	server, repo, id := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if server == "" || repo == "" || id == "" {
+		return ""
+	}
+	return server + "/" + repo + "/actions/runs/" + id
+}
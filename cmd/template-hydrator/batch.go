@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// batchResult is the outcome of rendering one record
+type batchResult struct {
+	Record     record
+	OutputPath string
+	Err        error
+}
+
+// renderBatch renders records with up to concurrency documents in flight.
+// Results are returned in record order; records not started before ctx is
+// cancelled carry the context error.
+func renderBatch(ctx context.Context, r *renderer, records []record, concurrency int) []batchResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]batchResult, len(records))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(records)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				rec := records[i]
+				results[i] = batchResult{Record: rec}
+
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+
+				outputPath, err := r.render(ctx, rec)
+				if err != nil {
+					log.Printf("Failed to render %s: %v", rec.Name, err)
+					results[i].Err = err
+					continue
+				}
+
+				results[i].OutputPath = outputPath
+				log.Printf("Rendered: %s", outputPath)
+
+				if r.mail != nil {
+					if err := r.mail.send(rec, outputPath); err != nil {
+						log.Printf("Failed to email %s: %v", rec.Name, err)
+						results[i].Err = fmt.Errorf("email: %w", err)
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range records {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// reportBatch logs a summary of the batch, listing every failed record, and
+// returns the number of records that were not rendered
+func reportBatch(results []batchResult) int {
+	var failed []batchResult
+	skipped := 0
+
+	for _, res := range results {
+		switch {
+		case res.Err == nil:
+		case errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, context.DeadlineExceeded):
+			skipped++
+		default:
+			failed = append(failed, res)
+		}
+	}
+
+	log.Printf("Rendered %d of %d documents", len(results)-len(failed)-skipped, len(results))
+
+	if skipped > 0 {
+		log.Printf("Interrupted, %d documents skipped", skipped)
+	}
+
+	if len(failed) > 0 {
+		log.Printf("%d documents failed:", len(failed))
+		for _, res := range failed {
+			log.Printf("  %s: %v", res.Record.Name, res.Err)
+		}
+	}
+
+	return len(failed) + skipped
+}
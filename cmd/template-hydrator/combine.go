@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+)
+
+// indexTemplate lists each document in a combined PDF with its starting page
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"add1": func(i int) int { return i + 1 },
+}).Parse(`<h1>Index</h1>
+<table>
+<thead><tr><th>#</th><th>Document</th><th>Page</th></tr></thead>
+<tbody>
+{{range $i, $e := .}}<tr><td>{{add1 $i}}</td><td>{{$e.Title}}</td><td>{{$e.Page}}</td></tr>
+{{end}}</tbody>
+</table>`))
+
+// indexEntry is one row of the combined PDF's index page
+type indexEntry struct {
+	Title string
+	Page  int
+}
+
+// combineBatch merges the rendered documents, in record order, into one PDF,
+// optionally preceded by a generated index page
+func combineBatch(ctx context.Context, backend pdf.Backend, results []batchResult, outputPath string, withIndex bool) error {
+	var (
+		inputs  []string
+		entries []indexEntry
+	)
+
+	page := 1
+	for _, res := range results {
+		// Documents that rendered but failed to email are still combined
+		if res.OutputPath == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(res.OutputPath)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", res.OutputPath, err)
+		}
+
+		inputs = append(inputs, res.OutputPath)
+		entries = append(entries, indexEntry{Title: res.Record.title(), Page: page})
+		page += max(pdf.PageCount(data), 1)
+	}
+
+	if len(inputs) == 0 {
+		return fmt.Errorf("no documents were rendered")
+	}
+
+	if withIndex {
+		indexPath, err := renderIndex(ctx, backend, entries)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(indexPath)
+
+		inputs = append([]string{indexPath}, inputs...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	return pdf.Merge(outputPath, inputs)
+}
+
+// renderIndex renders the index page to a temporary PDF and returns its path.
+// Page numbers are offset by the length of the index itself.
+func renderIndex(ctx context.Context, backend pdf.Backend, entries []indexEntry) (string, error) {
+	opts := pdf.DefaultOptions()
+	opts.Backend = backend
+
+	var (
+		pdfBuf []byte
+		offset = 1
+	)
+
+	// Re-render if the index turns out longer than assumed, since that shifts every page number
+	for {
+		shifted := make([]indexEntry, len(entries))
+		for i, e := range entries {
+			shifted[i] = indexEntry{Title: e.Title, Page: e.Page + offset}
+		}
+
+		var body strings.Builder
+		if err := indexTemplate.Execute(&body, shifted); err != nil {
+			return "", fmt.Errorf("execute index template: %w", err)
+		}
+
+		fullHTML, err := wrapHTML(body.String(), "Index")
+		if err != nil {
+			return "", fmt.Errorf("wrap HTML: %w", err)
+		}
+
+		pdfBuf, err = pdf.Generate(ctx, fullHTML, opts)
+		if err != nil {
+			return "", fmt.Errorf("generate index: %w", err)
+		}
+
+		pages := max(pdf.PageCount(pdfBuf), 1)
+		if pages <= offset {
+			break
+		}
+		offset = pages
+	}
+
+	tmp, err := os.CreateTemp("", "hydrator-index-*.pdf")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	defer tmp.Close()
+
+	if _, err := tmp.Write(pdfBuf); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("write index: %w", err)
+	}
+
+	return tmp.Name(), nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// record is one document to render: its output name and template data
+type record struct {
+	Name string
+	Data any
+}
+
+// title returns the record's Title field, falling back to its name
+func (r record) title() string {
+	if dataMap, ok := r.Data.(map[string]any); ok {
+		if t, ok := dataMap["Title"].(string); ok && t != "" {
+			return t
+		}
+	}
+	return r.Name
+}
+
+// loadRecords reads a data file and returns its records in a stable order.
+//
+// Supported shapes:
+//   - a JSON object keyed by output name
+//   - a JSON array of records
+//   - NDJSON (.ndjson or .jsonl), one record per line
+//   - CSV (.csv) or Excel (.xlsx) with a header row naming the fields
+//
+// Records from arrays, NDJSON, and spreadsheets are named with nameTmpl, or numbered when it is nil.
+func loadRecords(path, sheet string, nameTmpl *template.Template) ([]record, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err := readCSV(path)
+		if err != nil {
+			return nil, err
+		}
+		return nameRecords(rowsToItems(rows), nameTmpl)
+	case ".xlsx":
+		rows, err := readXLSX(path, sheet)
+		if err != nil {
+			return nil, err
+		}
+		return nameRecords(rowsToItems(rows), nameTmpl)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read data file: %w", err)
+	}
+
+	var items []any
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		items, err = parseNDJSON(content)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		trimmed := bytes.TrimSpace(content)
+		if len(trimmed) > 0 && trimmed[0] == '{' {
+			return parseJSONObject(trimmed)
+		}
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("parse JSON data: %w", err)
+		}
+	}
+
+	return nameRecords(items, nameTmpl)
+}
+
+// parseJSONObject parses a top-level object keyed by output name
+func parseJSONObject(content []byte) ([]record, error) {
+	// Values can be any structure (nested objects, arrays, etc.)
+	var dataMap map[string]any
+	if err := json.Unmarshal(content, &dataMap); err != nil {
+		return nil, fmt.Errorf("parse JSON data: %w", err)
+	}
+
+	names := make([]string, 0, len(dataMap))
+	for name := range dataMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	records := make([]record, 0, len(names))
+	for _, name := range names {
+		records = append(records, record{Name: name, Data: dataMap[name]})
+	}
+	return records, nil
+}
+
+// parseNDJSON parses one JSON value per non-empty line
+func parseNDJSON(content []byte) ([]any, error) {
+	var items []any
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var item any
+		if err := json.Unmarshal(text, &item); err != nil {
+			return nil, fmt.Errorf("parse NDJSON line %d: %w", line, err)
+		}
+		items = append(items, item)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read NDJSON: %w", err)
+	}
+	return items, nil
+}
+
+// nameRecords names each item with nameTmpl (or its 1-based index) and de-duplicates names
+func nameRecords(items []any, nameTmpl *template.Template) ([]record, error) {
+	records := make([]record, 0, len(items))
+	taken := make(map[string]bool)
+
+	for i, item := range items {
+		name := fmt.Sprintf("%04d", i+1)
+
+		if nameTmpl != nil {
+			var buf strings.Builder
+			if err := nameTmpl.Execute(&buf, item); err != nil {
+				return nil, fmt.Errorf("record %d: execute name template: %w", i+1, err)
+			}
+			name = sanitizeName(buf.String())
+			if name == "" {
+				return nil, fmt.Errorf("record %d: name template produced an empty name", i+1)
+			}
+		}
+
+		// Keep every record by suffixing repeated names, skipping suffixes
+		// another record already has as its own name
+		for base, n := name, 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		taken[name] = true
+
+		records = append(records, record{Name: name, Data: item})
+	}
+
+	return records, nil
+}
+
+// sanitizeName makes a generated name safe to use as a file name
+func sanitizeName(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '-'
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, name)
+	return strings.Trim(name, ". ")
+}
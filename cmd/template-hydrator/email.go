@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/kuzik/markdown-pdf-action/internal/mail"
+)
+
+// mailer emails each rendered document to the batch recipients and the
+// addresses in its record
+type mailer struct {
+	server  mail.Server
+	to      []string           // recipients of every document
+	field   string             // record field holding the document's own recipients
+	subject *template.Template // executed with the record data
+	body    *template.Template
+}
+
+// newMailer returns nil when no recipients are configured
+func newMailer(to, field, subject, body string, funcs template.FuncMap) (*mailer, error) {
+	if to == "" && field == "" {
+		return nil, nil
+	}
+
+	addrs, err := mail.ParseAddresses(to)
+	if err != nil {
+		return nil, err
+	}
+	server, err := mail.ServerFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &mailer{server: server, to: addrs, field: field}
+	if subject == "" {
+		subject = "{{ .Title }}"
+	}
+	if m.subject, err = template.New("subject").Funcs(funcs).Parse(subject); err != nil {
+		return nil, fmt.Errorf("parse subject template: %w", err)
+	}
+	if m.body, err = template.New("body").Funcs(funcs).Parse(body); err != nil {
+		return nil, fmt.Errorf("parse body template: %w", err)
+	}
+	return m, nil
+}
+
+// recipients returns the batch recipients plus the addresses in the record's field,
+// which may hold a comma-separated string or a list
+func (m *mailer) recipients(rec record) ([]string, error) {
+	addrs := append([]string{}, m.to...)
+	if m.field == "" {
+		return addrs, nil
+	}
+
+	fields, _ := rec.Data.(map[string]any)
+	var list []string
+	switch v := fields[m.field].(type) {
+	case nil:
+	case []any:
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+	default:
+		list = append(list, fmt.Sprint(v))
+	}
+
+	own, err := mail.ParseAddresses(strings.Join(list, ","))
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", m.field, err)
+	}
+	return append(addrs, own...), nil
+}
+
+// send emails the rendered PDF of a record
+func (m *mailer) send(rec record, outputPath string) error {
+	to, err := m.recipients(rec)
+	if err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		log.Printf("Warning: %s has no recipients, not emailed", rec.Name)
+		return nil
+	}
+
+	// Expose the record title to the subject even when the data has no Title field
+	data := rec.Data
+	if fields, ok := data.(map[string]any); ok {
+		if _, ok := fields["Title"]; !ok {
+			data = withField(fields, "Title", rec.title())
+		}
+	}
+
+	var subject, body strings.Builder
+	if err := m.subject.Execute(&subject, data); err != nil {
+		return fmt.Errorf("execute subject template: %w", err)
+	}
+	if err := m.body.Execute(&body, data); err != nil {
+		return fmt.Errorf("execute body template: %w", err)
+	}
+
+	pdfData, err := os.ReadFile(outputPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", outputPath, err)
+	}
+
+	msg := mail.Message{
+		To:          to,
+		Subject:     strings.TrimSpace(subject.String()),
+		Body:        body.String(),
+		Attachments: []mail.Attachment{{Name: filepath.Base(outputPath), ContentType: "application/pdf", Data: pdfData}},
+	}
+	if err := m.server.Send(msg); err != nil {
+		return err
+	}
+	log.Printf("Emailed: %s to %s", outputPath, strings.Join(to, ", "))
+	return nil
+}
+
+// withField returns a copy of fields with key set to value
+func withField(fields map[string]any, key string, value any) map[string]any {
+	out := make(map[string]any, len(fields)+1)
+	for k, v := range fields {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
@@ -1,20 +1,24 @@
 package main
 
 import (
+	"context"
 	"embed"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"html/template"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	texttemplate "text/template"
+	"time"
 
-	"github.com/kuzik/pandoc-latex-docker/internal/images"
-	"github.com/kuzik/pandoc-latex-docker/internal/markdown"
-	"github.com/kuzik/pandoc-latex-docker/internal/pdf"
-	"github.com/kuzik/pandoc-latex-docker/internal/templates"
+	"github.com/kuzik/markdown-pdf-action/internal/images"
+	"github.com/kuzik/markdown-pdf-action/internal/markdown"
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+	"github.com/kuzik/markdown-pdf-action/internal/templates"
 )
 
 //go:embed template.html
@@ -36,20 +40,56 @@ func init() {
 }
 
 func main() {
+	// "template-hydrator validate-templates --template ... --data ..." is a dry
+	// run that needs no output directory
+	validateOnly := len(os.Args) > 1 && os.Args[1] == "validate-templates"
+	if validateOnly {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	var (
 		templatePath string
 		dataPath     string
 		outputDir    string
 		imagesDir    string
+		nameTemplate string
+		sheet        string
+		funcsPath    string
+		concurrency  int
+		combinePath  string
+		combineIndex bool
+		dryRun       bool
+		strict       bool
+		emailTo      string
+		emailField   string
+		emailSubject string
+		emailBody    string
 	)
 
 	flag.StringVar(&templatePath, "template", "", "Path to the .html or .md template file")
-	flag.StringVar(&dataPath, "data", "", "Path to the .json data file")
+	flag.StringVar(&dataPath, "data", "", "Path to the data file: a JSON object keyed by name, a JSON array, NDJSON, CSV, or Excel (.xlsx)")
 	flag.StringVar(&outputDir, "output", "", "Path to the directory where PDFs will be saved")
 	flag.StringVar(&imagesDir, "images", "", "Base path for resolving image paths (defaults to template directory)")
+	flag.StringVar(&nameTemplate, "name-template", "", "Output name template for array, NDJSON, and spreadsheet records, e.g. {{.InvoiceNumber}} (defaults to the record number)")
+	flag.StringVar(&sheet, "sheet", "", "Worksheet to read from an .xlsx data file (defaults to the first sheet)")
+	flag.StringVar(&funcsPath, "funcs", "", "YAML file defining extra template functions as name: template snippet")
+	flag.IntVar(&concurrency, "concurrency", 1, "Number of documents rendered in parallel in a shared Chrome instance")
+	flag.StringVar(&combinePath, "combine", "", "Also merge all rendered documents into this single PDF")
+	flag.BoolVar(&combineIndex, "combine-index", false, "Start the combined PDF with an index page listing each document")
+	flag.BoolVar(&dryRun, "dry-run", false, "Validate every record against the template and report missing or unused fields without generating PDFs")
+	flag.BoolVar(&strict, "strict", false, "Fail a document when the template references a field its record lacks, instead of rendering it empty")
+	flag.StringVar(&emailTo, "email-to", "", "Email every rendered PDF to these comma-separated addresses (SMTP_* environment variables configure the server)")
+	flag.StringVar(&emailField, "email-field", "", "Record field holding the addresses each document is emailed to, e.g. Email")
+	flag.StringVar(&emailSubject, "email-subject", "", "Email subject template, e.g. Invoice {{.InvoiceNumber}} (defaults to the document title)")
+	flag.StringVar(&emailBody, "email-body", "", "Email body template")
 	flag.Parse()
 
-	if templatePath == "" || dataPath == "" || outputDir == "" {
+	if validateOnly {
+		dryRun = true
+		if templatePath == "" || dataPath == "" {
+			log.Fatal("--template and --data must be provided")
+		}
+	} else if templatePath == "" || dataPath == "" || outputDir == "" {
 		log.Fatal("--template, --data, and --output must all be provided")
 	}
 
@@ -65,8 +105,13 @@ func main() {
 		log.Fatalf("Failed to read template: %v", err)
 	}
 
+	funcs, err := templateFuncs(funcsPath)
+	if err != nil {
+		log.Fatalf("Failed to load template functions: %v", err)
+	}
+
 	// Parse template
-	tmpl, err := template.New("document").Parse(string(tmplContent))
+	tmpl, err := template.New("document").Funcs(funcs).Parse(string(tmplContent))
 	if err != nil {
 		log.Fatalf("Failed to parse template: %v", err)
 	}
@@ -74,16 +119,50 @@ func main() {
 	// Determine if template is markdown
 	isMarkdown := strings.HasSuffix(strings.ToLower(templatePath), ".md")
 
-	// Load JSON data
-	dataContent, err := os.ReadFile(dataPath)
+	// Parse the output name template
+	var nameTmpl *texttemplate.Template
+	if nameTemplate != "" {
+		nameTmpl, err = texttemplate.New("name").Funcs(texttemplate.FuncMap(funcs)).Option("missingkey=error").Parse(nameTemplate)
+		if err != nil {
+			log.Fatalf("Failed to parse name template: %v", err)
+		}
+	}
+
+	// Load data records
+	records, err := loadRecords(dataPath, sheet, nameTmpl)
 	if err != nil {
-		log.Fatalf("Failed to read data file: %v", err)
+		log.Fatalf("Failed to load data: %v", err)
 	}
 
-	// Parse JSON as map - values can be any structure (nested objects, arrays, etc.)
-	var dataMap map[string]any
-	if err := json.Unmarshal(dataContent, &dataMap); err != nil {
-		log.Fatalf("Failed to parse JSON data: %v", err)
+	if dryRun {
+		invalid, err := validateBatch(tmpl, records, outputDir)
+		if err != nil {
+			log.Fatalf("Failed to validate data: %v", err)
+		}
+		if invalid > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Records sharing an output path would overwrite each other, or render into
+	// the same file at once with --concurrency
+	if collisions := outputCollisions(records, outputDir); len(collisions) > 0 {
+		for i, rec := range records {
+			if owner, ok := collisions[i]; ok {
+				log.Printf("Record %s has the same output path as %s", rec.Name, owner)
+			}
+		}
+		log.Fatalf("Failed to render: %d records share an output path; set distinct %s or %s values", len(collisions), fieldFilename, fieldSubfolder)
+	}
+
+	if strict {
+		tmpl.Option("missingkey=error")
+	}
+
+	mail, err := newMailer(emailTo, emailField, emailSubject, emailBody, texttemplate.FuncMap(funcs))
+	if err != nil {
+		log.Fatalf("Failed to set up email: %v", err)
 	}
 
 	// Create output directory
@@ -91,70 +170,152 @@ func main() {
 		log.Fatalf("Failed to create output directory: %v", err)
 	}
 
-	// Process each entry
-	for name, data := range dataMap {
-		if err := renderDocument(tmpl, data, name, outputDir, isMarkdown, imageBasePath); err != nil {
-			log.Printf("Failed to render %s: %v", name, err)
-			continue
+	// Cancel in-flight renders on SIGINT/SIGTERM so Chrome and temp files are cleaned up
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Share one browser across the batch instead of starting Chrome per document
+	pool, err := pdf.NewPool(concurrency, 30*time.Second, pdf.DefaultOptions())
+	if err != nil {
+		log.Fatalf("Failed to start Chrome: %v", err)
+	}
+	defer pool.Close()
+
+	r := &renderer{
+		tmpl:          tmpl,
+		outputDir:     outputDir,
+		isMarkdown:    isMarkdown,
+		imageBasePath: imageBasePath,
+		backend:       pool,
+		mail:          mail,
+	}
+
+	results := renderBatch(ctx, r, records, concurrency)
+
+	if combinePath != "" && ctx.Err() == nil {
+		if err := combineBatch(ctx, pool, results, combinePath, combineIndex); err != nil {
+			log.Printf("Failed to combine documents: %v", err)
+			pool.Close()
+			os.Exit(1)
+		}
+		log.Printf("Combined: %s", combinePath)
+	}
+	if failed := reportBatch(results); failed > 0 {
+		pool.Close()
+		if ctx.Err() != nil {
+			os.Exit(130)
+		}
+		os.Exit(1)
+	}
+}
+
+// templateFuncs returns the built-in template functions plus markdownify and any
+// user-defined functions from funcsPath
+func templateFuncs(funcsPath string) (template.FuncMap, error) {
+	funcs := templates.Funcs()
+	funcs["markdownify"] = markdownify
+
+	if funcsPath != "" {
+		custom, err := templates.LoadFuncFile(funcsPath, funcs)
+		if err != nil {
+			return nil, err
 		}
-		log.Printf("Rendered: %s.pdf", name)
+		for name, fn := range custom {
+			funcs[name] = fn
+		}
+	}
+
+	return funcs, nil
+}
+
+// markdownify converts a markdown string to HTML for use inside templates
+func markdownify(value any) (template.HTML, error) {
+	if value == nil {
+		return "", nil
+	}
+	html, err := mdConverter.ToHTML([]byte(fmt.Sprint(value)))
+	if err != nil {
+		return "", err
 	}
+	return template.HTML(html), nil
 }
 
-// renderDocument renders a single document from template and data
-func renderDocument(tmpl *template.Template, data any, name, outputDir string, isMarkdown bool, imageBasePath string) error {
+// renderer holds the settings shared by every document in a batch
+type renderer struct {
+	tmpl          *template.Template
+	outputDir     string
+	isMarkdown    bool
+	imageBasePath string
+	backend       pdf.Backend
+	mail          *mailer // emails each rendered PDF when set
+}
+
+// render renders a single record from the template, applying the record's
+// rendering overrides, and returns the path of the written PDF
+func (r *renderer) render(ctx context.Context, rec record) (string, error) {
+	data, name := rec.Data, rec.Name
+	docOpts := recordOptions(data)
+
+	pdfOpts, err := docOpts.pdfOptions()
+	if err != nil {
+		return "", err
+	}
+	pdfOpts.Backend = r.backend
+
+	outputPath, err := docOpts.outputPath(r.outputDir, name)
+	if err != nil {
+		return "", err
+	}
+
 	// Execute template with data
 	var buf strings.Builder
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return fmt.Errorf("execute template: %w", err)
+	if err := r.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute template: %w", err)
 	}
 
 	content := buf.String()
 
 	// Convert markdown to HTML if needed
-	if isMarkdown {
+	if r.isMarkdown {
 		html, err := mdConverter.ToHTML([]byte(content))
 		if err != nil {
-			return fmt.Errorf("convert markdown: %w", err)
+			return "", fmt.Errorf("convert markdown: %w", err)
 		}
 		content = html
 	}
 
 	// Embed images as base64 data URLs
-	content, err := images.EmbedImagesAsBase64(content, imageBasePath)
+	content, err = images.EmbedImagesAsBase64(content, r.imageBasePath)
 	if err != nil {
-		return fmt.Errorf("embed images: %w", err)
+		return "", fmt.Errorf("embed images: %w", err)
 	}
 
 	var fullHTML string
 
 	// Markdown templates always need wrapping for styles
 	// HTML templates with their own doctype/head/style are used directly
-	if !isMarkdown && isCompleteHTMLDocument(content) {
+	if !r.isMarkdown && isCompleteHTMLDocument(content) {
 		// Use the template's HTML directly without wrapping
 		fullHTML = content
 	} else {
 		// Wrap in styled HTML template
-		title := name
-		if dataMap, ok := data.(map[string]any); ok {
-			if t, ok := dataMap["Title"].(string); ok {
-				title = t
-			}
-		}
-
-		fullHTML, err = wrapHTML(content, title)
+		fullHTML, err = wrapHTML(content, rec.title())
 		if err != nil {
-			return fmt.Errorf("wrap HTML: %w", err)
+			return "", fmt.Errorf("wrap HTML: %w", err)
 		}
 	}
 
+	fullHTML = addWatermark(fullHTML, docOpts.Watermark)
+
 	// Generate PDF
-	outputPath := filepath.Join(outputDir, name+".pdf")
-	if err := pdf.FromHTML(fullHTML, outputPath); err != nil {
-		return fmt.Errorf("generate PDF: %w", err)
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return "", fmt.Errorf("create output directory: %w", err)
+	}
+	if err := pdf.FromHTMLWithOptions(ctx, fullHTML, outputPath, pdfOpts); err != nil {
+		return "", fmt.Errorf("generate PDF: %w", err)
 	}
 
-	return nil
+	return outputPath, nil
 }
 
 // isCompleteHTMLDocument checks if the content appears to be a complete HTML document
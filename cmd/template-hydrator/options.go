@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+)
+
+// Reserved record fields that override rendering for a single document
+const (
+	fieldPaper       = "_paper"
+	fieldOrientation = "_orientation"
+	fieldFilename    = "_filename"
+	fieldSubfolder   = "_subfolder"
+	fieldWatermark   = "_watermark"
+)
+
+// docOptions holds the per-record rendering overrides
+type docOptions struct {
+	Paper       string
+	Orientation string
+	Filename    string
+	Subfolder   string
+	Watermark   string
+}
+
+// recordOptions reads the reserved override fields from a record
+func recordOptions(data any) docOptions {
+	fields, ok := data.(map[string]any)
+	if !ok {
+		return docOptions{}
+	}
+
+	get := func(key string) string {
+		if v, ok := fields[key]; ok && v != nil {
+			return strings.TrimSpace(fmt.Sprint(v))
+		}
+		return ""
+	}
+
+	return docOptions{
+		Paper:       get(fieldPaper),
+		Orientation: get(fieldOrientation),
+		Filename:    get(fieldFilename),
+		Subfolder:   get(fieldSubfolder),
+		Watermark:   get(fieldWatermark),
+	}
+}
+
+// pdfOptions returns PDF settings with the record's paper size and orientation applied
+func (o docOptions) pdfOptions() (pdf.Options, error) {
+	opts := pdf.DefaultOptions()
+	if err := opts.SetPaper(o.Paper, o.Orientation); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// outputPath returns where the record's PDF is written, honoring filename and subfolder overrides
+func (o docOptions) outputPath(outputDir, name string) (string, error) {
+	if o.Filename != "" {
+		name = sanitizeName(strings.TrimSuffix(o.Filename, ".pdf"))
+		if name == "" {
+			return "", fmt.Errorf("invalid %s %q", fieldFilename, o.Filename)
+		}
+	}
+
+	dir := outputDir
+	if o.Subfolder != "" {
+		for _, part := range strings.FieldsFunc(o.Subfolder, func(r rune) bool { return r == '/' || r == '\\' }) {
+			part = sanitizeName(part)
+			if part == "" {
+				return "", fmt.Errorf("invalid %s %q", fieldSubfolder, o.Subfolder)
+			}
+			dir = filepath.Join(dir, part)
+		}
+	}
+
+	return filepath.Join(dir, name+".pdf"), nil
+}
+
+// outputCollisions maps the index of each record whose output path, after
+// its filename and subfolder overrides, is already taken by an earlier record
+// to that record's name. Records with invalid overrides are skipped.
+func outputCollisions(records []record, outputDir string) map[int]string {
+	owners := make(map[string]string)
+	collisions := make(map[int]string)
+	for i, rec := range records {
+		path, err := recordOptions(rec.Data).outputPath(outputDir, rec.Name)
+		if err != nil {
+			continue
+		}
+		if owner, ok := owners[path]; ok {
+			collisions[i] = owner
+			continue
+		}
+		owners[path] = rec.Name
+	}
+	return collisions
+}
+
+// addWatermark overlays diagonal watermark text on every page of an HTML document
+func addWatermark(document, text string) string {
+	if text == "" {
+		return document
+	}
+
+	overlay := `<style>
+.hydrator-watermark { position: fixed; top: 50%; left: 50%; transform: translate(-50%, -50%) rotate(-45deg);
+  font-size: 96px; font-weight: bold; color: rgba(0, 0, 0, 0.08); white-space: nowrap;
+  pointer-events: none; z-index: 9999; }
+</style>
+<div class="hydrator-watermark">` + html.EscapeString(text) + `</div>
+`
+
+	if i := strings.LastIndex(strings.ToLower(document), "</body>"); i >= 0 {
+		return document[:i] + overlay + document[i:]
+	}
+	return document + overlay
+}
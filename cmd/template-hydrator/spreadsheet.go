@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// readCSV reads all rows of a CSV file; the first row is the header
+func readCSV(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open CSV: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1 // short rows are padded in rowsToItems
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// readXLSX reads all rows of a worksheet (the first sheet if sheet is empty)
+func readXLSX(path, sheet string) ([][]string, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open workbook: %w", err)
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("read sheet %q: %w", sheet, err)
+	}
+	return rows, nil
+}
+
+// rowsToItems maps each data row to a record keyed by the header row.
+// Headers are also exposed as Go identifiers ("Invoice Number" as .InvoiceNumber)
+// so they can be used directly in templates. Blank rows are skipped.
+func rowsToItems(rows [][]string) []any {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	header := rows[0]
+	fields := make([]string, len(header))
+	for i, h := range header {
+		fields[i] = fieldName(h)
+	}
+
+	var items []any
+	for _, row := range rows[1:] {
+		if isBlankRow(row) {
+			continue
+		}
+
+		item := make(map[string]any, len(header)*2)
+		for i, h := range header {
+			h = strings.TrimSpace(h)
+			if h == "" {
+				continue
+			}
+
+			value := ""
+			if i < len(row) {
+				value = strings.TrimSpace(row[i])
+			}
+
+			item[h] = value
+			if fields[i] != "" {
+				if _, exists := item[fields[i]]; !exists {
+					item[fields[i]] = value
+				}
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// fieldName converts a header such as "invoice number" to an identifier like InvoiceNumber
+func fieldName(header string) string {
+	var b strings.Builder
+	upper := true
+
+	for _, r := range strings.TrimSpace(header) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteRune('_')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// isBlankRow reports whether every cell in a row is empty
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/templates"
+)
+
+// recordReport lists the problems found in one record
+type recordReport struct {
+	Name    string
+	Missing []string // fields the template references but the record lacks
+	Unused  []string // record fields the template never references
+	Err     error    // template execution or option error
+	Clashes string   // earlier record writing to the same output path
+}
+
+func (r recordReport) ok() bool { return len(r.Missing) == 0 && r.Err == nil && r.Clashes == "" }
+
+// validateRecord checks a record against the template's fields, its rendering
+// options, and a strict execution of the template
+func validateRecord(tmpl *template.Template, fields []templates.FieldPath, rec record, outputDir string) recordReport {
+	report := recordReport{Name: rec.Name}
+
+	// Spreadsheet columns are exposed under both their header and identifier form
+	referenced := make(map[string]bool)
+	for _, p := range fields {
+		referenced[fieldName(p[0])] = true
+		if !templates.HasPath(rec.Data, p) {
+			report.Missing = append(report.Missing, p.String())
+		}
+	}
+
+	if data, ok := rec.Data.(map[string]any); ok {
+		for key := range data {
+			if strings.HasPrefix(key, "_") || referenced[fieldName(key)] {
+				continue
+			}
+			report.Unused = append(report.Unused, key)
+		}
+		sort.Strings(report.Unused)
+	}
+
+	docOpts := recordOptions(rec.Data)
+	if _, err := docOpts.pdfOptions(); err != nil {
+		report.Err = err
+		return report
+	}
+	if _, err := docOpts.outputPath(outputDir, rec.Name); err != nil {
+		report.Err = err
+		return report
+	}
+
+	// Missing fields would only repeat as an execution error
+	if len(report.Missing) == 0 {
+		if err := tmpl.Execute(io.Discard, rec.Data); err != nil {
+			report.Err = err
+		}
+	}
+
+	return report
+}
+
+// validateBatch validates every record without rendering and logs a report.
+// It returns the number of records with problems.
+func validateBatch(tmpl *template.Template, records []record, outputDir string) (int, error) {
+	// Fail on missing keys instead of silently rendering empty values
+	strict, err := tmpl.Clone()
+	if err != nil {
+		return 0, fmt.Errorf("clone template: %w", err)
+	}
+	strict.Option("missingkey=error")
+
+	fields := templates.Fields(tmpl)
+
+	collisions := outputCollisions(records, outputDir)
+
+	invalid := 0
+	for i, rec := range records {
+		report := validateRecord(strict, fields, rec, outputDir)
+		report.Clashes = collisions[i]
+		if !report.ok() {
+			invalid++
+		}
+
+		switch {
+		case report.ok() && len(report.Unused) == 0:
+			log.Printf("OK: %s", report.Name)
+		case report.ok():
+			log.Printf("OK: %s (unused fields: %s)", report.Name, strings.Join(report.Unused, ", "))
+		default:
+			var problems []string
+			if len(report.Missing) > 0 {
+				problems = append(problems, "missing fields: "+strings.Join(report.Missing, ", "))
+			}
+			if len(report.Unused) > 0 {
+				problems = append(problems, "unused fields: "+strings.Join(report.Unused, ", "))
+			}
+			if report.Clashes != "" {
+				problems = append(problems, "same output path as "+report.Clashes)
+			}
+			if report.Err != nil {
+				problems = append(problems, report.Err.Error())
+			}
+			log.Printf("INVALID: %s: %s", report.Name, strings.Join(problems, "; "))
+		}
+	}
+
+	log.Printf("Validated %d records, %d invalid", len(records), invalid)
+	return invalid, nil
+}
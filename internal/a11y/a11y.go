@@ -0,0 +1,220 @@
+// Package a11y checks rendered HTML documents for common accessibility
+// problems with built-in heuristics: images without alt text, skipped heading
+// levels, empty headings and links, tables without headers, a missing
+// language or title, and text colors with too little contrast.
+package a11y
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Issue is one accessibility problem found in a document.
+type Issue struct {
+	Rule    string // img-alt, heading-order, empty-heading, link-name, table-header, html-lang, document-title, color-contrast
+	Message string
+}
+
+func (i Issue) String() string {
+	return i.Rule + ": " + i.Message
+}
+
+// minContrast is the WCAG AA contrast ratio for normal text
+const minContrast = 4.5
+
+// Check parses a complete HTML document and returns its issues in document
+// order, each distinct issue once.
+func Check(document string) ([]Issue, error) {
+	doc, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %w", err)
+	}
+
+	c := &checker{seen: make(map[Issue]bool), pairs: make(map[[2]rgb]bool)}
+	c.walk(doc, style{color: defaultColor, background: white})
+
+	if c.root != nil && strings.TrimSpace(attr(c.root, "lang")) == "" {
+		c.report("html-lang", "the document has no lang attribute, so screen readers can't pick a language")
+	}
+	if !c.titled {
+		c.report("document-title", "the document has no <title>")
+	}
+	return c.issues, nil
+}
+
+type checker struct {
+	issues  []Issue
+	seen    map[Issue]bool
+	pairs   map[[2]rgb]bool // color pairs reported for contrast
+	root    *html.Node
+	titled  bool
+	heading int // level of the previous heading, 0 before the first
+	images  int
+}
+
+func (c *checker) report(rule, format string, args ...any) {
+	issue := Issue{Rule: rule, Message: fmt.Sprintf(format, args...)}
+	if !c.seen[issue] {
+		c.seen[issue] = true
+		c.issues = append(c.issues, issue)
+	}
+}
+
+// skipped lists elements whose content isn't read as page text
+var skipped = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Math:     true,
+}
+
+// headingLevels maps heading elements to their level
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+func (c *checker) walk(n *html.Node, inherited style) {
+	if n.Type == html.TextNode {
+		if strings.TrimSpace(n.Data) != "" {
+			c.contrast(n, inherited)
+		}
+		return
+	}
+	if n.Type == html.ElementNode {
+		if hidden(n) {
+			return
+		}
+		switch n.DataAtom {
+		case atom.Html:
+			c.root = n
+		case atom.Title:
+			c.titled = c.titled || strings.TrimSpace(text(n)) != ""
+			return
+		case atom.Img:
+			c.images++
+			if _, ok := attrOK(n, "alt"); !ok && attr(n, "role") != "presentation" && attr(n, "aria-label") == "" {
+				c.report("img-alt", "image %s has no alt text", describeImage(n, c.images))
+			}
+		case atom.A:
+			if attr(n, "href") != "" && accessibleName(n) == "" {
+				c.report("link-name", "link to %s has no text", truncate(attr(n, "href")))
+			}
+		case atom.Table:
+			if find(n, atom.Th) == nil {
+				c.report("table-header", "table %q has no header cells", truncate(strings.Join(strings.Fields(text(n)), " ")))
+			}
+		}
+		if level, ok := headingLevels[n.DataAtom]; ok {
+			title := strings.Join(strings.Fields(text(n)), " ")
+			if title == "" {
+				c.report("empty-heading", "an h%d heading has no text", level)
+			}
+			if c.heading > 0 && level > c.heading+1 {
+				c.report("heading-order", "h%d %q follows an h%d, skipping a level", level, truncate(title), c.heading)
+			}
+			c.heading = level
+		}
+		if skipped[n.DataAtom] {
+			return
+		}
+		inherited = inherited.apply(attr(n, "style"))
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.walk(child, inherited)
+	}
+}
+
+// contrast reports text whose inline colors contrast too little, once per
+// color pair since highlighted code repeats them on every line
+func (c *checker) contrast(n *html.Node, s style) {
+	if !s.explicit || c.pairs[[2]rgb{s.color, s.background}] {
+		return
+	}
+	if ratio := contrastRatio(s.color, s.background); ratio < minContrast {
+		c.pairs[[2]rgb{s.color, s.background}] = true
+		c.report("color-contrast", "%s text on %s has a contrast ratio of %.1f:1, below %.1f:1, e.g. %q",
+			s.color, s.background, ratio, minContrast, truncate(strings.TrimSpace(n.Data)))
+	}
+}
+
+// hidden reports whether an element is not presented at all
+func hidden(n *html.Node) bool {
+	if _, ok := attrOK(n, "hidden"); ok || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	return strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "display:none")
+}
+
+// accessibleName returns the text a screen reader announces for an element
+func accessibleName(n *html.Node) string {
+	if label := strings.TrimSpace(attr(n, "aria-label")); label != "" {
+		return label
+	}
+	if name := strings.TrimSpace(text(n)); name != "" {
+		return name
+	}
+	var alt string
+	for img := range n.Descendants() {
+		if img.Type == html.ElementNode && img.DataAtom == atom.Img {
+			alt += strings.TrimSpace(attr(img, "alt"))
+		}
+	}
+	return alt
+}
+
+// describeImage names an image by its source, or by position for embedded ones
+func describeImage(n *html.Node, index int) string {
+	src := attr(n, "src")
+	if src == "" || strings.HasPrefix(src, "data:") {
+		return fmt.Sprintf("#%d", index)
+	}
+	return truncate(src)
+}
+
+// text returns the text content of a node
+func text(n *html.Node) string {
+	var b strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+		}
+	}
+	return b.String()
+}
+
+// find returns the first descendant element of type a
+func find(n *html.Node, a atom.Atom) *html.Node {
+	for d := range n.Descendants() {
+		if d.Type == html.ElementNode && d.DataAtom == a {
+			return d
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := attrOK(n, key)
+	return v
+}
+
+func attrOK(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// truncate shortens text quoted in an issue
+func truncate(s string) string {
+	if r := []rune(s); len(r) > 60 {
+		return string(r[:57]) + "..."
+	}
+	return s
+}
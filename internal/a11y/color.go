@@ -0,0 +1,144 @@
+package a11y
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// rgb is an opaque sRGB color
+type rgb struct{ r, g, b uint8 }
+
+func (c rgb) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+}
+
+var (
+	white = rgb{255, 255, 255}
+
+	// defaultColor is the body text color of the built-in template
+	defaultColor = rgb{0x24, 0x29, 0x2e}
+)
+
+// namedColors are the CSS color keywords common in hand-written styles
+var namedColors = map[string]rgb{
+	"black": {0, 0, 0}, "white": white, "gray": {128, 128, 128}, "grey": {128, 128, 128},
+	"silver": {192, 192, 192}, "lightgray": {211, 211, 211}, "lightgrey": {211, 211, 211},
+	"darkgray": {169, 169, 169}, "darkgrey": {169, 169, 169}, "red": {255, 0, 0},
+	"green": {0, 128, 0}, "blue": {0, 0, 255}, "yellow": {255, 255, 0}, "orange": {255, 165, 0},
+	"purple": {128, 0, 128}, "navy": {0, 0, 128}, "maroon": {128, 0, 0}, "teal": {0, 128, 128},
+	"olive": {128, 128, 0}, "lime": {0, 255, 0}, "aqua": {0, 255, 255}, "cyan": {0, 255, 255},
+	"fuchsia": {255, 0, 255}, "magenta": {255, 0, 255},
+}
+
+// style is the text and background color in effect for an element, from
+// inline style attributes; explicit once an inline style set either
+type style struct {
+	color      rgb
+	background rgb
+	explicit   bool
+}
+
+// apply returns the style after an element's style attribute
+func (s style) apply(attr string) style {
+	for _, decl := range strings.Split(attr, ";") {
+		prop, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		switch strings.ToLower(strings.TrimSpace(prop)) {
+		case "color":
+			if c, ok := parseColor(value, s.background); ok {
+				s.color, s.explicit = c, true
+			}
+		case "background-color", "background":
+			if c, ok := parseColor(value, s.background); ok {
+				s.background, s.explicit = c, true
+			}
+		}
+	}
+	return s
+}
+
+// parseColor parses hex, rgb(), rgba(), and named colors; translucent colors
+// are blended over the background below them
+func parseColor(value string, below rgb) (rgb, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if c, ok := namedColors[value]; ok {
+		return c, true
+	}
+
+	if hex, ok := strings.CutPrefix(value, "#"); ok {
+		switch len(hex) {
+		case 3, 4:
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		case 6, 8:
+			hex = hex[:6]
+		default:
+			return rgb{}, false
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return rgb{}, false
+		}
+		return rgb{uint8(n >> 16), uint8(n >> 8), uint8(n)}, true
+	}
+
+	args, ok := strings.CutPrefix(value, "rgba(")
+	if !ok {
+		args, ok = strings.CutPrefix(value, "rgb(")
+	}
+	if !ok || !strings.HasSuffix(args, ")") {
+		return rgb{}, false
+	}
+	fields := strings.FieldsFunc(strings.TrimSuffix(args, ")"), func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+	if len(fields) < 3 {
+		return rgb{}, false
+	}
+	var channels [3]float64
+	for i := range channels {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return rgb{}, false
+		}
+		channels[i] = v
+	}
+	alpha := 1.0
+	if len(fields) > 3 {
+		a, err := strconv.ParseFloat(strings.TrimSuffix(fields[3], "%"), 64)
+		if err != nil {
+			return rgb{}, false
+		}
+		if strings.HasSuffix(fields[3], "%") {
+			a /= 100
+		}
+		alpha = a
+	}
+	blend := func(v float64, under uint8) uint8 {
+		return uint8(math.Round(v*alpha + float64(under)*(1-alpha)))
+	}
+	return rgb{blend(channels[0], below.r), blend(channels[1], below.g), blend(channels[2], below.b)}, true
+}
+
+// contrastRatio is the WCAG contrast ratio of two colors, from 1 to 21
+func contrastRatio(a, b rgb) float64 {
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// luminance is the WCAG relative luminance of a color
+func luminance(c rgb) float64 {
+	linear := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c.r) + 0.7152*linear(c.g) + 0.0722*linear(c.b)
+}
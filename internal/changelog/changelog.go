@@ -0,0 +1,167 @@
+// Package changelog parses CHANGELOG.md files in the Keep a Changelog format
+// (https://keepachangelog.com) and selects ranges of releases.
+package changelog
+
+import (
+	"bytes"
+	"cmp"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unreleased is the version of the section collecting upcoming changes
+const Unreleased = "Unreleased"
+
+var (
+	// ## [1.2.0] - 2024-01-31, ## 1.2.0 (2024-01-31), ## [Unreleased], ## [0.9.0] - 2023-12-01 [YANKED]
+	releaseRegex = regexp.MustCompile(`^##\s+\[?([^\]\s]+?)\]?(?:\s+[-–—(]\s*(\d{4}-\d{2}-\d{2})\)?)?(\s+\[YANKED\])?\s*$`)
+	linkRefRegex = regexp.MustCompile(`^\[([^\]]+)\]:\s*(\S+)`)
+	numberRegex  = regexp.MustCompile(`\d+|\D+`)
+)
+
+// Release is one version section of a changelog.
+type Release struct {
+	Version string
+	Date    string // YYYY-MM-DD, empty when not given
+	Yanked  bool
+	Link    string // from a [version]: URL reference, such as a compare view
+	Body    []byte // markdown below the version heading
+}
+
+// Changelog is a parsed changelog, newest release first.
+type Changelog struct {
+	Title    string // the level-one heading
+	Intro    []byte // markdown between the title and the first release
+	Releases []Release
+}
+
+// Parse reads a Keep a Changelog document. Link reference definitions are
+// attached to the releases they name.
+func Parse(src []byte) Changelog {
+	var c Changelog
+	var intro bytes.Buffer
+	var current *Release
+	links := make(map[string]string)
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n") {
+		if m := linkRefRegex.FindStringSubmatch(line); m != nil {
+			links[strings.ToLower(m[1])] = m[2]
+			continue
+		}
+		if title, ok := strings.CutPrefix(line, "# "); ok && c.Title == "" && current == nil {
+			c.Title = strings.TrimSpace(title)
+			continue
+		}
+		if m := releaseRegex.FindStringSubmatch(line); m != nil {
+			c.Releases = append(c.Releases, Release{Version: m[1], Date: m[2], Yanked: m[3] != ""})
+			current = &c.Releases[len(c.Releases)-1]
+			continue
+		}
+
+		if current == nil {
+			intro.WriteString(line + "\n")
+		} else {
+			current.Body = append(current.Body, line+"\n"...)
+		}
+	}
+
+	c.Intro = bytes.TrimSpace(intro.Bytes())
+	for i := range c.Releases {
+		r := &c.Releases[i]
+		r.Body = bytes.TrimSpace(r.Body)
+		r.Link = links[strings.ToLower(r.Version)]
+	}
+	return c
+}
+
+// Select returns the releases from since through until, both inclusive and
+// either of which may be empty for an open range. Versions compare by their
+// numbers, so v1.2.0 and 1.2.0 are the same release. The Unreleased section is
+// only kept when until is Unreleased.
+func (c Changelog) Select(since, until string) []Release {
+	var selected []Release
+	for _, r := range c.Releases {
+		if strings.EqualFold(r.Version, Unreleased) {
+			if strings.EqualFold(until, Unreleased) {
+				selected = append(selected, r)
+			}
+			continue
+		}
+		if since != "" && Compare(r.Version, since) < 0 {
+			continue
+		}
+		if until != "" && !strings.EqualFold(until, Unreleased) && Compare(r.Version, until) > 0 {
+			continue
+		}
+		selected = append(selected, r)
+	}
+	return selected
+}
+
+// Compare orders two versions such as v1.10.0 and 1.9.2-rc.1 by their numeric
+// parts. A pre-release sorts before the release it precedes.
+func Compare(a, b string) int {
+	a, b = strings.TrimPrefix(strings.ToLower(a), "v"), strings.TrimPrefix(strings.ToLower(b), "v")
+	coreA, preA, _ := strings.Cut(a, "-")
+	coreB, preB, _ := strings.Cut(b, "-")
+
+	if c := compareParts(coreA, coreB); c != 0 {
+		return c
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return compareParts(preA, preB)
+}
+
+// compareParts compares dotted versions part by part, numbers by value
+func compareParts(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if c := comparePart(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// comparePart compares one version part, treating missing parts as 0
+func comparePart(a, b string) int {
+	if a == "" {
+		a = "0"
+	}
+	if b == "" {
+		b = "0"
+	}
+
+	ta, tb := numberRegex.FindAllString(a, -1), numberRegex.FindAllString(b, -1)
+	for i := 0; i < min(len(ta), len(tb)); i++ {
+		na, errA := strconv.Atoi(ta[i])
+		nb, errB := strconv.Atoi(tb[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case errA != nil || errB != nil:
+			if c := strings.Compare(ta[i], tb[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmp.Compare(len(ta), len(tb))
+}
@@ -0,0 +1,238 @@
+// Package confluence publishes rendered documents to Confluence pages through
+// its REST API.
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Client talks to the REST API of a Confluence site.
+type Client struct {
+	base string // site URL, e.g. https://acme.atlassian.net/wiki
+	auth string // Authorization header value
+	http *http.Client
+}
+
+// NewClient creates a client for the site at baseURL, authenticating with
+// CONFLUENCE_USER and CONFLUENCE_API_TOKEN (Confluence Cloud), or with the
+// personal access token in CONFLUENCE_TOKEN (Data Center).
+func NewClient(baseURL string) (*Client, error) {
+	c := &Client{
+		base: strings.TrimSuffix(baseURL, "/"),
+		http: &http.Client{Timeout: 60 * time.Second},
+	}
+
+	user, apiToken, pat := os.Getenv("CONFLUENCE_USER"), os.Getenv("CONFLUENCE_API_TOKEN"), os.Getenv("CONFLUENCE_TOKEN")
+	switch {
+	case user != "" && apiToken != "":
+		req := http.Request{Header: http.Header{}}
+		req.SetBasicAuth(user, apiToken)
+		c.auth = req.Header.Get("Authorization")
+	case pat != "":
+		c.auth = "Bearer " + pat
+	default:
+		return nil, fmt.Errorf("set CONFLUENCE_USER and CONFLUENCE_API_TOKEN, or CONFLUENCE_TOKEN")
+	}
+	return c, nil
+}
+
+// Page is a page to create or update.
+type Page struct {
+	Space  string // space key
+	Parent string // parent page ID; empty places new pages at the space root
+	Title  string
+	Body   string // storage format
+
+	Attachments []Attachment
+}
+
+// content is the REST representation of a page
+type content struct {
+	ID        string     `json:"id,omitempty"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Space     *spaceRef  `json:"space,omitempty"`
+	Ancestors []pageRef  `json:"ancestors,omitempty"`
+	Version   *version   `json:"version,omitempty"`
+	Body      *bodyValue `json:"body,omitempty"`
+}
+
+type spaceRef struct {
+	Key string `json:"key"`
+}
+
+type pageRef struct {
+	ID string `json:"id"`
+}
+
+type version struct {
+	Number int `json:"number"`
+}
+
+type bodyValue struct {
+	Storage struct {
+		Value          string `json:"value"`
+		Representation string `json:"representation"`
+	} `json:"storage"`
+}
+
+// Publish creates the page, or updates the page of the same title in the
+// space, then uploads its attachments. It returns the page URL.
+func (c *Client) Publish(ctx context.Context, p Page) (string, error) {
+	existing, err := c.find(ctx, p.Space, p.Title)
+	if err != nil {
+		return "", err
+	}
+
+	page := content{
+		Type:  "page",
+		Title: p.Title,
+		Space: &spaceRef{Key: p.Space},
+		Body:  &bodyValue{},
+	}
+	page.Body.Storage.Value = p.Body
+	page.Body.Storage.Representation = "storage"
+	if p.Parent != "" {
+		page.Ancestors = []pageRef{{ID: p.Parent}}
+	}
+
+	var saved content
+	if existing != nil {
+		page.ID = existing.ID
+		page.Version = &version{Number: existing.Version.Number + 1}
+		err = c.doJSON(ctx, http.MethodPut, "/rest/api/content/"+existing.ID, page, &saved)
+	} else {
+		err = c.doJSON(ctx, http.MethodPost, "/rest/api/content", page, &saved)
+	}
+	if err != nil {
+		return "", fmt.Errorf("save page %q: %w", p.Title, err)
+	}
+
+	for _, a := range p.Attachments {
+		if err := c.attach(ctx, saved.ID, a); err != nil {
+			return "", fmt.Errorf("attach %s: %w", a.Name, err)
+		}
+	}
+
+	return c.base + "/pages/viewpage.action?pageId=" + saved.ID, nil
+}
+
+// find returns the page with the title in the space, or nil
+func (c *Client) find(ctx context.Context, space, title string) (*content, error) {
+	query := url.Values{
+		"spaceKey": {space},
+		"title":    {title},
+		"type":     {"page"},
+		"expand":   {"version"},
+	}
+
+	var found struct {
+		Results []content `json:"results"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/rest/api/content?"+query.Encode(), nil, &found); err != nil {
+		return nil, fmt.Errorf("find page %q: %w", title, err)
+	}
+	if len(found.Results) == 0 {
+		return nil, nil
+	}
+	if found.Results[0].Version == nil {
+		return nil, fmt.Errorf("find page %q: response has no version", title)
+	}
+	return &found.Results[0], nil
+}
+
+// attach creates or updates an attachment on a page
+func (c *Client) attach(ctx context.Context, pageID string, a Attachment) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, a.Name))
+	header.Set("Content-Type", a.ContentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(a.Data); err != nil {
+		return err
+	}
+	if err := form.WriteField("minorEdit", "true"); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := c.request(ctx, http.MethodPut, "/rest/api/content/"+pageID+"/child/attachment", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-Atlassian-Token", "no-check")
+	return c.do(req, nil)
+}
+
+// doJSON sends in as JSON, if set, and decodes the response into out
+func (c *Client) doJSON(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := c.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(req, out)
+}
+
+// request creates an authenticated API request
+func (c *Client) request(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", c.auth)
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// do sends the request, decoding a JSON response into out when it is not nil
+func (c *Client) do(req *http.Request, out any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
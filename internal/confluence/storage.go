@@ -0,0 +1,187 @@
+package confluence
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Attachment is a file uploaded to a page.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// skipped lists elements that carry no page content
+var skipped = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Link:     true,
+	atom.Noscript: true,
+	atom.Template: true,
+}
+
+// imageExtensions names attachments of common image types
+var imageExtensions = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+}
+
+// Storage converts the body of a rendered HTML document to Confluence storage
+// format. Embedded images become attachments and remote images are linked.
+func Storage(document string) (string, []Attachment, error) {
+	doc, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return "", nil, fmt.Errorf("parse html: %w", err)
+	}
+
+	body := findBody(doc)
+	if body == nil {
+		return "", nil, fmt.Errorf("document has no body")
+	}
+
+	var attachments []Attachment
+	seen := make(map[string]bool)
+	for _, a := range convertImages(body) {
+		if !seen[a.Name] {
+			seen[a.Name] = true
+			attachments = append(attachments, a)
+		}
+	}
+
+	var buf bytes.Buffer
+	for n := body.FirstChild; n != nil; n = n.NextSibling {
+		if err := html.Render(&buf, n); err != nil {
+			return "", nil, fmt.Errorf("render storage format: %w", err)
+		}
+	}
+
+	return strings.TrimSpace(buf.String()), attachments, nil
+}
+
+// findBody returns the <body> element of a parsed document
+func findBody(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Body {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if b := findBody(c); b != nil {
+			return b
+		}
+	}
+	return nil
+}
+
+// convertImages replaces <img> elements under n with Confluence image macros,
+// removes scripts and styles, and returns the attachments the images need
+func convertImages(n *html.Node) []Attachment {
+	var attachments []Attachment
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if c.Type == html.ElementNode && skipped[c.DataAtom] {
+				n.RemoveChild(c)
+			} else {
+				walk(c)
+			}
+			c = next
+		}
+
+		if n.Type != html.ElementNode || n.DataAtom != atom.Img || n.Parent == nil {
+			return
+		}
+
+		src, alt := attr(n, "src"), attr(n, "alt")
+		var resource *html.Node
+		switch {
+		case strings.HasPrefix(src, "data:"):
+			a, ok := dataAttachment(src)
+			if !ok {
+				break
+			}
+			attachments = append(attachments, a)
+			resource = element("ri:attachment", "ri:filename", a.Name)
+		case strings.HasPrefix(src, "http://"), strings.HasPrefix(src, "https://"):
+			resource = element("ri:url", "ri:value", src)
+		}
+
+		if resource == nil {
+			// Unresolvable images keep their alt text
+			n.Parent.InsertBefore(&html.Node{Type: html.TextNode, Data: alt}, n)
+			n.Parent.RemoveChild(n)
+			return
+		}
+
+		image := element("ac:image")
+		if alt != "" {
+			image.Attr = append(image.Attr, html.Attribute{Key: "ac:alt", Val: alt})
+		}
+		image.AppendChild(resource)
+		n.Parent.InsertBefore(image, n)
+		n.Parent.RemoveChild(n)
+	}
+
+	walk(n)
+	return attachments
+}
+
+// dataAttachment decodes a base64 data URL into an attachment named after its
+// content, so unchanged images keep their name across exports
+func dataAttachment(src string) (Attachment, bool) {
+	meta, payload, ok := strings.Cut(strings.TrimPrefix(src, "data:"), ",")
+	if !ok || !strings.HasSuffix(meta, ";base64") {
+		return Attachment{}, false
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return Attachment{}, false
+	}
+
+	contentType := strings.TrimSuffix(meta, ";base64")
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		ext = ".bin"
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	return Attachment{
+		Name:        "image-" + hex.EncodeToString(sum[:6]) + ext,
+		ContentType: contentType,
+		Data:        data,
+	}, true
+}
+
+// element creates an element with attribute key/value pairs
+func element(name string, attrs ...string) *html.Node {
+	n := &html.Node{Type: html.ElementNode, Data: name}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		n.Attr = append(n.Attr, html.Attribute{Key: attrs[i], Val: attrs[i+1]})
+	}
+	return n
+}
+
+// attr returns the value of an element attribute
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+// Package fileurl converts between local paths and file:// URLs on every
+// platform, including Windows drive letters (file:///C:/docs/a.html) and UNC
+// shares (file://server/share/a.html).
+package fileurl
+
+import (
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// FromPath returns the file:// URL of a local path, made absolute first.
+func FromPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	slashed := filepath.ToSlash(abs)
+
+	u := url.URL{Scheme: "file", Path: slashed}
+	switch {
+	case strings.HasPrefix(slashed, "//"):
+		// UNC path: \\server\share\file
+		host, rest, _ := strings.Cut(strings.TrimPrefix(slashed, "//"), "/")
+		u.Host, u.Path = host, "/"+rest
+	case !strings.HasPrefix(slashed, "/"):
+		// Drive letter: C:/docs
+		u.Path = "/" + slashed
+	}
+	return u.String(), nil
+}
+
+// Path returns the local path a file:// URL points to, and false when ref is
+// not a file URL.
+func Path(ref string) (string, bool) {
+	if !strings.HasPrefix(strings.ToLower(ref), "file:") {
+		return "", false
+	}
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+
+	p := u.Path
+	if u.Host != "" && u.Host != "localhost" {
+		p = "//" + u.Host + p
+	}
+	if runtime.GOOS == "windows" && hasDrive(strings.TrimPrefix(p, "/")) {
+		p = strings.TrimPrefix(p, "/")
+	}
+	return filepath.FromSlash(p), true
+}
+
+// hasDrive reports whether p starts with a drive letter such as C:
+func hasDrive(p string) bool {
+	return len(p) >= 2 && p[1] == ':' && ('a' <= p[0]|0x20 && p[0]|0x20 <= 'z')
+}
@@ -0,0 +1,87 @@
+// Package frontmatter reads YAML front matter from markdown documents.
+package frontmatter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	delimiter = []byte("---")
+	newline   = []byte("\n")
+)
+
+// Split separates a leading "---" delimited front matter block from the body.
+// ok is false when the document has no front matter.
+func Split(src []byte) (meta, body []byte, ok bool) {
+	first, rest, found := bytes.Cut(src, newline)
+	if !found || !isDelimiter(first) {
+		return nil, src, false
+	}
+
+	for pos := 0; pos < len(rest); {
+		line, tail, _ := bytes.Cut(rest[pos:], newline)
+		if isDelimiter(line) {
+			return rest[:pos], tail, true
+		}
+		pos = len(rest) - len(tail)
+	}
+
+	return nil, src, false
+}
+
+// Parse returns the front matter fields of a document and its body.
+// Documents without front matter have no fields.
+func Parse(src []byte) (map[string]any, []byte, error) {
+	meta, body, ok := Split(src)
+	if !ok {
+		return map[string]any{}, src, nil
+	}
+
+	fields := map[string]any{}
+	if err := yaml.Unmarshal(meta, &fields); err != nil {
+		return nil, src, fmt.Errorf("parse front matter: %w", err)
+	}
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	return fields, body, nil
+}
+
+// ReadFile parses the front matter of a file.
+func ReadFile(path string) (map[string]any, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fields, _, err := Parse(src)
+	return fields, err
+}
+
+// Bool reports whether a field is true, accepting YAML booleans and "true"/"yes" strings.
+func Bool(fields map[string]any, key string) bool {
+	switch v := fields[key].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true" || v == "yes"
+	}
+	return false
+}
+
+// String returns a string field, or "" when it is missing or not a string.
+func String(fields map[string]any, key string) string {
+	if v, ok := fields[key].(string); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+// isDelimiter reports whether a line is a front matter delimiter.
+func isDelimiter(line []byte) bool {
+	return bytes.Equal(bytes.TrimRight(line, " \t\r"), delimiter)
+}
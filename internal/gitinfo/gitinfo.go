@@ -0,0 +1,148 @@
+// Package gitinfo reads metadata about the git checkout in the working directory.
+package gitinfo
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Info describes the commit the working directory is checked out at.
+// Fields are empty when git or the information is unavailable.
+type Info struct {
+	SHA        string
+	ShortSHA   string
+	Tag        string // tag pointing at the commit, if any
+	Branch     string
+	CommitDate time.Time
+}
+
+// Load reads the current commit, tag, branch, and commit date.
+func Load() Info {
+	info := Info{
+		SHA:    git("rev-parse", "HEAD"),
+		Tag:    git("describe", "--tags", "--exact-match"),
+		Branch: Branch(),
+	}
+
+	if len(info.SHA) >= 7 {
+		info.ShortSHA = info.SHA[:7]
+	}
+
+	if date := git("log", "-1", "--format=%cI"); date != "" {
+		if t, err := time.Parse(time.RFC3339, date); err == nil {
+			info.CommitDate = t
+		}
+	}
+
+	return info
+}
+
+// Branch returns the checked-out branch name, or "" when unknown.
+func Branch() string {
+	return git("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// Expand replaces the {sha}, {short_sha}, {tag}, {branch}, and {commit_date}
+// placeholders in s. The commit date is formatted as YYYY-MM-DD.
+func (i Info) Expand(s string) string {
+	if !strings.Contains(s, "{") {
+		return s
+	}
+
+	date := ""
+	if !i.CommitDate.IsZero() {
+		date = i.CommitDate.Format("2006-01-02")
+	}
+
+	return strings.NewReplacer(
+		"{sha}", i.SHA,
+		"{short_sha}", i.ShortSHA,
+		"{tag}", i.Tag,
+		"{branch}", i.Branch,
+		"{commit_date}", date,
+	).Replace(s)
+}
+
+// git runs a git command and returns its trimmed output, or "" on failure.
+func git(args ...string) string {
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Commit is one entry of a file's history.
+type Commit struct {
+	SHA     string
+	Author  string
+	Date    time.Time
+	Subject string
+}
+
+// History returns up to n of the most recent commits touching any of paths,
+// newest first.
+func History(paths []string, n int) ([]Commit, error) {
+	args := append([]string{"log", "-n", strconv.Itoa(n), "--format=%H%x1f%an%x1f%cI%x1f%s", "--"}, paths...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, Commit{SHA: fields[0], Author: fields[1], Date: date, Subject: fields[3]})
+	}
+	return commits, nil
+}
+
+// Attribution tells who wrote a file: its authors, most commits first, and
+// its last change.
+type Attribution struct {
+	Authors []string
+	Last    Commit
+}
+
+// Attribute reads the authors and last commit of path from its history,
+// following renames, in the repository holding it. ok is false for files
+// without history, including files outside any repository.
+func Attribute(path string) (a Attribution, ok bool, err error) {
+	dir := filepath.Dir(path)
+	if exec.Command("git", "-C", dir, "rev-parse", "--is-inside-work-tree").Run() != nil {
+		return a, false, nil
+	}
+	output, err := exec.Command("git", "-C", dir, "log", "--follow", "--format=%H%x1f%an%x1f%cI%x1f%s", "--", filepath.Base(path)).Output()
+	if err != nil {
+		return a, false, fmt.Errorf("git log: %w", err)
+	}
+
+	commits := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		if !ok {
+			date, _ := time.Parse(time.RFC3339, fields[2])
+			a.Last = Commit{SHA: fields[0], Author: fields[1], Date: date, Subject: fields[3]}
+			ok = true
+		}
+		if commits[fields[1]] == 0 {
+			a.Authors = append(a.Authors, fields[1])
+		}
+		commits[fields[1]]++
+	}
+	// Ties keep the order of the most recent commit
+	slices.SortStableFunc(a.Authors, func(x, y string) int { return commits[y] - commits[x] })
+	return a, ok, nil
+}
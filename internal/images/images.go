@@ -4,43 +4,241 @@ package images
 import (
 	"encoding/base64"
 	"fmt"
+	"html"
 	"log"
 	"mime"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/fileurl"
+	"github.com/kuzik/markdown-pdf-action/internal/qrcode"
 )
 
+// QRScheme prefixes image sources generated as QR codes, e.g. qr:https://example.com
+const QRScheme = "qr:"
+
 var (
 	imgRegex = regexp.MustCompile(`<img\s+[^>]*src=["']([^"']+)["'][^>]*>`)
 	srcRegex = regexp.MustCompile(`src=["']([^"']+)["']`)
+
+	srcsetTagRegex  = regexp.MustCompile(`<(?:img|source)\s+[^>]*srcset=["'][^"']+["'][^>]*>`)
+	srcsetRegex     = regexp.MustCompile(`srcset=(["'])([^"']+)["']`)
+	styleBlockRegex = regexp.MustCompile(`(?is)<style[^>]*>.*?</style>`)
+	styleAttrRegex  = regexp.MustCompile(`style="[^"]*url\([^"]*"`)
+	cssURLRegex     = regexp.MustCompile(`url\(\s*(?:'([^']+)'|"([^"]+)"|([^'")\s]+))\s*\)`)
+
+	// C:\docs, C:/docs, and drive-relative C:docs on any platform
+	driveRegex = regexp.MustCompile(`^[A-Za-z]:`)
 )
 
 // EmbedImagesAsBase64 replaces relative image paths with base64 data URLs in HTML content.
 func EmbedImagesAsBase64(htmlContent, baseDir string) (string, error) {
+	return embedder{baseDir: baseDir}.embed(htmlContent), nil
+}
+
+// EmbedImagesConfined is EmbedImagesAsBase64 for untrusted content: only
+// files inside baseDir are embedded (see ConfinedPath), and none without one.
+func EmbedImagesConfined(htmlContent, baseDir string) (string, error) {
+	return embedder{baseDir: baseDir, confined: true}.embed(htmlContent), nil
+}
+
+// embedder inlines the local files an HTML document references
+type embedder struct {
+	baseDir  string
+	confined bool // only files inside baseDir
+}
+
+// blockedURL replaces the local references a confined embedder didn't inline,
+// which Chrome would otherwise load itself from the file:// page
+const blockedURL = "data:,"
+
+// embed inlines the images, srcset candidates, and CSS url()s of HTML content
+func (e embedder) embed(htmlContent string) string {
 	result := imgRegex.ReplaceAllStringFunc(htmlContent, func(imgTag string) string {
 		srcPath := ExtractSrcAttribute(imgTag)
 		if srcPath == "" {
 			return imgTag
 		}
 
+		if text, ok := strings.CutPrefix(srcPath, QRScheme); ok {
+			code, err := qrcode.Encode(html.UnescapeString(text))
+			if err != nil {
+				log.Printf("Warning: failed to generate QR code for %s: %v", text, err)
+				return imgTag
+			}
+			return ReplaceSrcAttribute(imgTag, code.DataURL())
+		}
+
 		// Skip data URLs and absolute URLs
 		if IsAbsoluteOrDataURL(srcPath) {
 			return imgTag
 		}
 
 		// Convert to data URL
-		dataURL, err := ImageToDataURL(srcPath, baseDir)
+		dataURL, err := e.dataURL(srcPath)
 		if err != nil {
 			log.Printf("Warning: failed to embed image %s: %v", srcPath, err)
+			if e.confined {
+				return ReplaceSrcAttribute(imgTag, blockedURL)
+			}
 			return imgTag
 		}
 
 		return ReplaceSrcAttribute(imgTag, dataURL)
 	})
 
-	return result, nil
+	result = e.embedSrcset(result)
+	return e.embedCSS(result)
+}
+
+// dataURL reads a referenced file into a data URL
+func (e embedder) dataURL(srcPath string) (string, error) {
+	if !e.confined {
+		return ImageToDataURL(srcPath, e.baseDir)
+	}
+	path, err := ConfinedPath(srcPath, e.baseDir)
+	if err != nil {
+		return "", err
+	}
+	return fileToDataURL(path)
+}
+
+// EmbedSrcsetImages inlines every candidate in srcset attributes of <img> and
+// <picture><source> elements. Attributes without local candidates are left
+// untouched.
+func EmbedSrcsetImages(htmlContent, baseDir string) string {
+	return embedder{baseDir: baseDir}.embedSrcset(htmlContent)
+}
+
+func (e embedder) embedSrcset(htmlContent string) string {
+	return srcsetTagRegex.ReplaceAllStringFunc(htmlContent, func(tag string) string {
+		return srcsetRegex.ReplaceAllStringFunc(tag, func(attr string) string {
+			m := srcsetRegex.FindStringSubmatch(attr)
+			quote, value := m[1], m[2]
+
+			candidates := parseSrcset(value)
+			changed := false
+			for i, c := range candidates {
+				if IsAbsoluteOrDataURL(c.url) {
+					continue
+				}
+
+				dataURL, err := e.dataURL(c.url)
+				if err != nil {
+					log.Printf("Warning: failed to embed srcset image %s: %v", c.url, err)
+					if e.confined {
+						candidates[i].url = "" // dropped below
+						changed = true
+					}
+					continue
+				}
+				candidates[i].url = dataURL
+				changed = true
+			}
+			if !changed {
+				return attr
+			}
+
+			var parts []string
+			for _, c := range candidates {
+				if c.url != "" {
+					parts = append(parts, strings.TrimSpace(c.url+" "+c.descriptors))
+				}
+			}
+			return "srcset=" + quote + strings.Join(parts, ", ") + quote
+		})
+	})
+}
+
+// srcsetCandidate is an image candidate of a srcset attribute
+type srcsetCandidate struct {
+	url         string
+	descriptors string // e.g. "2x" or "480w"
+}
+
+// parseSrcset splits a srcset attribute into its candidates following the
+// HTML grammar: a URL runs up to whitespace, so the commas of data: URLs are
+// kept, and its descriptors up to the next comma outside parentheses
+func parseSrcset(value string) []srcsetCandidate {
+	var candidates []srcsetCandidate
+	isSpace := func(b byte) bool { return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f' }
+
+	for i := 0; i < len(value); {
+		for i < len(value) && (isSpace(value[i]) || value[i] == ',') {
+			i++
+		}
+		if i == len(value) {
+			break
+		}
+
+		start := i
+		for i < len(value) && !isSpace(value[i]) {
+			i++
+		}
+		url := value[start:i]
+
+		// A URL ending in commas has no descriptors
+		if trimmed := strings.TrimRight(url, ","); trimmed != url {
+			candidates = append(candidates, srcsetCandidate{url: trimmed})
+			continue
+		}
+
+		start = i
+		depth := 0
+		for ; i < len(value); i++ {
+			if value[i] == '(' {
+				depth++
+			} else if value[i] == ')' && depth > 0 {
+				depth--
+			} else if value[i] == ',' && depth == 0 {
+				break
+			}
+		}
+		candidates = append(candidates, srcsetCandidate{url: url, descriptors: strings.Join(strings.Fields(value[start:i]), " ")})
+	}
+	return candidates
+}
+
+// EmbedCSSImages inlines url(...) references inside <style> blocks and style attributes.
+func EmbedCSSImages(htmlContent, baseDir string) string {
+	return embedder{baseDir: baseDir}.embedCSS(htmlContent)
+}
+
+func (e embedder) embedCSS(htmlContent string) string {
+	replace := e.embedCSSURLs
+
+	result := styleBlockRegex.ReplaceAllStringFunc(htmlContent, replace)
+	return styleAttrRegex.ReplaceAllStringFunc(result, replace)
+}
+
+// EmbedCSSURLs inlines the url(...) references of a stylesheet, such as images
+// and @font-face fonts, relative to baseDir.
+func EmbedCSSURLs(css, baseDir string) string {
+	return embedder{baseDir: baseDir}.embedCSSURLs(css)
+}
+
+func (e embedder) embedCSSURLs(css string) string {
+	return cssURLRegex.ReplaceAllStringFunc(css, func(ref string) string {
+		m := cssURLRegex.FindStringSubmatch(ref)
+		srcPath := m[1] + m[2] + m[3]
+		if IsAbsoluteOrDataURL(srcPath) || strings.HasPrefix(srcPath, "#") {
+			return ref
+		}
+
+		dataURL, err := e.dataURL(srcPath)
+		if err != nil {
+			log.Printf("Warning: failed to embed CSS image %s: %v", srcPath, err)
+			if e.confined {
+				return fmt.Sprintf("url('%s')", blockedURL)
+			}
+			return ref
+		}
+
+		return fmt.Sprintf("url('%s')", dataURL)
+	})
 }
 
 // ExtractSrcAttribute extracts the src value from an img tag.
@@ -61,8 +259,11 @@ func IsAbsoluteOrDataURL(url string) bool {
 
 // ImageToDataURL reads an image and converts it to a base64 data URL.
 func ImageToDataURL(srcPath, baseDir string) (string, error) {
-	imagePath := filepath.Join(baseDir, srcPath)
+	return fileToDataURL(LocalPath(srcPath, baseDir))
+}
 
+// fileToDataURL reads a file into a base64 data URL
+func fileToDataURL(imagePath string) (string, error) {
 	imageData, err := os.ReadFile(imagePath)
 	if err != nil {
 		return "", err
@@ -74,6 +275,60 @@ func ImageToDataURL(srcPath, baseDir string) (string, error) {
 	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64Data), nil
 }
 
+// LocalPath returns the file a relative image path or file:// URL refers to.
+func LocalPath(srcPath, baseDir string) string {
+	if p, ok := fileurl.Path(srcPath); ok {
+		return p
+	}
+	// Markdown renderers percent-encode paths, e.g. spaces as %20
+	if unescaped, err := url.PathUnescape(srcPath); err == nil {
+		srcPath = unescaped
+	}
+	// Windows paths such as C:\docs\logo.png are used as they are
+	if filepath.VolumeName(srcPath) != "" {
+		return srcPath
+	}
+	return filepath.Join(baseDir, srcPath)
+}
+
+// ConfinedPath returns the file a relative image path refers to, like
+// LocalPath, but only inside baseDir: absolute and Windows volume paths,
+// file: URLs, paths leaving baseDir, and symlinks pointing out of it are
+// rejected, and so is every path when baseDir is empty.
+func ConfinedPath(srcPath, baseDir string) (string, error) {
+	if baseDir == "" {
+		return "", fmt.Errorf("%s: no base directory to resolve it in", srcPath)
+	}
+	if strings.HasPrefix(strings.ToLower(srcPath), "file:") {
+		return "", fmt.Errorf("%s: file URLs are not allowed", srcPath)
+	}
+	p := srcPath
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+	if filepath.IsAbs(p) || filepath.VolumeName(p) != "" || driveRegex.MatchString(p) || strings.HasPrefix(p, "/") || strings.HasPrefix(p, `\`) {
+		return "", fmt.Errorf("%s: absolute paths are not allowed", srcPath)
+	}
+
+	path := filepath.Join(baseDir, p)
+	if !inside(baseDir, path) {
+		return "", fmt.Errorf("%s: outside %s", srcPath, baseDir)
+	}
+	// A symlink inside baseDir may still point out of it
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		if realBase, err := filepath.EvalSymlinks(baseDir); err != nil || !inside(realBase, real) {
+			return "", fmt.Errorf("%s: outside %s", srcPath, baseDir)
+		}
+	}
+	return path, nil
+}
+
+// inside reports whether path is within dir
+func inside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
 // GetMimeType determines the MIME type from file extension.
 func GetMimeType(filePath string) string {
 	ext := strings.ToLower(filepath.Ext(filePath))
@@ -85,12 +340,16 @@ func GetMimeType(filePath string) string {
 
 	// Fallback to common types
 	mimeTypes := map[string]string{
-		".png":  "image/png",
-		".jpg":  "image/jpeg",
-		".jpeg": "image/jpeg",
-		".gif":  "image/gif",
-		".svg":  "image/svg+xml",
-		".webp": "image/webp",
+		".png":   "image/png",
+		".jpg":   "image/jpeg",
+		".jpeg":  "image/jpeg",
+		".gif":   "image/gif",
+		".svg":   "image/svg+xml",
+		".webp":  "image/webp",
+		".woff":  "font/woff",
+		".woff2": "font/woff2",
+		".ttf":   "font/ttf",
+		".otf":   "font/otf",
 	}
 
 	if mt, ok := mimeTypes[ext]; ok {
@@ -100,6 +359,22 @@ func GetMimeType(filePath string) string {
 	return "image/png" // default
 }
 
+// placeholderStyle keeps the placeholder visible with any template
+const placeholderStyle = "display:inline-block;border:2px dashed #d73a49;color:#d73a49;padding:0.5em 1em;font-size:0.9em"
+
+// ReplaceMissing replaces the images still referring to local files, which
+// couldn't be embedded, with a visible "Missing image: path" box.
+func ReplaceMissing(htmlContent string) string {
+	return imgRegex.ReplaceAllStringFunc(htmlContent, func(imgTag string) string {
+		srcPath := ExtractSrcAttribute(imgTag)
+		if srcPath == "" || IsAbsoluteOrDataURL(srcPath) {
+			return imgTag
+		}
+		return fmt.Sprintf(`<span class="missing-image" style="%s">Missing image: %s</span>`,
+			placeholderStyle, html.EscapeString(html.UnescapeString(srcPath)))
+	})
+}
+
 // ReplaceSrcAttribute replaces the src attribute in an img tag.
 func ReplaceSrcAttribute(imgTag, newSrc string) string {
 	return srcRegex.ReplaceAllString(imgTag, fmt.Sprintf(`src="%s"`, newSrc))
@@ -0,0 +1,106 @@
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// confinedDir returns a base directory holding logo.png, next to a secret.txt
+// outside of it
+func confinedDir(t *testing.T) (baseDir, secret string) {
+	t.Helper()
+	root := t.TempDir()
+	baseDir = filepath.Join(root, "docs")
+	if err := os.Mkdir(baseDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "logo.png"), []byte("logo"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	secret = filepath.Join(root, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return baseDir, secret
+}
+
+func TestConfinedPath(t *testing.T) {
+	baseDir, secret := confinedDir(t)
+	if err := os.Symlink(secret, filepath.Join(baseDir, "link.png")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		baseDir string
+		ok      bool
+	}{
+		{"relative path", "logo.png", baseDir, true},
+		{"subfolder back inside", "img/../logo.png", baseDir, true},
+		{"absolute path", secret, baseDir, false},
+		{"escaped absolute path", "%2Fetc%2Fhostname", baseDir, false},
+		{"windows volume path", `C:\Windows\win.ini`, baseDir, false},
+		{"windows drive-relative path", "C:win.ini", baseDir, false},
+		{"windows UNC path", `\\server\share\a.png`, baseDir, false},
+		{"file URL", "file://" + filepath.ToSlash(secret), baseDir, false},
+		{"file URL in capitals", "FILE:///etc/hostname", baseDir, false},
+		{"parent directory", "../secret.txt", baseDir, false},
+		{"deep parent directory", "../../../../../../etc/hostname", baseDir, false},
+		{"escaped parent directory", "..%2Fsecret.txt", baseDir, false},
+		{"symlink out of base directory", "link.png", baseDir, false},
+		{"no base directory", "logo.png", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConfinedPath(tt.src, tt.baseDir)
+			if tt.ok && err != nil {
+				t.Errorf("ConfinedPath(%q) = %v, want it allowed", tt.src, err)
+			}
+			if !tt.ok && err == nil {
+				t.Errorf("ConfinedPath(%q) allowed, want an error", tt.src)
+			}
+		})
+	}
+}
+
+func TestEmbedImagesConfined(t *testing.T) {
+	baseDir, secret := confinedDir(t)
+
+	fileURL := "file://" + filepath.ToSlash(secret)
+	tests := []struct {
+		name    string
+		html    string
+		baseDir string
+		ref     string // local reference, which must be gone unless embedded
+		embeds  bool
+	}{
+		{"img inside base directory", `<img src="logo.png">`, baseDir, "logo.png", true},
+		{"img absolute path", `<img src="` + secret + `">`, baseDir, secret, false},
+		{"img parent directory", `<img src="../secret.txt">`, baseDir, "../secret.txt", false},
+		{"img file URL", `<img src="` + fileURL + `">`, baseDir, fileURL, false},
+		{"img without base directory", `<img src="logo.png">`, "", "logo.png", false},
+		{"srcset parent directory", `<img srcset="../secret.txt 1x, ../secret.txt 2x">`, baseDir, "../secret.txt", false},
+		{"srcset absolute path", `<img srcset="` + secret + ` 2x">`, baseDir, secret, false},
+		{"srcset keeps allowed candidates", `<img srcset="logo.png 1x, ../secret.txt 2x">`, baseDir, "../secret.txt", true},
+		{"CSS url parent directory", `<div style="background: url('../secret.txt')"></div>`, baseDir, "../secret.txt", false},
+		{"CSS url absolute path", `<style>p { background: url(` + secret + `) }</style>`, baseDir, secret, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EmbedImagesConfined(tt.html, tt.baseDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if embedded := strings.Contains(got, ";base64,"); embedded != tt.embeds {
+				t.Errorf("EmbedImagesConfined(%q) = %q, want embedded %v", tt.html, got, tt.embeds)
+			}
+			// Chrome would load a reference left in place from the file:// page
+			if strings.Contains(got, tt.ref) {
+				t.Errorf("EmbedImagesConfined(%q) = %q, still referencing %s", tt.html, got, tt.ref)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package licenses
+
+import (
+	"regexp"
+	"strings"
+)
+
+var spaceRegex = regexp.MustCompile(`\s+`)
+
+// signatures identify common licenses by phrases of their text, most
+// specific first
+var signatures = []struct {
+	id      string
+	phrases []string
+}{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"EPL-2.0", []string{"eclipse public license", "2.0"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+	{"CC0-1.0", []string{"cc0 1.0 universal"}},
+	{"Zlib", []string{"this software is provided 'as-is', without any express or implied warranty"}},
+}
+
+// Detect names the license of a license text with its SPDX identifier, or
+// returns "" when it isn't recognized.
+func Detect(text []byte) string {
+	normalized := spaceRegex.ReplaceAllString(strings.ToLower(string(text)), " ")
+	for _, s := range signatures {
+		matched := true
+		for _, phrase := range s.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return s.id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package licenses
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// licenseFiles are the names a module's license text is looked up under
+var licenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "COPYING", "License", "license"}
+
+// goMod lists the requirements of a go.mod file. Licenses are detected from
+// the module cache when the modules have been downloaded.
+func goMod(src []byte) []Dependency {
+	cache := goModCache()
+
+	var deps []Dependency
+	block := false
+	for _, line := range strings.Split(string(src), "\n") {
+		line, comment, _ := strings.Cut(line, "//")
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case block && fields[0] == ")":
+			block = false
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			block = true
+			continue
+		case fields[0] == "require" && len(fields) == 3:
+			fields = fields[1:]
+		case !block || len(fields) != 2:
+			continue
+		}
+
+		d := Dependency{Name: unquote(fields[0]), Version: unquote(fields[1]), Scope: "direct"}
+		if strings.TrimSpace(comment) == "indirect" {
+			d.Scope = "indirect"
+		}
+		if cache != "" {
+			d.License = moduleLicense(filepath.Join(cache, escapePath(d.Name)+"@"+escapePath(d.Version)))
+		}
+		deps = append(deps, d)
+	}
+	return deps
+}
+
+// moduleLicense detects the license of a downloaded module
+func moduleLicense(dir string) string {
+	for _, name := range licenseFiles {
+		if text, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			return Detect(text)
+		}
+	}
+	return ""
+}
+
+// goModCache returns the module cache directory, or "" when it can't be found
+func goModCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	if out, err := exec.Command("go", "env", "GOMODCACHE").Output(); err == nil {
+		return strings.TrimSpace(string(out))
+	}
+	if gopath := os.Getenv("GOPATH"); gopath != "" {
+		return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, "go", "pkg", "mod")
+	}
+	return ""
+}
+
+// escapePath escapes upper-case letters as the module cache does, so
+// github.com/BurntSushi is stored as github.com/!burnt!sushi
+func escapePath(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return filepath.FromSlash(b.String())
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, "\"`")
+}
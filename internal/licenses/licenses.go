@@ -0,0 +1,188 @@
+// Package licenses lists third-party dependencies and their licenses from
+// go.mod, package.json, package-lock.json, and CycloneDX or SPDX JSON SBOMs.
+package licenses
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Unknown is the license of a dependency whose license wasn't found
+const Unknown = "Unknown"
+
+// Dependency is one third-party package.
+type Dependency struct {
+	Name    string
+	Version string
+	License string // SPDX identifier or expression where known, else Unknown
+	Scope   string // direct, indirect, dev, optional, or empty when the source doesn't say
+	Source  string // file the dependency was read from
+}
+
+// Read lists the dependencies declared in a file, picking the format from
+// its name or, for other JSON files, its content.
+func Read(path string) ([]Dependency, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []Dependency
+	switch name := filepath.Base(path); {
+	case name == "go.mod":
+		deps = goMod(src)
+	case name == "package.json":
+		deps, err = packageJSON(src, filepath.Dir(path))
+	case name == "package-lock.json" || name == "npm-shrinkwrap.json":
+		deps, err = packageLock(src)
+	case strings.EqualFold(filepath.Ext(name), ".json"):
+		deps, err = sbom(src)
+	default:
+		return nil, fmt.Errorf("%s: unsupported dependency file; use go.mod, package.json, package-lock.json, or a CycloneDX or SPDX JSON SBOM", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i := range deps {
+		deps[i].Source = path
+		if deps[i].License == "" {
+			deps[i].License = Unknown
+		}
+	}
+	return deps, nil
+}
+
+// Merge combines dependency lists, keeping the first entry of each name and
+// version and filling in licenses later lists know, sorted by name.
+func Merge(lists ...[]Dependency) []Dependency {
+	var out []Dependency
+	index := make(map[string]int)
+	for _, list := range lists {
+		for _, d := range list {
+			key := d.Name + "@" + d.Version
+			if i, ok := index[key]; ok {
+				if out[i].License == Unknown {
+					out[i].License = d.License
+				}
+				continue
+			}
+			index[key] = len(out)
+			out = append(out, d)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Dependency) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)), cmp.Compare(a.Version, b.Version))
+	})
+	return out
+}
+
+// packageJSON lists the dependencies of an npm package, reading licenses
+// from node_modules when it is installed next to it
+func packageJSON(src []byte, dir string) ([]Dependency, error) {
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal(src, &pkg); err != nil {
+		return nil, fmt.Errorf("parse package.json: %w", err)
+	}
+
+	var deps []Dependency
+	for _, group := range []struct{ key, scope string }{
+		{"dependencies", "direct"},
+		{"devDependencies", "dev"},
+		{"optionalDependencies", "optional"},
+	} {
+		var versions map[string]string
+		if raw, ok := pkg[group.key]; ok {
+			if err := json.Unmarshal(raw, &versions); err != nil {
+				return nil, fmt.Errorf("parse %s: %w", group.key, err)
+			}
+		}
+		for name, version := range versions {
+			d := Dependency{Name: name, Version: version, Scope: group.scope}
+			if installed, err := os.ReadFile(filepath.Join(dir, "node_modules", filepath.FromSlash(name), "package.json")); err == nil {
+				var meta struct {
+					Version string          `json:"version"`
+					License json.RawMessage `json:"license"`
+				}
+				if json.Unmarshal(installed, &meta) == nil {
+					d.Version = cmp.Or(meta.Version, d.Version)
+					d.License = npmLicense(meta.License)
+				}
+			}
+			deps = append(deps, d)
+		}
+	}
+	return deps, nil
+}
+
+// packageLock lists every package installed by an npm lockfile
+func packageLock(src []byte) ([]Dependency, error) {
+	type entry struct {
+		Version      string           `json:"version"`
+		License      json.RawMessage  `json:"license"`
+		Dev          bool             `json:"dev"`
+		Optional     bool             `json:"optional"`
+		Dependencies map[string]entry `json:"dependencies"`
+	}
+	var lock struct {
+		Packages     map[string]entry `json:"packages"`
+		Dependencies map[string]entry `json:"dependencies"`
+	}
+	if err := json.Unmarshal(src, &lock); err != nil {
+		return nil, fmt.Errorf("parse lockfile: %w", err)
+	}
+
+	scope := func(e entry) string {
+		switch {
+		case e.Dev:
+			return "dev"
+		case e.Optional:
+			return "optional"
+		}
+		return ""
+	}
+
+	var deps []Dependency
+	if len(lock.Packages) > 0 {
+		// Lockfile v2 and v3 key packages by their node_modules path
+		for path, e := range lock.Packages {
+			i := strings.LastIndex(path, "node_modules/")
+			if i < 0 {
+				continue // the root package or a workspace
+			}
+			deps = append(deps, Dependency{Name: path[i+len("node_modules/"):], Version: e.Version, License: npmLicense(e.License), Scope: scope(e)})
+		}
+		return deps, nil
+	}
+
+	// Lockfile v1 nests dependencies and records no licenses
+	var walk func(map[string]entry)
+	walk = func(entries map[string]entry) {
+		for name, e := range entries {
+			deps = append(deps, Dependency{Name: name, Version: e.Version, Scope: scope(e)})
+			walk(e.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return deps, nil
+}
+
+// npmLicense reads a package.json license, a string or a legacy {type} object
+func npmLicense(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var legacy struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(raw, &legacy) == nil {
+		return legacy.Type
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package licenses
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type cycloneDXComponent struct {
+	Name     string `json:"name"`
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Scope    string `json:"scope"`
+	Licenses []struct {
+		License struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type spdxPackage struct {
+	ID               string `json:"SPDXID"`
+	Name             string `json:"name"`
+	Version          string `json:"versionInfo"`
+	LicenseConcluded string `json:"licenseConcluded"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+}
+
+// sbom lists the components of a CycloneDX or SPDX JSON document
+func sbom(src []byte) ([]Dependency, error) {
+	var doc struct {
+		BOMFormat  string               `json:"bomFormat"`
+		Components []cycloneDXComponent `json:"components"`
+
+		SPDXVersion string        `json:"spdxVersion"`
+		Describes   []string      `json:"documentDescribes"`
+		Packages    []spdxPackage `json:"packages"`
+	}
+	if err := json.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("parse SBOM: %w", err)
+	}
+
+	var deps []Dependency
+	switch {
+	case strings.EqualFold(doc.BOMFormat, "CycloneDX"):
+		var walk func([]cycloneDXComponent)
+		walk = func(components []cycloneDXComponent) {
+			for _, c := range components {
+				name := c.Name
+				if c.Group != "" {
+					name = c.Group + "/" + c.Name
+				}
+				var ids []string
+				for _, l := range c.Licenses {
+					if id := cmp.Or(l.Expression, l.License.ID, l.License.Name); id != "" {
+						ids = append(ids, id)
+					}
+				}
+				scope := c.Scope
+				if scope == "required" {
+					scope = ""
+				}
+				deps = append(deps, Dependency{Name: name, Version: c.Version, License: strings.Join(ids, " OR "), Scope: scope})
+				walk(c.Components)
+			}
+		}
+		walk(doc.Components)
+
+	case doc.SPDXVersion != "":
+		for _, p := range doc.Packages {
+			// The packages the document describes are the product itself
+			if slices.Contains(doc.Describes, p.ID) {
+				continue
+			}
+			license := p.LicenseConcluded
+			if spdxNone(license) {
+				license = p.LicenseDeclared
+			}
+			if spdxNone(license) {
+				license = ""
+			}
+			deps = append(deps, Dependency{Name: p.Name, Version: p.Version, License: license})
+		}
+
+	default:
+		return nil, fmt.Errorf("not a CycloneDX or SPDX JSON SBOM")
+	}
+	return deps, nil
+}
+
+func spdxNone(license string) bool {
+	return license == "" || license == "NOASSERTION" || license == "NONE"
+}
@@ -0,0 +1,242 @@
+// Package mail sends messages with file attachments over SMTP.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server is an SMTP server and the account messages are sent from.
+type Server struct {
+	Host     string
+	Port     int // 465 uses implicit TLS; other ports upgrade with STARTTLS when offered
+	Username string
+	Password string
+	From     string
+}
+
+// ServerFromEnv reads the server from SMTP_HOST, SMTP_PORT (default 587),
+// SMTP_USERNAME, SMTP_PASSWORD, and SMTP_FROM (defaults to SMTP_USERNAME).
+func ServerFromEnv() (Server, error) {
+	s := Server{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     587,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return Server{}, fmt.Errorf("invalid SMTP_PORT %q", port)
+		}
+		s.Port = n
+	}
+	if s.From == "" {
+		s.From = s.Username
+	}
+
+	if s.Host == "" {
+		return Server{}, fmt.Errorf("SMTP_HOST is not set")
+	}
+	if _, err := mail.ParseAddress(s.From); err != nil {
+		return Server{}, fmt.Errorf("invalid sender %q (set SMTP_FROM): %w", s.From, err)
+	}
+	return s, nil
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an email with a plain text body.
+type Message struct {
+	To          []string
+	Cc          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// ParseAddresses splits a comma- or semicolon-separated list of addresses and
+// rejects invalid ones.
+func ParseAddresses(list string) ([]string, error) {
+	var addrs []string
+	for _, part := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ';' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(part); err != nil {
+			return nil, fmt.Errorf("invalid address %q", part)
+		}
+		addrs = append(addrs, part)
+	}
+	return addrs, nil
+}
+
+// Send delivers msg through the server.
+func (s Server) Send(msg Message) error {
+	if len(msg.To)+len(msg.Cc) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+
+	data, err := s.build(msg)
+	if err != nil {
+		return err
+	}
+
+	var recipients []string
+	for _, addr := range append(append([]string{}, msg.To...), msg.Cc...) {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("invalid address %q", addr)
+		}
+		recipients = append(recipients, parsed.Address)
+	}
+	from, _ := mail.ParseAddress(s.From)
+
+	client, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("authenticate: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("sender: %w", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("send message: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial connects to the server, using TLS on port 465 and STARTTLS elsewhere when offered
+func (s Server) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	tlsConfig := &tls.Config{ServerName: s.Host}
+
+	var conn net.Conn
+	var err error
+	if s.Port == 465 {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, 30*time.Second)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", addr, err)
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connect to %s: %w", addr, err)
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok && s.Port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("starttls: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// build encodes msg as a MIME message, multipart when it has attachments
+func (s Server) build(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	header("From", s.From)
+	header("To", strings.Join(msg.To, ", "))
+	if len(msg.Cc) > 0 {
+		header("Cc", strings.Join(msg.Cc, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if len(msg.Attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(msg.Body))
+		return buf.Bytes(), nil
+	}
+
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+	header("Content-Type", `multipart/mixed; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	fmt.Fprintf(&buf, "--%s\r\n", boundary)
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: base64\r\n\r\n")
+	writeBase64(&buf, []byte(msg.Body))
+
+	for _, a := range msg.Attachments {
+		name := mime.QEncoding.Encode("utf-8", a.Name)
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; name=%q\r\n", a.ContentType, name)
+		fmt.Fprintf(&buf, "Content-Disposition: attachment; filename=%q\r\n", name)
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		writeBase64(&buf, a.Data)
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+// newBoundary returns a random MIME boundary
+func newBoundary() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate boundary: %w", err)
+	}
+	return "part-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,201 @@
+// Package manifest describes generated artifacts in a machine-readable index.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Version is the manifest schema version.
+const Version = 1
+
+// Artifact describes a single generated file.
+type Artifact struct {
+	Output      string     `json:"output"`
+	Kind        string     `json:"kind"` // pdf | zip | sourcemap
+	Sources     []string   `json:"sources,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	SHA256      string     `json:"sha256"`
+	Size        int64      `json:"size"`
+	Pages       int        `json:"pages,omitempty"`
+	Job         string     `json:"job,omitempty"`
+	URL         string     `json:"url,omitempty"` // public URL once published
+	Resources   []Resource `json:"resources,omitempty"`
+	Warnings    []string   `json:"warnings,omitempty"` // page errors while printing, e.g. a failed diagram script
+	GeneratedAt time.Time  `json:"generated_at"`
+}
+
+// Resource is a request a document's page made while it was printed.
+type Resource struct {
+	URL      string `json:"url"`
+	Type     string `json:"type,omitempty"`   // Image, Stylesheet, Font, Script, ...
+	Status   int    `json:"status,omitempty"` // HTTP status; absent for local files and failures
+	Bytes    int64  `json:"bytes"`
+	External bool   `json:"external,omitempty"` // loaded over the network
+	Error    string `json:"error,omitempty"`
+}
+
+// Manifest is the index of all artifacts produced by a run.
+type Manifest struct {
+	Version     int        `json:"version"`
+	GeneratedAt time.Time  `json:"generated_at"`
+	Artifacts   []Artifact `json:"artifacts"`
+}
+
+// Recorder collects artifacts from concurrent jobs.
+type Recorder struct {
+	mu        sync.Mutex
+	artifacts []Artifact
+	fixedTime time.Time
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// SetTime fixes every generated_at timestamp to t, for reproducible manifests.
+func (r *Recorder) SetTime(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixedTime = t.UTC()
+}
+
+// now returns the fixed time if one is set, otherwise the current time.
+func (r *Recorder) now() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.fixedTime.IsZero() {
+		return r.fixedTime
+	}
+	return time.Now().UTC()
+}
+
+// Add records an artifact.
+func (r *Recorder) Add(a Artifact) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.artifacts = append(r.artifacts, a)
+}
+
+// Job returns the artifacts recorded so far for the named job.
+func (r *Recorder) Job(name string) []Artifact {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var artifacts []Artifact
+	for _, a := range r.artifacts {
+		if a.Job == name {
+			artifacts = append(artifacts, a)
+		}
+	}
+	return artifacts
+}
+
+// SetURL records the public URL of the artifact written to output.
+func (r *Recorder) SetURL(output, url string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.artifacts {
+		if r.artifacts[i].Output == output {
+			r.artifacts[i].URL = url
+		}
+	}
+}
+
+// AddFile records an artifact for a file on disk, filling in its checksum and size.
+func (r *Recorder) AddFile(a Artifact) error {
+	sum, size, err := Checksum(a.Output)
+	if err != nil {
+		return err
+	}
+	a.SHA256 = sum
+	a.Size = size
+	if a.GeneratedAt.IsZero() {
+		a.GeneratedAt = r.now()
+	}
+	r.Add(a)
+	return nil
+}
+
+// Manifest returns the recorded artifacts sorted by output path.
+func (r *Recorder) Manifest() Manifest {
+	r.mu.Lock()
+	artifacts := append([]Artifact(nil), r.artifacts...)
+	r.mu.Unlock()
+
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Output < artifacts[j].Output
+	})
+
+	return Manifest{
+		Version:     Version,
+		GeneratedAt: r.now(),
+		Artifacts:   artifacts,
+	}
+}
+
+// Write saves the recorded artifacts as JSON to path.
+func (r *Recorder) Write(path string) error {
+	return Save(r.Manifest(), path)
+}
+
+// Save writes a manifest as indented JSON.
+func Save(m Manifest, path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create manifest directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a manifest from path.
+func Load(path string) (Manifest, error) {
+	var m Manifest
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, fmt.Errorf("read manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("parse manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// Checksum returns the hex-encoded SHA-256 and size of a file.
+func Checksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("hash %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,200 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// styleAttributes are attribute keys rendered as inline CSS, since HTML size
+// attributes accept neither percentages nor units
+var styleAttributes = []string{"width", "height", "max-width", "max-height"}
+
+// attributes is the goldmark extension for {#id .class key=value} blocks after
+// headings and images, e.g. ![Diagram](diagram.png){width=50% .center}.
+type attributes struct{}
+
+// Extend implements goldmark.Extender.
+func (a *attributes) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithAttribute(),
+		parser.WithASTTransformers(util.Prioritized(a, 500)),
+	)
+}
+
+// Transform implements parser.ASTTransformer. Heading attributes are parsed by
+// goldmark; image attributes are read from the text that follows the image.
+func (a *attributes) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if img, ok := n.(*ast.Image); ok {
+			parseImageAttributes(img, source)
+		}
+		if n.Type() == ast.TypeBlock || n.Kind() == ast.KindImage {
+			stylizeAttributes(n)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// parseImageAttributes moves a {...} block directly following an image onto it
+func parseImageAttributes(img *ast.Image, source []byte) {
+	next, ok := img.NextSibling().(*ast.Text)
+	if !ok {
+		return
+	}
+
+	line := source[next.Segment.Start:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	attrs, consumed, ok := parseAttributeBlock(line)
+	if !ok {
+		return
+	}
+
+	for _, attr := range attrs {
+		if attr[0] == "class" {
+			if v, ok := img.AttributeString("class"); ok {
+				attr[1] = string(v.([]byte)) + " " + attr[1]
+			}
+		}
+		img.SetAttributeString(attr[0], []byte(attr[1]))
+	}
+
+	// Drop the block from the following text
+	parent := img.Parent()
+	for n := ast.Node(next); consumed > 0 && n != nil; {
+		t, ok := n.(*ast.Text)
+		if !ok {
+			break
+		}
+		following := n.NextSibling()
+		if l := t.Segment.Len(); consumed >= l && t.SoftLineBreak() {
+			// Keep the line break
+			t.Segment = t.Segment.WithStart(t.Segment.Stop)
+			consumed -= l
+		} else if consumed >= l {
+			parent.RemoveChild(parent, t)
+			consumed -= l
+		} else {
+			t.Segment = t.Segment.WithStart(t.Segment.Start + consumed)
+			consumed = 0
+		}
+		n = following
+	}
+}
+
+// parseAttributeBlock parses a leading {#id .class key=value key="quoted value"}
+// block and returns its attributes and length
+func parseAttributeBlock(b []byte) (attrs [][2]string, n int, ok bool) {
+	if len(b) == 0 || b[0] != '{' {
+		return nil, 0, false
+	}
+
+	i := 1
+	for {
+		for i < len(b) && (b[i] == ' ' || b[i] == '\t') {
+			i++
+		}
+		if i >= len(b) {
+			return nil, 0, false
+		}
+		if b[i] == '}' {
+			return attrs, i + 1, len(attrs) > 0
+		}
+
+		start := i
+		for i < len(b) && b[i] != ' ' && b[i] != '\t' && b[i] != '}' && b[i] != '=' {
+			i++
+		}
+		name := string(b[start:i])
+
+		switch {
+		case strings.HasPrefix(name, ".") && len(name) > 1:
+			attrs = append(attrs, [2]string{"class", name[1:]})
+		case strings.HasPrefix(name, "#") && len(name) > 1:
+			attrs = append(attrs, [2]string{"id", name[1:]})
+		case i < len(b) && b[i] == '=' && name != "":
+			i++
+			var value string
+			if i < len(b) && b[i] == '"' {
+				end := bytes.IndexByte(b[i+1:], '"')
+				if end < 0 {
+					return nil, 0, false
+				}
+				value = string(b[i+1 : i+1+end])
+				i += end + 2
+			} else {
+				start := i
+				for i < len(b) && b[i] != ' ' && b[i] != '\t' && b[i] != '}' {
+					i++
+				}
+				value = string(b[start:i])
+			}
+			attrs = append(attrs, [2]string{name, value})
+		default:
+			return nil, 0, false
+		}
+	}
+}
+
+// stylizeAttributes rewrites size attributes as inline CSS, appending to any
+// existing style
+func stylizeAttributes(n ast.Node) {
+	var styles []string
+	for _, key := range styleAttributes {
+		v, ok := n.AttributeString(key)
+		if !ok {
+			continue
+		}
+		value := attributeValue(v)
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			value += "px"
+		}
+		styles = append(styles, key+": "+value)
+		removeAttribute(n, key)
+	}
+	if len(styles) == 0 {
+		return
+	}
+
+	if v, ok := n.AttributeString("style"); ok {
+		styles = slices.Insert(styles, 0, strings.TrimSuffix(attributeValue(v), ";"))
+	}
+	n.SetAttributeString("style", []byte(strings.Join(styles, "; ")))
+}
+
+// attributeValue formats a parsed attribute value
+func attributeValue(v any) string {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// removeAttribute deletes an attribute, which ast.Node cannot do itself
+func removeAttribute(n ast.Node, name string) {
+	attrs := n.Attributes()
+	n.RemoveAttributes()
+	for _, attr := range attrs {
+		if string(attr.Name) != name {
+			n.SetAttribute(attr.Name, attr.Value)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package markdown
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	mathjax "github.com/litao91/goldmark-mathjax"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Options selects the goldmark extensions a converter uses on top of the defaults.
+type Options struct {
+	// Extensions to enable in addition to DefaultExtensions, e.g. "footnote".
+	Enable []string
+
+	// Extensions to leave out, including default ones, e.g. "linkify".
+	Disable []string
+
+	// How the "wikilinks" extension resolves [[Page]] links.
+	Wikilinks WikilinkOptions
+}
+
+// DefaultExtensions are enabled unless disabled: GitHub-flavored markdown
+// (table, strikethrough, linkify, tasklist), definition lists, attributes,
+// fenced divs, page breaks, math, and syntax highlighting.
+var DefaultExtensions = []string{"table", "strikethrough", "linkify", "tasklist", "definition_list", "attributes", "fenced_divs", "page_breaks", "math", "highlighting"}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]goldmark.Extender{
+		"table":         extension.Table,
+		"strikethrough": extension.Strikethrough,
+		"linkify":       extension.Linkify,
+		"tasklist":      extension.TaskList,
+		"math":          mathjax.MathJax,
+		"highlighting": highlighting.NewHighlighting(
+			highlighting.WithStyle("github"),
+			highlighting.WithFormatOptions(),
+		),
+		"footnote":        extension.Footnote,
+		"definition_list": extension.DefinitionList,
+		"attributes":      &attributes{},
+		"fenced_divs":     &fencedDivs{},
+		"page_breaks":     &pageBreaks{},
+		"typographer":     extension.Typographer,
+		"cjk":             extension.CJK,
+		"wikilinks":       &wikilinks{},
+		"figures":         &figures{},
+		"table_captions":  &tableCaptions{},
+	}
+)
+
+// Register makes a goldmark extension available to Options under name,
+// replacing any extension registered with the same name. Call it before
+// converters are created, typically from an init function.
+func Register(name string, ext goldmark.Extender) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = ext
+}
+
+// Available returns the names of all registered extensions, sorted.
+func Available() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return availableLocked()
+}
+
+// availableLocked lists the registered names; the caller holds registryMu.
+func availableLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate reports an error if the options name an unregistered extension
+// or configure wikilinks incorrectly.
+func (o Options) Validate() error {
+	if _, err := o.extensions(); err != nil {
+		return err
+	}
+	return o.Wikilinks.Validate()
+}
+
+// extensions resolves the options to goldmark extenders in a stable order.
+func (o Options) extensions() ([]goldmark.Extender, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, name := range append(slices.Clone(o.Enable), o.Disable...) {
+		if _, ok := registry[name]; !ok {
+			return nil, fmt.Errorf("unknown markdown extension %q (available: %s)", name, strings.Join(availableLocked(), ", "))
+		}
+	}
+
+	var names []string
+	for _, name := range append(slices.Clone(DefaultExtensions), o.Enable...) {
+		if !slices.Contains(o.Disable, name) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	exts := make([]goldmark.Extender, len(names))
+	for i, name := range names {
+		exts[i] = registry[name]
+		if name == "wikilinks" {
+			exts[i] = &wikilinks{opts: o.Wikilinks}
+		}
+	}
+	return exts, nil
+}
+
+// key identifies the effective option set, for caching converters.
+func (o Options) key() string {
+	enable := slices.Clone(o.Enable)
+	disable := slices.Clone(o.Disable)
+	sort.Strings(enable)
+	sort.Strings(disable)
+	return fmt.Sprintf("%s|%s|%+v", strings.Join(enable, ","), strings.Join(disable, ","), o.Wikilinks)
+}
@@ -0,0 +1,199 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindFencedDiv is the node kind of a ::: container.
+var KindFencedDiv = ast.NewNodeKind("FencedDiv")
+
+// FencedDiv is a pandoc-style container rendered as a <div>.
+type FencedDiv struct {
+	ast.BaseBlock
+
+	// Nested containers opened inside this one and not yet closed
+	depth int
+
+	// Markers such as ::: pagebreak hold no content
+	leaf bool
+}
+
+// leafClasses are containers that need no closing fence, like ::: pagebreak.
+// A closing fence right after them is accepted too.
+var leafClasses = map[string]bool{"pagebreak": true}
+
+// Kind implements ast.Node.
+func (n *FencedDiv) Kind() ast.NodeKind { return KindFencedDiv }
+
+// Dump implements ast.Node.
+func (n *FencedDiv) Dump(source []byte, level int) { ast.DumpHelper(n, source, level, nil, nil) }
+
+// fencedDivs is the goldmark extension for pandoc-style containers: a line of
+// three or more colons followed by a class name or an attribute block opens a
+// <div>, and a line of colons alone closes it.
+//
+//	::: two-column
+//	Content laid out in two columns.
+//	:::
+//
+//	::: {.note #setup}
+//	Containers can nest and hold any markdown.
+//	:::
+type fencedDivs struct{}
+
+// Extend implements goldmark.Extender.
+func (f *fencedDivs) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(util.Prioritized(f, 750)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(f, 500)))
+}
+
+// Trigger implements parser.BlockParser.
+func (f *fencedDivs) Trigger() []byte {
+	return []byte{':'}
+}
+
+// Open implements parser.BlockParser.
+func (f *fencedDivs) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	attrs, ok := parseFenceOpener(line)
+	if !ok {
+		return nil, parser.NoChildren
+	}
+
+	div := &FencedDiv{}
+	for _, attr := range attrs {
+		if attr[0] == "class" {
+			div.leaf = div.leaf || leafClasses[attr[1]]
+			if v, ok := div.AttributeString("class"); ok {
+				attr[1] = string(v.([]byte)) + " " + attr[1]
+			}
+		}
+		div.SetAttributeString(attr[0], []byte(attr[1]))
+	}
+
+	reader.Advance(segment.Len() - trailingNewline(line))
+	if div.leaf {
+		return div, parser.NoChildren
+	}
+	return div, parser.HasChildren
+}
+
+// Continue implements parser.BlockParser. Every open container sees the lines of
+// the containers nested in it, so each one counts the openers it has seen to
+// leave their closing fences to them.
+func (f *fencedDivs) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	div := node.(*FencedDiv)
+	line, segment := reader.PeekLine()
+
+	if div.leaf {
+		if isFenceCloser(line) {
+			reader.Advance(segment.Len() - trailingNewline(line))
+		}
+		return parser.Close
+	}
+
+	if _, ok := parseFenceOpener(line); ok {
+		div.depth++
+		return parser.Continue | parser.HasChildren
+	}
+	if !isFenceCloser(line) {
+		return parser.Continue | parser.HasChildren
+	}
+	if div.depth > 0 {
+		div.depth--
+		return parser.Continue | parser.HasChildren
+	}
+
+	reader.Advance(segment.Len() - trailingNewline(line))
+	return parser.Close
+}
+
+// Close implements parser.BlockParser.
+func (f *fencedDivs) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.
+func (f *fencedDivs) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.
+func (f *fencedDivs) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// parseFenceOpener parses ":::: name", "::: {.name #id}", or "::: name :::"
+// into attributes
+func parseFenceOpener(line []byte) ([][2]string, bool) {
+	rest, ok := fenceRest(line)
+	if !ok {
+		return nil, false
+	}
+	rest = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), ":"))
+	if rest == "" {
+		return nil, false
+	}
+
+	if strings.HasPrefix(rest, "{") {
+		attrs, n, ok := parseAttributeBlock([]byte(rest))
+		if !ok || strings.TrimSpace(rest[n:]) != "" {
+			return nil, false
+		}
+		return attrs, true
+	}
+	if strings.ContainsAny(rest, " \t{}") {
+		return nil, false
+	}
+	return [][2]string{{"class", rest}}, true
+}
+
+// isFenceCloser reports whether a line is three or more colons alone
+func isFenceCloser(line []byte) bool {
+	rest, ok := fenceRest(line)
+	return ok && strings.TrimSpace(rest) == ""
+}
+
+// fenceRest returns what follows a leading run of three or more colons
+func fenceRest(line []byte) (string, bool) {
+	trimmed := bytes.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return "", false
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == ':' {
+		n++
+	}
+	if n < 3 {
+		return "", false
+	}
+	return string(trimmed[n:]), true
+}
+
+func trailingNewline(line []byte) int {
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		return 1
+	}
+	return 0
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (f *fencedDivs) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindFencedDiv, func(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.WriteString("<div")
+			html.RenderAttributes(w, n, nil)
+			_, _ = w.WriteString(">\n")
+		} else {
+			_, _ = w.WriteString("</div>\n")
+		}
+		return ast.WalkContinue, nil
+	})
+}
@@ -0,0 +1,161 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindFigure is the node kind of a captioned image.
+var KindFigure = ast.NewNodeKind("Figure")
+
+// KindFigureCaption is the node kind of a figure's caption.
+var KindFigureCaption = ast.NewNodeKind("FigureCaption")
+
+// Figure is a block holding an image and its FigureCaption.
+type Figure struct {
+	ast.BaseBlock
+}
+
+// Kind implements ast.Node.
+func (n *Figure) Kind() ast.NodeKind { return KindFigure }
+
+// Dump implements ast.Node.
+func (n *Figure) Dump(source []byte, level int) { ast.DumpHelper(n, source, level, nil, nil) }
+
+// FigureCaption holds the inline content of a figure's caption.
+type FigureCaption struct {
+	ast.BaseBlock
+}
+
+// Kind implements ast.Node.
+func (n *FigureCaption) Kind() ast.NodeKind { return KindFigureCaption }
+
+// Dump implements ast.Node.
+func (n *FigureCaption) Dump(source []byte, level int) { ast.DumpHelper(n, source, level, nil, nil) }
+
+// figures is the goldmark extension turning an image alone in its paragraph
+// into a <figure> when it has a caption: the image title, or an emphasized line
+// directly below it.
+//
+//	![Architecture](diagram.png "Request flow")
+//
+//	![Architecture](diagram.png)
+//	*Request flow*
+type figures struct{}
+
+// Extend implements goldmark.Extender.
+func (f *figures) Extend(m goldmark.Markdown) {
+	// After attributes, so {...} blocks are already attached to the images
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(f, 600)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(f, 500)))
+}
+
+// Transform implements parser.ASTTransformer.
+func (f *figures) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	var paragraphs []*ast.Paragraph
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if p, ok := n.(*ast.Paragraph); ok && entering {
+			paragraphs = append(paragraphs, p)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, p := range paragraphs {
+		if p.Parent() == nil {
+			continue // a caption paragraph merged into the figure before it
+		}
+		makeFigure(p, source)
+	}
+}
+
+// makeFigure replaces p with a figure if it holds a captioned image
+func makeFigure(p *ast.Paragraph, source []byte) {
+	var img *ast.Image
+	var caption ast.Node
+	for c := p.FirstChild(); c != nil; c = c.NextSibling() {
+		switch n := c.(type) {
+		case *ast.Image:
+			if img != nil {
+				return
+			}
+			img = n
+		case *ast.Emphasis:
+			if img == nil || caption != nil {
+				return
+			}
+			caption = n
+		case *ast.Text:
+			if !isBlank(n, source) {
+				return
+			}
+		default:
+			return
+		}
+	}
+	if img == nil {
+		return
+	}
+
+	// An emphasized paragraph right below the image
+	next, _ := p.NextSibling().(*ast.Paragraph)
+	if caption == nil && next != nil && next.ChildCount() == 1 {
+		if emph, ok := next.FirstChild().(*ast.Emphasis); ok {
+			caption = emph
+			next.Parent().RemoveChild(next.Parent(), next)
+		}
+	}
+
+	figCaption := &FigureCaption{}
+	switch {
+	case caption != nil:
+		for c := caption.FirstChild(); c != nil; {
+			following := c.NextSibling()
+			figCaption.AppendChild(figCaption, c)
+			c = following
+		}
+	case len(img.Title) > 0:
+		figCaption.AppendChild(figCaption, ast.NewString(img.Title))
+	default:
+		return
+	}
+
+	fig := &Figure{}
+	fig.SetAttributeString("class", []byte("figure"))
+	p.Parent().ReplaceChild(p.Parent(), p, fig)
+	fig.AppendChild(fig, img)
+	fig.AppendChild(fig, figCaption)
+}
+
+// isBlank reports whether a text node holds only whitespace
+func isBlank(t *ast.Text, source []byte) bool {
+	return strings.TrimSpace(string(t.Segment.Value(source))) == ""
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (f *figures) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindFigure, func(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.WriteString(`<figure class="figure">` + "\n")
+		} else {
+			_, _ = w.WriteString("</figure>\n")
+		}
+		return ast.WalkContinue, nil
+	})
+	reg.Register(KindFigureCaption, func(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.WriteString("\n<figcaption>")
+		} else {
+			_, _ = w.WriteString("</figcaption>\n")
+		}
+		return ast.WalkContinue, nil
+	})
+}
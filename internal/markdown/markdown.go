@@ -3,13 +3,14 @@ package markdown
 
 import (
 	"bytes"
+	"fmt"
+	"sync"
 
-	mathjax "github.com/litao91/goldmark-mathjax"
 	"github.com/yuin/goldmark"
-	highlighting "github.com/yuin/goldmark-highlighting/v2"
-	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
 )
 
 // Converter handles markdown to HTML conversion.
@@ -19,24 +20,62 @@ type Converter struct {
 
 // DefaultConverter returns a converter with sensible defaults for GitHub-flavored markdown.
 func DefaultConverter() *Converter {
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,
-			mathjax.MathJax,
-			highlighting.NewHighlighting(
-				highlighting.WithStyle("github"),
-				highlighting.WithFormatOptions(),
-			),
-		),
+	c, _ := NewConverterWithOptions(Options{}, true)
+	return c
+}
+
+// SafeConverter returns a converter like DefaultConverter that omits raw HTML
+// from the output, for rendering untrusted markdown.
+func SafeConverter() *Converter {
+	c, _ := NewConverterWithOptions(Options{}, false)
+	return c
+}
+
+// NewConverterWithOptions returns a converter with the extensions selected by
+// opts, optionally passing raw HTML through.
+func NewConverterWithOptions(opts Options, allowRawHTML bool) (*Converter, error) {
+	exts, err := opts.extensions()
+	if err != nil {
+		return nil, err
+	}
+
+	var rendererOpts []renderer.Option
+	if allowRawHTML {
+		rendererOpts = append(rendererOpts, html.WithUnsafe())
+	}
+
+	return &Converter{md: goldmark.New(
+		goldmark.WithExtensions(exts...),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
+			parser.WithASTTransformers(util.Prioritized(namespacer{}, 0)),
 		),
-		goldmark.WithRendererOptions(
-			html.WithUnsafe(),
-		),
-	)
+		goldmark.WithRendererOptions(rendererOpts...),
+	)}, nil
+}
 
-	return &Converter{md: md}
+var (
+	cacheMu    sync.Mutex
+	converters = map[string]*Converter{}
+)
+
+// CachedConverter is like NewConverterWithOptions but reuses converters
+// built for the same options.
+func CachedConverter(opts Options, allowRawHTML bool) (*Converter, error) {
+	key := fmt.Sprintf("%s|%t", opts.key(), allowRawHTML)
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if c, ok := converters[key]; ok {
+		return c, nil
+	}
+	c, err := NewConverterWithOptions(opts, allowRawHTML)
+	if err != nil {
+		return nil, err
+	}
+	converters[key] = c
+	return c, nil
 }
 
 // NewConverter creates a converter with a custom goldmark instance.
@@ -53,6 +92,19 @@ func (c *Converter) ToHTML(src []byte) (string, error) {
 	return buf.String(), nil
 }
 
+// ToHTMLIn converts markdown read from dir, which relative references such as
+// wikilinks are resolved from.
+func (c *Converter) ToHTMLIn(src []byte, dir string) (string, error) {
+	pc := parser.NewContext()
+	pc.Set(docDirKey, dir)
+
+	var buf bytes.Buffer
+	if err := c.md.Convert(src, &buf, parser.WithContext(pc)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 // ToHTMLBytes converts markdown content to HTML bytes.
 func (c *Converter) ToHTMLBytes(src []byte) ([]byte, error) {
 	var buf bytes.Buffer
@@ -0,0 +1,99 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// pageBreakMarkers are the lines, alone on a line, that start a new page
+var pageBreakMarkers = [][]byte{
+	[]byte(`\newpage`),
+	[]byte(`\pagebreak`),
+	[]byte(`<!-- pagebreak -->`),
+	[]byte(`<!-- newpage -->`),
+}
+
+// KindPageBreak is the node kind of a page break marker.
+var KindPageBreak = ast.NewNodeKind("PageBreak")
+
+// PageBreak forces the content that follows onto a new page.
+type PageBreak struct {
+	ast.BaseBlock
+}
+
+// Kind implements ast.Node.
+func (n *PageBreak) Kind() ast.NodeKind { return KindPageBreak }
+
+// Dump implements ast.Node.
+func (n *PageBreak) Dump(source []byte, level int) { ast.DumpHelper(n, source, level, nil, nil) }
+
+// pageBreaks is the goldmark extension for LaTeX-style \newpage and
+// <!-- pagebreak --> lines. They render like a ::: pagebreak fenced div.
+type pageBreaks struct{}
+
+// Extend implements goldmark.Extender.
+func (p *pageBreaks) Extend(m goldmark.Markdown) {
+	// Ahead of the HTML block parser, which would keep the comment as is
+	m.Parser().AddOptions(parser.WithBlockParsers(util.Prioritized(p, 100)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(p, 500)))
+}
+
+// Trigger implements parser.BlockParser.
+func (p *pageBreaks) Trigger() []byte {
+	return []byte{'\\', '<'}
+}
+
+// Open implements parser.BlockParser.
+func (p *pageBreaks) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	if !isPageBreakMarker(line) {
+		return nil, parser.NoChildren
+	}
+	reader.Advance(segment.Len() - trailingNewline(line))
+	return &PageBreak{}, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser.
+func (p *pageBreaks) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	return parser.Close
+}
+
+// Close implements parser.BlockParser.
+func (p *pageBreaks) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.
+func (p *pageBreaks) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.
+func (p *pageBreaks) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// isPageBreakMarker reports whether a line holds only a page break marker
+func isPageBreakMarker(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	for _, marker := range pageBreakMarkers {
+		if bytes.EqualFold(line, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (p *pageBreaks) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindPageBreak, func(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.WriteString("<div class=\"pagebreak\"></div>\n")
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
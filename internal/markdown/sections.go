@@ -0,0 +1,148 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// Section is the part of combined markdown that came from one source,
+// starting at byte Offset. Its heading IDs are prefixed with Namespace and a
+// hyphen, e.g. "auth-installation", and its links to them are updated, so the
+// same heading in two sources doesn't collide.
+type Section struct {
+	Offset    int
+	Namespace string
+}
+
+// sectionsKey holds the sections of the document being converted
+var sectionsKey = parser.NewContextKey()
+
+// ToHTMLSections converts combined markdown read from dir, namespacing the
+// heading IDs of each section.
+func (c *Converter) ToHTMLSections(src []byte, dir string, sections []Section) (string, error) {
+	pc := parser.NewContext()
+	pc.Set(docDirKey, dir)
+	pc.Set(sectionsKey, sections)
+
+	var buf bytes.Buffer
+	if err := c.md.Convert(src, &buf, parser.WithContext(pc)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// dedupedIDRegex matches the suffix goldmark adds to repeated heading IDs
+var dedupedIDRegex = regexp.MustCompile(`-\d+$`)
+
+// namespacer is the AST transformer applying the sections of a document
+type namespacer struct{}
+
+func (namespacer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	sections, _ := pc.Get(sectionsKey).([]Section)
+	if len(sections) == 0 {
+		return
+	}
+	source := reader.Source()
+
+	// The IDs of each section, as goldmark generated them and as written
+	// before goldmark made repeated ones unique, mapped to the new IDs
+	renamed := make([]map[string]string, len(sections))
+	for i := range renamed {
+		renamed[i] = make(map[string]string)
+	}
+	used := make(map[string]bool)
+
+	current := 0
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			current = sectionAt(sections, n.Lines().At(0).Start)
+		}
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		value, ok := heading.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+		id := string(value.([]byte))
+
+		// Automatic IDs repeated across sources are renamed from the ID the
+		// heading would have alone
+		base := id
+		var line []byte
+		if lines := heading.Lines(); lines.Len() > 0 {
+			last := lines.At(lines.Len() - 1)
+			line = last.Value(source)
+		}
+		if slug := headingID(string(line)); id == slug ||
+			strings.HasPrefix(id, slug) && dedupedIDRegex.MatchString(strings.TrimPrefix(id, slug)) {
+			base = slug
+		}
+		ns := sections[current].Namespace + "-" + base
+		unique := ns
+		for i := 1; used[unique]; i++ {
+			unique = fmt.Sprintf("%s-%d", ns, i)
+		}
+		used[unique] = true
+
+		heading.SetAttributeString("id", []byte(unique))
+		renamed[current][id] = unique
+		if _, ok := renamed[current][base]; !ok {
+			renamed[current][base] = unique
+		}
+		return ast.WalkSkipChildren, nil
+	})
+
+	current = 0
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			current = sectionAt(sections, n.Lines().At(0).Start)
+		}
+		if link, ok := n.(*ast.Link); ok {
+			if fragment, ok := strings.CutPrefix(string(link.Destination), "#"); ok {
+				if id, ok := renamedID(renamed, current, fragment); ok {
+					link.Destination = []byte("#" + id)
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// renamedID returns the new ID a link fragment refers to: a heading of the
+// linking section, or else the first other section with the heading
+func renamedID(renamed []map[string]string, current int, fragment string) (string, bool) {
+	if id, ok := renamed[current][fragment]; ok {
+		return id, true
+	}
+	for _, ids := range renamed {
+		if id, ok := ids[fragment]; ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// sectionAt returns the index of the section holding a source offset
+func sectionAt(sections []Section, offset int) int {
+	i := 0
+	for j, s := range sections {
+		if s.Offset <= offset {
+			i = j
+		}
+	}
+	return i
+}
@@ -0,0 +1,97 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// tableCaptionPrefix starts a caption paragraph following a table
+var tableCaptionPrefix = []byte("Table:")
+
+// KindTableCaption is the node kind of a table's caption.
+var KindTableCaption = ast.NewNodeKind("TableCaption")
+
+// TableCaption holds the inline content of a table's caption. It is the first
+// child of the table.
+type TableCaption struct {
+	ast.BaseBlock
+}
+
+// Kind implements ast.Node.
+func (n *TableCaption) Kind() ast.NodeKind { return KindTableCaption }
+
+// Dump implements ast.Node.
+func (n *TableCaption) Dump(source []byte, level int) { ast.DumpHelper(n, source, level, nil, nil) }
+
+// tableCaptions is the goldmark extension taking a paragraph starting with
+// "Table:" right after a table as the table's caption.
+//
+//	| Quarter | Revenue |
+//	|---------|---------|
+//	| Q1      | 1.2M    |
+//
+//	Table: Revenue by quarter
+type tableCaptions struct{}
+
+// Extend implements goldmark.Extender.
+func (c *tableCaptions) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(c, 600)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(c, 500)))
+}
+
+// Transform implements parser.ASTTransformer.
+func (c *tableCaptions) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	var tables []*east.Table
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := n.(*east.Table); ok && entering {
+			tables = append(tables, t)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, table := range tables {
+		p, ok := table.NextSibling().(*ast.Paragraph)
+		if !ok {
+			continue
+		}
+		first, ok := p.FirstChild().(*ast.Text)
+		if !ok || !bytes.HasPrefix(first.Segment.Value(source), tableCaptionPrefix) {
+			continue
+		}
+
+		// Drop the prefix and the space after it
+		first.Segment = first.Segment.WithStart(first.Segment.Start + len(tableCaptionPrefix))
+		first.Segment = first.Segment.TrimLeftSpace(source)
+
+		caption := &TableCaption{}
+		for n := p.FirstChild(); n != nil; {
+			following := n.NextSibling()
+			caption.AppendChild(caption, n)
+			n = following
+		}
+		p.Parent().RemoveChild(p.Parent(), p)
+		table.InsertBefore(table, table.FirstChild(), caption)
+	}
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (c *tableCaptions) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindTableCaption, func(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.WriteString(`<caption class="table-caption">`)
+		} else {
+			_, _ = w.WriteString("</caption>\n")
+		}
+		return ast.WalkContinue, nil
+	})
+}
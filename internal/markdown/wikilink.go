@@ -0,0 +1,344 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Wikilink resolution modes
+const (
+	ResolveShortest = "shortest" // by file name anywhere under the root, like Obsidian
+	ResolveRelative = "relative" // relative to the linking document
+	ResolveAbsolute = "absolute" // relative to the root
+)
+
+// DefaultWikilinkExtension is the extension links to notes point to.
+const DefaultWikilinkExtension = ".pdf"
+
+// WikilinkOptions configures how [[wikilinks]] and ![[embeds]] are resolved.
+type WikilinkOptions struct {
+	// Directory targets are resolved against, e.g. the vault root
+	// (defaults to the directory of the linking document).
+	Root string
+
+	// Resolution mode: shortest (default), relative, or absolute.
+	Resolve string
+
+	// Extension links to notes point to, e.g. ".pdf" (default) or ".md".
+	Extension string
+}
+
+// Validate reports an error for an unknown resolution mode.
+func (o WikilinkOptions) Validate() error {
+	switch o.Resolve {
+	case "", ResolveShortest, ResolveRelative, ResolveAbsolute:
+		return nil
+	}
+	return fmt.Errorf("invalid wikilink resolution %q (use shortest, relative, or absolute)", o.Resolve)
+}
+
+// noteExtensions are files a wikilink without an extension can refer to
+var noteExtensions = []string{".md", ".markdown"}
+
+// docDirKey holds the directory of the document being converted
+var docDirKey = parser.NewContextKey()
+
+// wikilinks is the goldmark extension parsing [[Page]], [[Page#Heading|label]],
+// and ![[image.png|300]] into links and images.
+type wikilinks struct {
+	opts WikilinkOptions
+}
+
+// Extend implements goldmark.Extender.
+func (w *wikilinks) Extend(m goldmark.Markdown) {
+	// Ahead of the link parser, which would otherwise claim the brackets
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		util.Prioritized(&wikilinkParser{opts: w.opts}, 199),
+	))
+}
+
+type wikilinkParser struct {
+	opts WikilinkOptions
+}
+
+// Trigger implements parser.InlineParser.
+func (p *wikilinkParser) Trigger() []byte {
+	return []byte{'!', '['}
+}
+
+// Parse implements parser.InlineParser.
+func (p *wikilinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+
+	embed := bytes.HasPrefix(line, []byte("![["))
+	if !embed && !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+	start := 2
+	if embed {
+		start = 3
+	}
+
+	end := bytes.Index(line[start:], []byte("]]"))
+	if end <= 0 {
+		return nil
+	}
+	inner := string(line[start : start+end])
+	if strings.ContainsAny(inner, "[]\n") {
+		return nil
+	}
+	block.Advance(start + end + 2)
+
+	target, alias, _ := strings.Cut(inner, "|")
+	target = strings.TrimSpace(target)
+	alias = strings.TrimSpace(alias)
+	page, heading, _ := strings.Cut(target, "#")
+
+	// On embeds the alias may be an image size rather than a label
+	label := alias
+	width, height, sized := imageSize(alias)
+	if embed && sized {
+		label = ""
+	}
+
+	docDir, _ := pc.Get(docDirKey).(string)
+	if docDir == "" {
+		docDir = "."
+	}
+
+	var dest string
+	if page != "" {
+		path, ok := p.opts.resolve(page, docDir)
+		if !ok {
+			log.Printf("Warning: unresolved wikilink [[%s]] in %s", target, docDir)
+			return ast.NewString([]byte(wikilinkLabel(label, page, heading)))
+		}
+		dest = path
+	}
+
+	if embed && isImage(dest) {
+		img := ast.NewImage(ast.NewLink())
+		img.Destination = []byte(dest)
+		img.AppendChild(img, ast.NewString([]byte(filepath.Base(page))))
+		if sized {
+			img.SetAttributeString("width", []byte(width))
+			if height != "" {
+				img.SetAttributeString("height", []byte(height))
+			}
+		}
+		return img
+	}
+
+	if heading != "" {
+		dest += "#" + headingID(heading)
+	}
+	link := ast.NewLink()
+	link.Destination = []byte(dest)
+	link.AppendChild(link, ast.NewString([]byte(wikilinkLabel(label, page, heading))))
+	return link
+}
+
+// wikilinkLabel is the text shown for a link: the alias, or the page and heading
+func wikilinkLabel(alias, page, heading string) string {
+	switch {
+	case alias != "":
+		return alias
+	case page == "":
+		return heading
+	case heading != "":
+		return page + " > " + heading
+	}
+	return page
+}
+
+// resolve finds the file a target refers to and returns its path relative to
+// docDir, with note extensions replaced by the link extension
+func (o WikilinkOptions) resolve(target, docDir string) (string, bool) {
+	root := o.Root
+	if root == "" {
+		root = docDir
+	}
+
+	var found string
+	switch o.Resolve {
+	case ResolveRelative:
+		found = findFile(filepath.Join(docDir, target))
+	case ResolveAbsolute:
+		found = findFile(filepath.Join(root, target))
+	default:
+		if strings.Contains(target, "/") {
+			if found = findFile(filepath.Join(root, target)); found == "" {
+				found = findFile(filepath.Join(docDir, target))
+			}
+		} else {
+			found = lookupName(root, target, docDir)
+		}
+	}
+	if found == "" {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(docDir, found)
+	if err != nil {
+		// One path is absolute and the other is not
+		absDir, _ := filepath.Abs(docDir)
+		absFound, _ := filepath.Abs(found)
+		if rel, err = filepath.Rel(absDir, absFound); err != nil {
+			rel = found
+		}
+	}
+	if slices.Contains(noteExtensions, strings.ToLower(filepath.Ext(rel))) {
+		ext := o.Extension
+		if ext == "" {
+			ext = DefaultWikilinkExtension
+		}
+		rel = strings.TrimSuffix(rel, filepath.Ext(rel)) + ext
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// findFile returns path, or path with a note extension, if the file exists
+func findFile(path string) string {
+	candidates := []string{path}
+	if filepath.Ext(path) == "" {
+		candidates = nil
+		for _, ext := range noteExtensions {
+			candidates = append(candidates, path+ext)
+		}
+	}
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && !info.IsDir() {
+			return c
+		}
+	}
+	return ""
+}
+
+var (
+	nameIndexMu sync.Mutex
+	nameIndexes = map[string]map[string][]string{}
+)
+
+// lookupName finds a file by name under root, ignoring case. Among several
+// matches the one closest to docDir wins.
+func lookupName(root, name, docDir string) string {
+	index := nameIndex(root)
+
+	keys := []string{strings.ToLower(name)}
+	if filepath.Ext(name) == "" {
+		keys = nil
+		for _, ext := range noteExtensions {
+			keys = append(keys, strings.ToLower(name+ext))
+		}
+	}
+
+	var matches []string
+	for _, key := range keys {
+		matches = append(matches, index[key]...)
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	slices.SortFunc(matches, func(a, b string) int {
+		if da, db := filepath.Dir(a) == filepath.Clean(docDir), filepath.Dir(b) == filepath.Clean(docDir); da != db {
+			if da {
+				return -1
+			}
+			return 1
+		}
+		if ca, cb := strings.Count(a, string(filepath.Separator)), strings.Count(b, string(filepath.Separator)); ca != cb {
+			return ca - cb
+		}
+		return strings.Compare(a, b)
+	})
+	return matches[0]
+}
+
+// nameIndex maps lowercased file names under root to their paths, walking the
+// tree once per root
+func nameIndex(root string) map[string][]string {
+	root = filepath.Clean(root)
+
+	nameIndexMu.Lock()
+	defer nameIndexMu.Unlock()
+
+	if index, ok := nameIndexes[root]; ok {
+		return index
+	}
+
+	index := make(map[string][]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		key := strings.ToLower(d.Name())
+		index[key] = append(index[key], path)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Warning: index wikilink root %s: %v", root, err)
+	}
+
+	nameIndexes[root] = index
+	return index
+}
+
+// imageExtensions are embedded as images rather than linked
+var imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".bmp", ".avif"}
+
+func isImage(path string) bool {
+	return slices.Contains(imageExtensions, strings.ToLower(filepath.Ext(path)))
+}
+
+// imageSize parses an Obsidian size alias, "300" or "300x200"
+func imageSize(alias string) (width, height string, ok bool) {
+	width, height, _ = strings.Cut(alias, "x")
+	if _, err := strconv.Atoi(width); err != nil {
+		return "", "", false
+	}
+	if _, err := strconv.Atoi(height); height != "" && err != nil {
+		return "", "", false
+	}
+	return width, height, true
+}
+
+// headingID mirrors goldmark's automatic heading IDs for ASCII text
+func headingID(heading string) string {
+	var b strings.Builder
+	for _, c := range []byte(strings.TrimSpace(heading)) {
+		switch {
+		case util.IsAlphaNumeric(c):
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			b.WriteByte(c)
+		case util.IsSpace(c) || c == '-' || c == '_':
+			b.WriteByte('-')
+		}
+	}
+	if b.Len() == 0 {
+		return "heading"
+	}
+	return b.String()
+}
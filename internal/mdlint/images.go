@@ -0,0 +1,89 @@
+package mdlint
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/images"
+)
+
+var (
+	// ![alt](path "title") and ![alt](<path with spaces>)
+	inlineImageRegex = regexp.MustCompile(`!\[([^\]]*)\]\(\s*(?:<([^>]*)>|([^)\s]*))[^)]*\)`)
+	// ![alt][label], ![alt][], and ![alt]
+	refImageRegex   = regexp.MustCompile(`!\[([^\]]*)\](?:\[([^\]]*)\])?`)
+	definitionRegex = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:\s*<?([^\s>]+)>?`)
+	imgTagRegex     = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+	altAttrRegex    = regexp.MustCompile(`(?i)\balt\s*=`)
+	srcAttrRegex    = regexp.MustCompile(`(?i)\bsrc\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// Images checks the images of a markdown document: "image-alt" for images
+// without alt text and "image-missing" for local images that don't exist,
+// resolved against baseDir as they are when embedded.
+func Images(src []byte, baseDir string) []Issue {
+	lines := splitLines(src)
+
+	definitions := make(map[string]string)
+	for _, line := range textLines(lines) {
+		if m := definitionRegex.FindStringSubmatch(line); m != nil {
+			definitions[strings.ToLower(m[1])] = m[2]
+		}
+	}
+
+	var issues []Issue
+	check := func(i int, path string, alt bool) {
+		name := path
+		if name == "" {
+			name = "(no path)"
+		}
+		if !alt {
+			issues = append(issues, Issue{Line: i + 1, Rule: "image-alt", Message: "image " + name + " has no alt text"})
+		}
+		if path == "" || images.IsAbsoluteOrDataURL(path) || strings.HasPrefix(path, images.QRScheme) {
+			return
+		}
+		if _, err := os.Stat(images.LocalPath(path, baseDir)); err != nil {
+			issues = append(issues, Issue{Line: i + 1, Rule: "image-missing", Message: "image " + path + " does not exist"})
+		}
+	}
+
+	for i, line := range textLines(lines) {
+		line = inlineCodeRegex.ReplaceAllString(line, "")
+
+		for _, m := range inlineImageRegex.FindAllStringSubmatch(line, -1) {
+			check(i, stripFragment(m[2]+m[3]), strings.TrimSpace(m[1]) != "")
+		}
+		for _, m := range refImageRegex.FindAllStringSubmatch(inlineImageRegex.ReplaceAllString(line, ""), -1) {
+			label := m[2]
+			if label == "" {
+				label = m[1]
+			}
+			path, ok := definitions[strings.ToLower(label)]
+			if !ok {
+				continue // not an image, e.g. "![" in prose
+			}
+			check(i, stripFragment(path), strings.TrimSpace(m[1]) != "")
+		}
+		// An empty alt attribute marks a decorative image, so only a missing one is reported
+		for _, tag := range imgTagRegex.FindAllString(line, -1) {
+			path := ""
+			if m := srcAttrRegex.FindStringSubmatch(tag); m != nil {
+				path = m[1] + m[2] + m[3]
+			}
+			check(i, stripFragment(path), altAttrRegex.MatchString(tag))
+		}
+	}
+	return issues
+}
+
+// stripFragment drops the query and fragment of an image path
+func stripFragment(path string) string {
+	if images.IsAbsoluteOrDataURL(path) {
+		return path
+	}
+	path, _, _ = strings.Cut(path, "#")
+	path, _, _ = strings.Cut(path, "?")
+	return path
+}
@@ -0,0 +1,129 @@
+// Package mdlint checks markdown sources for problems that make ugly PDFs:
+// skipped heading levels, headings without a space after the "#", trailing
+// spaces, bare URLs, and images without alt text or a file.
+package mdlint
+
+import (
+	"fmt"
+	"iter"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Issue is one lint problem at a line of a source.
+type Issue struct {
+	Line    int
+	Rule    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d: %s: %s", i.Line, i.Rule, i.Message)
+}
+
+// Rules lists the lint rules.
+var Rules = []string{"heading-order", "heading-space", "trailing-spaces", "bare-url"}
+
+var (
+	fenceRegex        = regexp.MustCompile("^ {0,3}(```|~~~)")
+	atxRegex          = regexp.MustCompile(`^ {0,3}(#{1,6})([^#]|$)`)
+	setextRegex       = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	inlineCodeRegex   = regexp.MustCompile("`+[^`]*`+")
+	bracketedURLRegex = regexp.MustCompile(`<[a-zA-Z][a-zA-Z0-9+.-]*:[^>\s]*>|\]\([^)]*\)|\]:\s*\S+|\w+="[^"]*"|\w+='[^']*'`)
+	urlRegex          = regexp.MustCompile(`https?://[^\s<>()\[\]]+`)
+)
+
+// Lint returns the issues of a markdown document in line order, leaving out
+// the rules in disabled. Front matter and fenced code are not checked.
+func Lint(src []byte, disabled []string) []Issue {
+	enabled := func(rule string) bool { return !slices.Contains(disabled, rule) }
+	lines := splitLines(src)
+
+	var issues []Issue
+	report := func(i int, rule, format string, args ...any) {
+		if enabled(rule) {
+			issues = append(issues, Issue{Line: i + 1, Rule: rule, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	heading := 0 // level of the previous heading
+	checkHeading := func(i, level int) {
+		if heading > 0 && level > heading+1 {
+			report(i, "heading-order", "h%d follows an h%d, skipping a level", level, heading)
+		}
+		heading = level
+	}
+
+	for i, line := range textLines(lines) {
+		// Two trailing spaces are a hard line break
+		if trimmed := strings.TrimRight(line, " \t"); trimmed != line && trimmed != "" && line[len(trimmed):] != "  " {
+			report(i, "trailing-spaces", "trailing whitespace (only two spaces make a line break)")
+		}
+
+		if m := atxRegex.FindStringSubmatch(line); m != nil {
+			if m[2] != "" && m[2] != " " && m[2] != "\t" {
+				report(i, "heading-space", "no space after %q, so the heading prints as text", m[1])
+				continue
+			}
+			checkHeading(i, len(m[1]))
+		} else if m := setextRegex.FindStringSubmatch(line); m != nil && i > 0 && isParagraph(lines[i-1]) {
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			checkHeading(i-1, level)
+		}
+
+		text := bracketedURLRegex.ReplaceAllString(inlineCodeRegex.ReplaceAllString(line, ""), "")
+		for _, url := range urlRegex.FindAllString(text, -1) {
+			report(i, "bare-url", "bare URL %s; write <%s> or [text](%s)", url, url, url)
+		}
+	}
+	return issues
+}
+
+// splitLines splits a source into lines
+func splitLines(src []byte) []string {
+	return strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+}
+
+// textLines yields the lines of a document with their index, skipping front
+// matter and fenced code
+func textLines(lines []string) iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		frontMatter := len(lines) > 0 && strings.TrimSpace(lines[0]) == "---"
+		fence := ""
+		for i, line := range lines {
+			switch {
+			case frontMatter:
+				if i > 0 && strings.TrimSpace(line) == "---" {
+					frontMatter = false
+				}
+				continue
+			case fence != "":
+				if strings.HasPrefix(strings.TrimSpace(line), fence) {
+					fence = ""
+				}
+				continue
+			}
+			if m := fenceRegex.FindStringSubmatch(line); m != nil {
+				fence = m[1]
+				continue
+			}
+			if !yield(i, line) {
+				return
+			}
+		}
+	}
+}
+
+// isParagraph reports whether a line can be the text of a setext heading
+func isParagraph(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ">") ||
+		strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "|") {
+		return false
+	}
+	return !setextRegex.MatchString(line)
+}
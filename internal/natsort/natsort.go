@@ -0,0 +1,121 @@
+// Package natsort orders strings the way people number files, so that
+// chapter2.md sorts before chapter10.md.
+package natsort
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Natural and Lexical name the orderings accepted by Func
+const (
+	Natural = "natural"
+	Lexical = "lexical"
+)
+
+// Compare compares a and b, treating runs of digits as numbers. Numbers equal
+// in value but not in zero padding order the shorter one first; ties fall back
+// to a plain byte comparison so the order is total.
+func Compare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			if c := compareNumbers(a[si:i], b[sj:j]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// Sort sorts names in natural order
+func Sort(names []string) {
+	slices.SortFunc(names, Compare)
+}
+
+// Func returns the comparison for order: natural (also the default when empty)
+// or lexical
+func Func(order string) (func(a, b string) int, error) {
+	switch order {
+	case "", Natural:
+		return Compare, nil
+	case Lexical:
+		return strings.Compare, nil
+	}
+	return nil, fmt.Errorf("unknown sort order %q (use natural or lexical)", order)
+}
+
+// Order sorts names by order, see Func
+func Order(names []string, order string) error {
+	compare, err := Func(order)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(names, compare)
+	return nil
+}
+
+// compareNumbers compares two digit runs by value, then by length
+func compareNumbers(a, b string) int {
+	ta, tb := trimZeros(a), trimZeros(b)
+	if len(ta) != len(tb) {
+		if len(ta) < len(tb) {
+			return -1
+		}
+		return 1
+	}
+	if ta != tb {
+		if ta < tb {
+			return -1
+		}
+		return 1
+	}
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func trimZeros(s string) string {
+	for len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
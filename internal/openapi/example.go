@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// example returns a JSON example for a schema: the media type's own example
+// when the spec gives one, else the schema's, else one built from the schema
+func (w *writer) example(schema, example, examples node) string {
+	if example.ok() {
+		return jsonValue(example, "")
+	}
+	for _, ex := range examples.pairs() {
+		// OpenAPI 3 wraps examples in {summary, value}; Swagger 2 maps media
+		// types straight to the value
+		if !w.spec.swagger {
+			ex.value = w.resolve(ex.value).get("value")
+		}
+		if ex.value.ok() {
+			return jsonValue(ex.value, "")
+		}
+	}
+	if !schema.ok() {
+		return ""
+	}
+	return w.sample(schema, "", 0, map[string]bool{})
+}
+
+// sample builds an example value from a schema. Schemas already being
+// expanded, and anything nested too deep, become null to stop recursion.
+func (w *writer) sample(schema node, indent string, depth int, expanding map[string]bool) string {
+	if ref := schema.str("$ref"); ref != "" {
+		if expanding[ref] || depth > 6 {
+			return "null"
+		}
+		expanding[ref] = true
+		defer delete(expanding, ref)
+		schema = w.resolve(schema)
+	}
+
+	for _, key := range []string{"example", "default"} {
+		if v := schema.get(key); v.ok() {
+			return jsonValue(v, indent)
+		}
+	}
+	if enum := schema.get("enum").items(); len(enum) > 0 {
+		return jsonValue(enum[0], indent)
+	}
+	if parts := schema.get("allOf").items(); len(parts) > 0 {
+		return w.sampleObject(w.mergedAll(parts), indent, depth, expanding)
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if parts := schema.get(key).items(); len(parts) > 0 {
+			return w.sample(parts[0], indent, depth+1, expanding)
+		}
+	}
+
+	typ := schema.get("type").value()
+	if types := schema.get("type").strings(); typ == "" && len(types) > 0 {
+		typ = types[0]
+	}
+	switch {
+	case typ == "object" || (typ == "" && schema.get("properties").ok()):
+		return w.sampleObject(schema, indent, depth, expanding)
+	case typ == "array":
+		if depth > 6 {
+			return "[]"
+		}
+		inner := indent + "  "
+		return "[\n" + inner + w.sample(schema.get("items"), inner, depth+1, expanding) + "\n" + indent + "]"
+	case typ == "integer":
+		return "0"
+	case typ == "number":
+		return "0.0"
+	case typ == "boolean":
+		return "true"
+	case typ == "string":
+		return sampleString(schema.str("format"))
+	}
+	return "null"
+}
+
+// sampleObject builds an example object with every property of schema
+func (w *writer) sampleObject(schema node, indent string, depth int, expanding map[string]bool) string {
+	props := schema.get("properties").pairs()
+	if len(props) == 0 || depth > 6 {
+		return "{}"
+	}
+
+	inner := indent + "  "
+	var fields []string
+	for _, p := range props {
+		fields = append(fields, inner+jsonString(p.key)+": "+w.sample(p.value, inner, depth+1, expanding))
+	}
+	return "{\n" + strings.Join(fields, ",\n") + "\n" + indent + "}"
+}
+
+// mergedAll resolves the parts of an allOf and merges their properties
+func (w *writer) mergedAll(parts []node) node {
+	props := &yaml.Node{Kind: yaml.MappingNode}
+	for _, part := range parts {
+		part = w.resolve(part)
+		if nested := part.get("allOf").items(); len(nested) > 0 {
+			part = w.mergedAll(nested)
+		}
+		props.Content = append(props.Content, part.get("properties").content()...)
+	}
+	return node{&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("properties"), props}}}
+}
+
+// sampleString returns an example string for a format
+func sampleString(format string) string {
+	switch format {
+	case "date":
+		return `"2024-01-31"`
+	case "date-time":
+		return `"2024-01-31T12:00:00Z"`
+	case "time":
+		return `"12:00:00"`
+	case "email":
+		return `"user@example.com"`
+	case "uri", "url":
+		return `"https://example.com"`
+	case "uuid":
+		return `"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+	case "ipv4":
+		return `"192.0.2.1"`
+	case "ipv6":
+		return `"2001:db8::1"`
+	case "byte":
+		return `"U3dhZ2dlciByb2Nrcw=="`
+	case "binary":
+		return `"<binary>"`
+	}
+	return `"string"`
+}
+
+// jsonValue writes a YAML value as indented JSON, keeping the key order
+func jsonValue(n node, indent string) string {
+	v := n.deref()
+	if v == nil {
+		return "null"
+	}
+
+	inner := indent + "  "
+	switch v.Kind {
+	case yaml.DocumentNode:
+		if len(v.Content) > 0 {
+			return jsonValue(node{v.Content[0]}, indent)
+		}
+		return "null"
+	case yaml.MappingNode:
+		pairs := n.pairs()
+		if len(pairs) == 0 {
+			return "{}"
+		}
+		var fields []string
+		for _, p := range pairs {
+			fields = append(fields, inner+jsonString(p.key)+": "+jsonValue(p.value, inner))
+		}
+		return "{\n" + strings.Join(fields, ",\n") + "\n" + indent + "}"
+	case yaml.SequenceNode:
+		items := n.items()
+		if len(items) == 0 {
+			return "[]"
+		}
+		var elems []string
+		for _, item := range items {
+			elems = append(elems, inner+jsonValue(item, inner))
+		}
+		return "[\n" + strings.Join(elems, ",\n") + "\n" + indent + "]"
+	}
+
+	switch v.ShortTag() {
+	case "!!null":
+		return "null"
+	case "!!bool", "!!int", "!!float":
+		var x any
+		if err := v.Decode(&x); err == nil {
+			if b, err := json.Marshal(x); err == nil {
+				return string(b)
+			}
+		}
+	}
+	return jsonString(v.Value)
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
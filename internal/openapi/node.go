@@ -0,0 +1,101 @@
+package openapi
+
+import "gopkg.in/yaml.v3"
+
+// node wraps a YAML node, which keeps the order of paths and properties as
+// the spec lists them. Lookups on a missing node return a missing node.
+type node struct {
+	n *yaml.Node
+}
+
+type pair struct {
+	key   string
+	value node
+}
+
+func (n node) ok() bool {
+	return n.n != nil
+}
+
+// deref follows YAML aliases
+func (n node) deref() *yaml.Node {
+	v := n.n
+	for v != nil && v.Kind == yaml.AliasNode {
+		v = v.Alias
+	}
+	return v
+}
+
+// get returns the value of key in a mapping
+func (n node) get(key string) node {
+	v := n.deref()
+	if v == nil || v.Kind != yaml.MappingNode {
+		return node{}
+	}
+	for i := 0; i+1 < len(v.Content); i += 2 {
+		if v.Content[i].Value == key {
+			return node{v.Content[i+1]}
+		}
+	}
+	return node{}
+}
+
+// value returns the text of a scalar
+func (n node) value() string {
+	v := n.deref()
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return v.Value
+}
+
+// str returns the scalar value of key in a mapping
+func (n node) str(key string) string {
+	return n.get(key).value()
+}
+
+// pairs returns the entries of a mapping in order
+func (n node) pairs() []pair {
+	v := n.deref()
+	if v == nil || v.Kind != yaml.MappingNode {
+		return nil
+	}
+	var out []pair
+	for i := 0; i+1 < len(v.Content); i += 2 {
+		out = append(out, pair{key: v.Content[i].Value, value: node{v.Content[i+1]}})
+	}
+	return out
+}
+
+// items returns the elements of a sequence
+func (n node) items() []node {
+	v := n.deref()
+	if v == nil || v.Kind != yaml.SequenceNode {
+		return nil
+	}
+	out := make([]node, len(v.Content))
+	for i, c := range v.Content {
+		out[i] = node{c}
+	}
+	return out
+}
+
+// strings returns a sequence of scalars, or a lone scalar as one string
+func (n node) strings() []string {
+	if s := n.value(); s != "" {
+		return []string{s}
+	}
+	var out []string
+	for _, item := range n.items() {
+		out = append(out, item.value())
+	}
+	return out
+}
+
+// content returns the raw children of a mapping or sequence
+func (n node) content() []*yaml.Node {
+	if v := n.deref(); v != nil {
+		return v.Content
+	}
+	return nil
+}
@@ -0,0 +1,669 @@
+// Package openapi turns OpenAPI 3 and Swagger 2 specs, in YAML or JSON, into a
+// markdown API reference: endpoints grouped by tag with their parameters,
+// request bodies, responses, and examples, followed by the schemas.
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// methods lists the operations of a path item in display order
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+var anchorRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Spec is a parsed OpenAPI or Swagger document.
+type Spec struct {
+	Title   string
+	Version string
+
+	root    node
+	swagger bool // Swagger 2.0 rather than OpenAPI 3
+}
+
+// Parse reads an OpenAPI 3 or Swagger 2 spec. JSON specs parse as YAML.
+func Parse(src []byte) (*Spec, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("spec is empty")
+	}
+
+	root := node{doc.Content[0]}
+	s := &Spec{root: root, swagger: root.str("swagger") != ""}
+	if !s.swagger && root.str("openapi") == "" {
+		return nil, fmt.Errorf("not an OpenAPI or Swagger spec: no openapi or swagger version")
+	}
+	s.Title = root.get("info").str("title")
+	s.Version = root.get("info").str("version")
+	return s, nil
+}
+
+// Markdown writes the API reference. Operations are headed "### `GET` /path"
+// with the classes operation and the lowercase method, and schemas with the
+// class schema and an id of schema-<name> that type columns link to.
+func (s *Spec) Markdown() string {
+	w := &writer{spec: s}
+	w.overview()
+	w.security()
+	w.operations()
+	w.schemas()
+	return w.String()
+}
+
+type writer struct {
+	strings.Builder
+	spec *Spec
+}
+
+func (w *writer) line(format string, args ...any) {
+	fmt.Fprintf(w, format, args...)
+	w.WriteByte('\n')
+}
+
+// overview writes the title, version, description, and servers
+func (w *writer) overview() {
+	root := w.spec.root
+	info := root.get("info")
+
+	title := w.spec.Title
+	if title == "" {
+		title = "API Reference"
+	}
+	w.line("# %s", title)
+	w.line("")
+	if w.spec.Version != "" {
+		w.line("*Version %s*", w.spec.Version)
+		w.line("")
+	}
+	if d := info.str("description"); d != "" {
+		w.line("%s", strings.TrimSpace(d))
+		w.line("")
+	}
+
+	var servers []string
+	if w.spec.swagger {
+		if host := root.str("host"); host != "" {
+			schemes := root.get("schemes").strings()
+			if len(schemes) == 0 {
+				schemes = []string{"https"}
+			}
+			for _, scheme := range schemes {
+				servers = append(servers, "`"+scheme+"://"+host+root.str("basePath")+"`")
+			}
+		}
+	} else {
+		for _, srv := range root.get("servers").items() {
+			entry := "`" + srv.str("url") + "`"
+			if d := srv.str("description"); d != "" {
+				entry += " - " + d
+			}
+			servers = append(servers, entry)
+		}
+	}
+	if len(servers) > 0 {
+		w.line("**Servers:**")
+		w.line("")
+		for _, srv := range servers {
+			w.line("- %s", srv)
+		}
+		w.line("")
+	}
+
+	if contact := info.get("contact"); contact.ok() {
+		parts := []string{}
+		if name := contact.str("name"); name != "" {
+			parts = append(parts, name)
+		}
+		if u := contact.str("url"); u != "" {
+			parts = append(parts, "<"+u+">")
+		}
+		if e := contact.str("email"); e != "" {
+			parts = append(parts, "<"+e+">")
+		}
+		if len(parts) > 0 {
+			w.line("**Contact:** %s", strings.Join(parts, ", "))
+			w.line("")
+		}
+	}
+	if license := info.get("license"); license.str("name") != "" {
+		name := license.str("name")
+		if u := license.str("url"); u != "" {
+			name = "[" + name + "](" + u + ")"
+		}
+		w.line("**License:** %s", name)
+		w.line("")
+	}
+}
+
+// security writes the authentication schemes
+func (w *writer) security() {
+	schemes := w.spec.root.get("components").get("securitySchemes")
+	if w.spec.swagger {
+		schemes = w.spec.root.get("securityDefinitions")
+	}
+	pairs := schemes.pairs()
+	if len(pairs) == 0 {
+		return
+	}
+
+	w.line("## Authentication")
+	w.line("")
+	w.line("| Name | Type | Details |")
+	w.line("| --- | --- | --- |")
+	for _, p := range pairs {
+		scheme := w.resolve(p.value)
+		var details []string
+		switch scheme.str("type") {
+		case "apiKey":
+			details = append(details, "`"+scheme.str("name")+"` in "+scheme.str("in"))
+		case "http":
+			details = append(details, scheme.str("scheme"))
+			if f := scheme.str("bearerFormat"); f != "" {
+				details = append(details, f)
+			}
+		case "openIdConnect":
+			details = append(details, scheme.str("openIdConnectUrl"))
+		case "oauth2":
+			if f := scheme.str("flow"); f != "" {
+				details = append(details, f)
+			}
+			for _, flow := range scheme.get("flows").pairs() {
+				details = append(details, flow.key)
+			}
+		}
+		if d := scheme.str("description"); d != "" {
+			details = append(details, d)
+		}
+		w.line("| %s | %s | %s |", cell(p.key), cell(scheme.str("type")), cell(strings.Join(details, "; ")))
+	}
+	w.line("")
+}
+
+type operation struct {
+	method, path string
+	op, item     node
+}
+
+// operations writes the endpoints, grouped by their first tag in the order
+// the tags are declared, then first used
+func (w *writer) operations() {
+	var order []string
+	groups := make(map[string][]operation)
+	descriptions := make(map[string]string)
+	for _, tag := range w.spec.root.get("tags").items() {
+		name := tag.str("name")
+		order = append(order, name)
+		descriptions[name] = tag.str("description")
+	}
+
+	for _, p := range w.spec.root.get("paths").pairs() {
+		item := w.resolve(p.value)
+		for _, method := range methods {
+			op := item.get(method)
+			if !op.ok() {
+				continue
+			}
+			tag := "Endpoints"
+			if tags := op.get("tags").strings(); len(tags) > 0 {
+				tag = tags[0]
+			}
+			if _, seen := groups[tag]; !seen && !contains(order, tag) {
+				order = append(order, tag)
+			}
+			groups[tag] = append(groups[tag], operation{method: method, path: p.key, op: op, item: item})
+		}
+	}
+
+	for _, tag := range order {
+		if len(groups[tag]) == 0 {
+			continue
+		}
+		w.line("## %s", tag)
+		w.line("")
+		if d := descriptions[tag]; d != "" {
+			w.line("%s", strings.TrimSpace(d))
+			w.line("")
+		}
+		for _, o := range groups[tag] {
+			w.operation(o)
+		}
+	}
+}
+
+func (w *writer) operation(o operation) {
+	w.line("### `%s` %s {.operation .%s}", strings.ToUpper(o.method), escapeBraces(o.path), o.method)
+	w.line("")
+	if s := o.op.str("summary"); s != "" {
+		w.line("**%s**", strings.TrimSpace(s))
+		w.line("")
+	}
+	if o.op.str("deprecated") == "true" {
+		w.line("> **Deprecated:** this operation may be removed in a future version.")
+		w.line("")
+	}
+	if d := o.op.str("description"); d != "" {
+		w.line("%s", strings.TrimSpace(d))
+		w.line("")
+	}
+
+	// Path-level parameters apply unless the operation overrides them
+	var params []node
+	seen := make(map[string]bool)
+	for _, list := range []node{o.op.get("parameters"), o.item.get("parameters")} {
+		for _, p := range list.items() {
+			p = w.resolve(p)
+			key := p.str("in") + ":" + p.str("name")
+			if !seen[key] {
+				seen[key] = true
+				params = append(params, p)
+			}
+		}
+	}
+
+	var body node
+	var bodyTypes []string
+	var formParams []node
+	rows := 0
+	for _, p := range params {
+		switch p.str("in") {
+		case "body":
+			body, bodyTypes = p, consumes(w.spec.root, o.op)
+			continue
+		case "formData":
+			formParams = append(formParams, p)
+			continue
+		}
+		if rows == 0 {
+			w.line("**Parameters:**")
+			w.line("")
+			w.line("| Name | In | Type | Required | Description |")
+			w.line("| --- | --- | --- | --- | --- |")
+		}
+		rows++
+		schema := p.get("schema")
+		if !schema.ok() {
+			schema = p // Swagger 2 keeps the type on the parameter
+		}
+		w.line("| `%s` | %s | %s | %s | %s |", p.str("name"), p.str("in"), cell(w.typeOf(schema)), yesNo(p.str("required") == "true"), cell(describe(p, schema)))
+	}
+	if rows > 0 {
+		w.line("")
+	}
+
+	if len(formParams) > 0 {
+		w.line("**Form data** (`%s`):", strings.Join(consumes(w.spec.root, o.op), "`, `"))
+		w.line("")
+		w.line("| Name | Type | Required | Description |")
+		w.line("| --- | --- | --- | --- |")
+		for _, p := range formParams {
+			w.line("| `%s` | %s | %s | %s |", p.str("name"), cell(w.typeOf(p)), yesNo(p.str("required") == "true"), cell(describe(p, p)))
+		}
+		w.line("")
+	}
+
+	if rb := w.resolve(o.op.get("requestBody")); rb.ok() {
+		w.requestBody(rb)
+	} else if body.ok() {
+		w.line("**Request body** (`%s`)%s", strings.Join(bodyTypes, "`, `"), required(body))
+		w.line("")
+		if d := body.str("description"); d != "" {
+			w.line("%s", strings.TrimSpace(d))
+			w.line("")
+		}
+		w.schemaBlock(w.resolve(body.get("schema")), body.get("schema"), node{}, node{})
+	}
+
+	w.responses(o.op)
+}
+
+// requestBody writes an OpenAPI 3 request body, one block per media type
+func (w *writer) requestBody(rb node) {
+	for _, media := range rb.get("content").pairs() {
+		w.line("**Request body** (`%s`)%s", media.key, required(rb))
+		w.line("")
+		if d := rb.str("description"); d != "" {
+			w.line("%s", strings.TrimSpace(d))
+			w.line("")
+		}
+		schema := media.value.get("schema")
+		w.schemaBlock(w.resolve(schema), schema, media.value.get("example"), media.value.get("examples"))
+	}
+}
+
+// responses writes the table of responses and the example of the first
+// successful response with a body
+func (w *writer) responses(op node) {
+	pairs := op.get("responses").pairs()
+	if len(pairs) == 0 {
+		return
+	}
+
+	w.line("**Responses:**")
+	w.line("")
+	w.line("| Status | Description | Type |")
+	w.line("| --- | --- | --- |")
+	var example func()
+	for _, p := range pairs {
+		resp := w.resolve(p.value)
+		var types []string
+		var schema, mediaExample, mediaExamples node
+		if w.spec.swagger {
+			schema = resp.get("schema")
+			mediaExamples = resp.get("examples")
+		} else {
+			for _, media := range resp.get("content").pairs() {
+				if !schema.ok() {
+					schema = media.value.get("schema")
+					mediaExample = media.value.get("example")
+					mediaExamples = media.value.get("examples")
+				}
+				types = append(types, "`"+media.key+"`")
+			}
+		}
+
+		typ := ""
+		if schema.ok() {
+			typ = w.typeOf(schema)
+			if len(types) > 0 {
+				typ += " (" + strings.Join(types, ", ") + ")"
+			}
+		}
+		w.line("| %s | %s | %s |", cell(p.key), cell(resp.str("description")), cell(typ))
+
+		if example == nil && strings.HasPrefix(p.key, "2") && schema.ok() {
+			status := p.key
+			example = func() {
+				if ex := w.example(schema, mediaExample, mediaExamples); ex != "" {
+					w.line("Example response (%s):", status)
+					w.line("")
+					w.code(ex)
+				}
+			}
+		}
+	}
+	w.line("")
+	if example != nil {
+		example()
+	}
+}
+
+// schemaBlock writes the fields of an inline object schema, or its type when
+// it refers to a named schema, followed by an example
+func (w *writer) schemaBlock(schema, raw, example, examples node) {
+	if !schema.ok() {
+		return
+	}
+	if raw.str("$ref") != "" || schema.get("type").value() != "object" || !schema.get("properties").ok() {
+		w.line("Type: %s", w.typeOf(raw))
+		w.line("")
+	} else {
+		w.properties(schema)
+	}
+	if ex := w.example(raw, example, examples); ex != "" {
+		w.line("Example:")
+		w.line("")
+		w.code(ex)
+	}
+}
+
+// schemas writes each named schema with its properties
+func (w *writer) schemas() {
+	defs := w.spec.root.get("components").get("schemas")
+	if w.spec.swagger {
+		defs = w.spec.root.get("definitions")
+	}
+	pairs := defs.pairs()
+	if len(pairs) == 0 {
+		return
+	}
+
+	w.line("## Schemas")
+	w.line("")
+	for _, p := range pairs {
+		schema := w.resolve(p.value)
+		w.line("### %s {#%s .schema}", p.key, schemaAnchor(p.key))
+		w.line("")
+		if d := schema.str("description"); d != "" {
+			w.line("%s", strings.TrimSpace(d))
+			w.line("")
+		}
+
+		switch {
+		case schema.get("properties").ok():
+			w.properties(schema)
+		case schema.get("allOf").ok():
+			w.line("Combines %s.", w.typeOf(p.value))
+			w.line("")
+			if own := w.merged(schema); own.get("properties").ok() {
+				w.properties(own)
+			}
+		default:
+			w.line("Type: %s", w.typeOf(p.value))
+			w.line("")
+			if enum := schema.get("enum").items(); len(enum) > 0 {
+				w.line("Values: %s", enumList(enum))
+				w.line("")
+			}
+		}
+	}
+}
+
+// properties writes the property table of an object schema. Inline objects
+// are flattened into dotted names.
+func (w *writer) properties(schema node) {
+	w.line("| Property | Type | Required | Description |")
+	w.line("| --- | --- | --- | --- |")
+	w.propertyRows(schema, "", 0)
+	w.line("")
+}
+
+func (w *writer) propertyRows(schema node, prefix string, depth int) {
+	required := schema.get("required").strings()
+	for _, p := range schema.get("properties").pairs() {
+		prop := w.resolve(p.value)
+		name := prefix + p.key
+		w.line("| `%s` | %s | %s | %s |", name, cell(w.typeOf(p.value)), yesNo(contains(required, p.key)), cell(describe(prop, prop)))
+
+		if depth >= 3 || p.value.str("$ref") != "" {
+			continue
+		}
+		switch {
+		case prop.get("properties").ok():
+			w.propertyRows(prop, name+".", depth+1)
+		case prop.get("items").get("properties").ok() && prop.get("items").str("$ref") == "":
+			w.propertyRows(prop.get("items"), name+"[].", depth+1)
+		}
+	}
+}
+
+// merged returns the inline parts of an allOf schema as one object
+func (w *writer) merged(schema node) node {
+	props := &yaml.Node{Kind: yaml.MappingNode}
+	req := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, part := range schema.get("allOf").items() {
+		if part.str("$ref") != "" {
+			continue
+		}
+		props.Content = append(props.Content, part.get("properties").content()...)
+		req.Content = append(req.Content, part.get("required").content()...)
+	}
+	return node{&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		scalar("properties"), props,
+		scalar("required"), req,
+	}}}
+}
+
+// typeOf describes a schema's type, linking named schemas
+func (w *writer) typeOf(schema node) string {
+	if ref := schema.str("$ref"); ref != "" {
+		name := refName(ref)
+		return "[" + name + "](#" + schemaAnchor(name) + ")"
+	}
+	for _, combinator := range []struct{ key, sep string }{{"allOf", " & "}, {"oneOf", " | "}, {"anyOf", " | "}} {
+		if parts := schema.get(combinator.key).items(); len(parts) > 0 {
+			var types []string
+			for _, part := range parts {
+				types = append(types, w.typeOf(part))
+			}
+			if len(types) == 1 {
+				return types[0]
+			}
+			return strings.Join(types, combinator.sep)
+		}
+	}
+
+	typ := schema.get("type").value()
+	if types := schema.get("type").strings(); len(types) > 1 {
+		// OpenAPI 3.1 allows a list of types, such as [string, "null"]
+		typ = strings.Join(types, " | ")
+	}
+	switch typ {
+	case "array":
+		typ = "array of " + w.typeOf(schema.get("items"))
+	case "":
+		switch {
+		case schema.get("properties").ok():
+			typ = "object"
+		case schema.get("items").ok():
+			typ = "array of " + w.typeOf(schema.get("items"))
+		default:
+			typ = "any"
+		}
+	case "object":
+		if extra := schema.get("additionalProperties"); extra.ok() && extra.value() != "false" {
+			typ = "map of " + w.typeOf(extra)
+		}
+	}
+	if f := schema.str("format"); f != "" {
+		typ += " (" + f + ")"
+	}
+	if schema.str("nullable") == "true" {
+		typ += ", nullable"
+	}
+	return typ
+}
+
+// resolve follows a local $ref, such as #/components/schemas/Pet; external
+// references are returned as they are
+func (w *writer) resolve(n node) node {
+	for range 32 {
+		ref := n.str("$ref")
+		if !strings.HasPrefix(ref, "#/") {
+			return n
+		}
+		target := w.spec.root
+		for _, part := range strings.Split(ref[2:], "/") {
+			part, _ = url.PathUnescape(part)
+			part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+			target = target.get(part)
+		}
+		if !target.ok() {
+			return n
+		}
+		n = target
+	}
+	return n
+}
+
+// code writes a JSON code block
+func (w *writer) code(json string) {
+	w.line("```json")
+	w.line("%s", json)
+	w.line("```")
+	w.line("")
+}
+
+// describe returns the description of a parameter or property with its
+// default, allowed values, and deprecation
+func describe(n, schema node) string {
+	var parts []string
+	if d := n.str("description"); d != "" {
+		parts = append(parts, strings.TrimSpace(d))
+	} else if d := schema.str("description"); d != "" {
+		parts = append(parts, strings.TrimSpace(d))
+	}
+	if enum := schema.get("enum").items(); len(enum) > 0 {
+		parts = append(parts, "One of "+enumList(enum)+".")
+	}
+	if def := schema.get("default"); def.ok() {
+		parts = append(parts, "Default: `"+jsonValue(def, "")+"`.")
+	}
+	if n.str("deprecated") == "true" {
+		parts = append(parts, "**Deprecated.**")
+	}
+	return strings.Join(parts, " ")
+}
+
+func enumList(values []node) string {
+	var out []string
+	for _, v := range values {
+		out = append(out, "`"+jsonValue(v, "")+"`")
+	}
+	return strings.Join(out, ", ")
+}
+
+// consumes returns the request media types of a Swagger 2 operation
+func consumes(root, op node) []string {
+	if types := op.get("consumes").strings(); len(types) > 0 {
+		return types
+	}
+	if types := root.get("consumes").strings(); len(types) > 0 {
+		return types
+	}
+	return []string{"application/json"}
+}
+
+func required(n node) string {
+	if n.str("required") == "true" {
+		return ", required"
+	}
+	return ""
+}
+
+// cell escapes text for a table cell
+func cell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// escapeBraces keeps path templates such as {id} from being read as heading
+// attributes
+func escapeBraces(path string) string {
+	return strings.NewReplacer("{", `\{`, "}", `\}`).Replace(path)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func schemaAnchor(name string) string {
+	return "schema-" + strings.Trim(anchorRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func scalar(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
@@ -0,0 +1,358 @@
+// Package orgmode converts Emacs Org-mode documents to markdown, so they go
+// through the same rendering pipeline.
+//
+// It covers the basics: headings, lists, source and example blocks, quotes,
+// links, tables, and inline markup. Keywords other than #+TITLE, drawers, and
+// comments are dropped.
+package orgmode
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingRegex  = regexp.MustCompile(`^(\*+)\s+(.*?)(?:\s+(:[\w@#%:]+:))?\s*$`)
+	keywordRegex  = regexp.MustCompile(`^#\+(\w+):\s*(.*)$`)
+	blockRegex    = regexp.MustCompile(`(?i)^#\+begin_(\w+)\s*(.*)$`)
+	listRegex     = regexp.MustCompile(`^(\s*)(?:[-+]|\s\*|(\d+)[.)])\s+(.*)$`)
+	checkboxRegex = regexp.MustCompile(`^\[([ Xx-])\]\s+`)
+	termRegex     = regexp.MustCompile(`^(.*?)\s+::\s+(.*)$`)
+	hlineRegex    = regexp.MustCompile(`^\s*\|-[-+|]*\s*$`)
+	drawerRegex   = regexp.MustCompile(`^\s*:(\w+):\s*$`)
+	ruleRegex     = regexp.MustCompile(`^\s*-{5,}\s*$`)
+
+	linkRegex     = regexp.MustCompile(`\[\[([^\]]+)\](?:\[([^\]]+)\])?\]`)
+	verbatimRegex = regexp.MustCompile(`(^|[\s(\[{'"])([=~])(\S|\S.*?\S)([=~])($|[\s)\]},.;:!?'"-])`)
+	emphasisRegex = regexp.MustCompile(`(^|[\s(\[{'"])([*/+_])(\S|\S.*?\S)([*/+_])($|[\s)\]},.;:!?'"-])`)
+
+	imageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true}
+)
+
+// ToMarkdown converts an Org document to markdown.
+func ToMarkdown(src []byte) []byte {
+	c := converter{}
+	lines := strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+
+	// With a #+TITLE the title is the top heading and * headings start at ##
+	for _, line := range lines {
+		if m := keywordRegex.FindStringSubmatch(line); m != nil && strings.EqualFold(m[1], "title") {
+			c.title = strings.TrimSpace(m[2])
+			break
+		}
+	}
+	if c.title != "" {
+		c.emit("# " + c.inline(c.title))
+		c.emit("")
+		c.offset = 1
+	}
+
+	for i := 0; i < len(lines); i++ {
+		i = c.line(lines, i)
+	}
+	return bytes.TrimLeft(c.out.Bytes(), "\n")
+}
+
+type converter struct {
+	out    bytes.Buffer
+	title  string
+	offset int // heading levels added below the title
+
+	table      bool // inside a table
+	tableRows  int  // rows written to the current table
+	tableWidth int
+}
+
+func (c *converter) emit(s string) {
+	c.out.WriteString(s)
+	c.out.WriteByte('\n')
+}
+
+// line converts lines[i] and returns the index of the last line it consumed
+func (c *converter) line(lines []string, i int) int {
+	line := lines[i]
+	trimmed := strings.TrimSpace(line)
+
+	if strings.HasPrefix(trimmed, "|") {
+		c.tableRow(trimmed)
+		return i
+	}
+	c.endTable()
+
+	switch {
+	case blockRegex.MatchString(trimmed):
+		return c.block(lines, i)
+
+	case keywordRegex.MatchString(trimmed):
+		return i
+
+	case trimmed == "#" || strings.HasPrefix(trimmed, "# "):
+		return i
+
+	case drawerRegex.MatchString(trimmed) && !strings.EqualFold(trimmed, ":end:"):
+		// Property and logbook drawers hold metadata, not content
+		for j := i + 1; j < len(lines); j++ {
+			if strings.EqualFold(strings.TrimSpace(lines[j]), ":end:") {
+				return j
+			}
+		}
+		return i
+
+	case trimmed == ":" || strings.HasPrefix(trimmed, ": "):
+		// Fixed-width lines are verbatim
+		c.emit("```")
+		j := i
+		for ; j < len(lines); j++ {
+			t := strings.TrimSpace(lines[j])
+			if t != ":" && !strings.HasPrefix(t, ": ") {
+				break
+			}
+			c.emit(strings.TrimPrefix(strings.TrimPrefix(t, ":"), " "))
+		}
+		c.emit("```")
+		return j - 1
+
+	case ruleRegex.MatchString(line):
+		// Blank lines keep the rule from turning the paragraph above into a heading
+		c.emit("")
+		c.emit("---")
+		c.emit("")
+		return i
+	}
+
+	if m := headingRegex.FindStringSubmatch(line); m != nil {
+		level := min(len(m[1])+c.offset, 6)
+		c.emit("")
+		c.emit(strings.Repeat("#", level) + " " + c.inline(m[2]))
+		c.emit("")
+		return i
+	}
+
+	if m := listRegex.FindStringSubmatch(line); m != nil {
+		c.emit(c.listItem(m[1], m[2], m[3]))
+		return i
+	}
+
+	c.emit(c.inline(line))
+	return i
+}
+
+// listItem converts a list item, keeping its indentation
+func (c *converter) listItem(indent, number, text string) string {
+	marker := "-"
+	if number != "" {
+		marker = number + "."
+	}
+
+	if m := checkboxRegex.FindStringSubmatch(text); m != nil {
+		box := "[ ]"
+		if m[1] == "X" || m[1] == "x" {
+			box = "[x]"
+		}
+		text = box + " " + text[len(m[0]):]
+	} else if m := termRegex.FindStringSubmatch(text); m != nil && number == "" {
+		// Description lists become bold terms
+		return indent + marker + " **" + c.inline(m[1]) + "**: " + c.inline(m[2])
+	}
+	return indent + marker + " " + c.inline(text)
+}
+
+// block converts a #+BEGIN_... #+END_... block starting at lines[i]
+func (c *converter) block(lines []string, i int) int {
+	m := blockRegex.FindStringSubmatch(strings.TrimSpace(lines[i]))
+	kind, args := strings.ToLower(m[1]), strings.Fields(m[2])
+	end := "#+end_" + kind
+
+	j := i + 1
+	var body []string
+	for ; j < len(lines); j++ {
+		if strings.EqualFold(strings.TrimSpace(lines[j]), end) {
+			break
+		}
+		body = append(body, lines[j])
+	}
+
+	switch kind {
+	case "src", "example", "export":
+		lang := ""
+		if len(args) > 0 && kind == "src" {
+			lang = args[0]
+		}
+		if kind == "export" && len(args) > 0 && strings.EqualFold(args[0], "html") {
+			// Raw HTML passes through as is
+			for _, l := range body {
+				c.emit(l)
+			}
+			break
+		}
+		c.emit("```" + lang)
+		for _, l := range dedent(body) {
+			// Org escapes lines that look like headings or keywords with a comma
+			if strings.HasPrefix(strings.TrimLeft(l, " \t"), ",*") || strings.HasPrefix(strings.TrimLeft(l, " \t"), ",#+") {
+				l = strings.Replace(l, ",", "", 1)
+			}
+			c.emit(l)
+		}
+		c.emit("```")
+	case "quote":
+		for _, l := range body {
+			c.emit(strings.TrimRight("> "+c.inline(strings.TrimSpace(l)), " "))
+		}
+	default:
+		// center, verse, and unknown blocks keep their content
+		for k := 0; k < len(body); k++ {
+			k = c.line(body, k)
+		}
+		c.endTable()
+	}
+	return j
+}
+
+// tableRow converts an Org table row or rule. Markdown needs a rule below the
+// first row, so one is added when the table has none.
+func (c *converter) tableRow(row string) {
+	if hlineRegex.MatchString(row) {
+		if c.table && c.tableRows == 1 {
+			c.emit(c.rule())
+			c.tableRows++
+		}
+		return
+	}
+
+	cells := strings.Split(strings.Trim(strings.TrimSpace(row), "|"), "|")
+	for k, cell := range cells {
+		cells[k] = c.inline(strings.TrimSpace(cell))
+	}
+
+	if !c.table {
+		c.emit("")
+		c.table = true
+		c.tableRows = 0
+		c.tableWidth = len(cells)
+	} else if c.tableRows == 1 {
+		c.emit(c.rule())
+		c.tableRows++
+	}
+
+	c.emit("| " + strings.Join(cells, " | ") + " |")
+	c.tableRows++
+}
+
+func (c *converter) rule() string {
+	return "|" + strings.Repeat(" --- |", max(c.tableWidth, 1))
+}
+
+// endTable closes the current table, adding the rule of a one-row table
+func (c *converter) endTable() {
+	if !c.table {
+		return
+	}
+	if c.tableRows == 1 {
+		c.emit(c.rule())
+	}
+	c.table = false
+	c.emit("")
+}
+
+// inline converts links and emphasis within a line
+func (c *converter) inline(s string) string {
+	// Verbatim text and links are protected from emphasis rules
+	var protected []string
+	protect := func(v string) string {
+		protected = append(protected, v)
+		return "\x00" + string(rune('0'+len(protected)-1)) + "\x00"
+	}
+
+	s = verbatimRegex.ReplaceAllStringFunc(s, func(m string) string {
+		p := verbatimRegex.FindStringSubmatch(m)
+		if p[2] != p[4] {
+			return m
+		}
+		return p[1] + protect("`"+p[3]+"`") + p[5]
+	})
+
+	s = linkRegex.ReplaceAllStringFunc(s, func(m string) string {
+		p := linkRegex.FindStringSubmatch(m)
+		return protect(link(p[1], p[2]))
+	})
+
+	// Emphasis markers can be adjacent, e.g. "*a* /b/", so repeat until stable
+	for {
+		next := emphasisRegex.ReplaceAllStringFunc(s, func(m string) string {
+			p := emphasisRegex.FindStringSubmatch(m)
+			if p[2] != p[4] {
+				return m
+			}
+			open, close := emphasis(p[2])
+			return p[1] + protect(open+p[3]+close) + p[5]
+		})
+		if next == s {
+			break
+		}
+		s = next
+	}
+
+	// Restore in reverse so protected text nested in later entries comes back
+	for k := len(protected) - 1; k >= 0; k-- {
+		s = strings.ReplaceAll(s, "\x00"+string(rune('0'+k))+"\x00", protected[k])
+	}
+	return s
+}
+
+// emphasis returns the markdown (or HTML) delimiters of an Org emphasis marker
+func emphasis(marker string) (string, string) {
+	switch marker {
+	case "*":
+		return "**", "**"
+	case "/":
+		return "*", "*"
+	case "+":
+		return "~~", "~~"
+	default:
+		return "<u>", "</u>"
+	}
+}
+
+// link converts an Org link target and optional description
+func link(target, desc string) string {
+	target = strings.TrimPrefix(target, "file:")
+	isImage := imageExtensions[strings.ToLower(path.Ext(target))]
+
+	switch {
+	case desc == "" && isImage:
+		return "![](" + target + ")"
+	case desc == "":
+		return "[" + target + "](" + target + ")"
+	case imageExtensions[strings.ToLower(path.Ext(strings.TrimPrefix(desc, "file:")))]:
+		// An image as the description links the image
+		return "[![](" + strings.TrimPrefix(desc, "file:") + ")](" + target + ")"
+	}
+	return "[" + desc + "](" + target + ")"
+}
+
+// dedent removes the indentation common to all non-blank lines
+func dedent(lines []string) []string {
+	common := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if common < 0 || n < common {
+			common = n
+		}
+	}
+	if common <= 0 {
+		return lines
+	}
+
+	out := make([]string, len(lines))
+	for k, l := range lines {
+		if len(l) >= common {
+			out[k] = l[common:]
+		}
+	}
+	return out
+}
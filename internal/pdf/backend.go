@@ -0,0 +1,29 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+)
+
+// Backend converts a complete HTML document to PDF bytes.
+type Backend interface {
+	Generate(ctx context.Context, htmlContent string, opts Options) ([]byte, error)
+}
+
+// NewBackend returns the backend registered under name.
+// endpoint is the binary path for wkhtmltopdf or the service URL for gotenberg.
+func NewBackend(name, endpoint string) (Backend, error) {
+	switch name {
+	case "", "chrome":
+		return ChromeBackend{}, nil
+	case "wkhtmltopdf":
+		return WkhtmltopdfBackend{Bin: endpoint}, nil
+	case "gotenberg":
+		if endpoint == "" {
+			return nil, fmt.Errorf("gotenberg backend requires a service URL")
+		}
+		return GotenbergBackend{URL: endpoint}, nil
+	default:
+		return nil, fmt.Errorf("unknown pdf backend %q", name)
+	}
+}
@@ -0,0 +1,78 @@
+package pdf
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// FindChrome returns the path of an installed Chromium-based browser, or ""
+// when none is found. It looks on PATH and in the usual install locations:
+// Chrome, Chromium, then Edge, which ships with Windows, and Brave.
+func FindChrome() string {
+	for _, candidate := range browserCandidates(runtime.GOOS) {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// browserCandidates lists the browser commands and paths to try on goos
+func browserCandidates(goos string) []string {
+	switch goos {
+	case "windows":
+		var candidates []string
+		for _, dir := range []string{os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)"), os.Getenv("LocalAppData")} {
+			if dir == "" {
+				continue
+			}
+			candidates = append(candidates,
+				filepath.Join(dir, `Google\Chrome\Application\chrome.exe`),
+				filepath.Join(dir, `Chromium\Application\chrome.exe`),
+				filepath.Join(dir, `Microsoft\Edge\Application\msedge.exe`),
+				filepath.Join(dir, `BraveSoftware\Brave-Browser\Application\brave.exe`),
+			)
+		}
+		return append(candidates, "chrome.exe", "msedge.exe")
+
+	case "darwin":
+		apps := []string{
+			"Google Chrome.app/Contents/MacOS/Google Chrome",
+			"Chromium.app/Contents/MacOS/Chromium",
+			"Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+			"Brave Browser.app/Contents/MacOS/Brave Browser",
+		}
+		var candidates []string
+		// Apps dragged into ~/Applications instead of /Applications too
+		for _, dir := range []string{"/Applications", filepath.Join(os.Getenv("HOME"), "Applications")} {
+			for _, app := range apps {
+				candidates = append(candidates, filepath.Join(dir, app))
+			}
+		}
+		return append(candidates, "chromium", "google-chrome")
+
+	default:
+		return []string{
+			"headless_shell", "headless-shell",
+			"chromium", "chromium-browser",
+			"google-chrome", "google-chrome-stable", "google-chrome-beta", "google-chrome-unstable",
+			"/usr/bin/google-chrome", "/usr/local/bin/chrome", "/snap/bin/chromium", "chrome",
+			"microsoft-edge", "microsoft-edge-stable", "brave-browser",
+		}
+	}
+}
+
+// removeTemp removes a temp file, retrying briefly: on Windows a file the
+// browser still holds open can't be deleted yet
+func removeTemp(path string) {
+	for attempt := 0; ; attempt++ {
+		err := os.Remove(path)
+		if err == nil || os.IsNotExist(err) || runtime.GOOS != "windows" || attempt == 5 {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
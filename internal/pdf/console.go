@@ -0,0 +1,107 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	cdplog "github.com/chromedp/cdproto/log"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// maxWarningLength caps a console message, which may hold a whole script
+const maxWarningLength = 500
+
+// consoleLog collects the page's JavaScript errors, console.error messages,
+// and failed resource loads
+type consoleLog struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+// listen records the problems of the page in ctx; call before it navigates
+func (l *consoleLog) listen(ctx context.Context) {
+	chromedp.ListenTarget(ctx, func(ev any) {
+		var msg string
+		switch ev := ev.(type) {
+		case *runtime.EventExceptionThrown:
+			d := ev.ExceptionDetails
+			msg = d.Text
+			if d.Exception != nil && d.Exception.Description != "" {
+				msg = d.Exception.Description
+			}
+			msg = "uncaught " + firstLine(msg) + location(d.URL, d.LineNumber)
+		case *runtime.EventConsoleAPICalled:
+			if ev.Type != runtime.APITypeError && ev.Type != runtime.APITypeAssert {
+				return
+			}
+			var args []string
+			for _, arg := range ev.Args {
+				args = append(args, remoteString(arg))
+			}
+			msg = "console." + string(ev.Type) + ": " + strings.Join(args, " ")
+		case *cdplog.EventEntryAdded:
+			// Failed resource loads and other browser errors, e.g. a missing script
+			if ev.Entry.Level != cdplog.LevelError {
+				return
+			}
+			msg = ev.Entry.Text
+			if ev.Entry.URL != "" && !strings.HasPrefix(ev.Entry.URL, "data:") {
+				msg += ": " + ev.Entry.URL
+			}
+		default:
+			return
+		}
+
+		if len(msg) > maxWarningLength {
+			msg = msg[:maxWarningLength] + "…"
+		}
+		l.mu.Lock()
+		if !slices.Contains(l.warnings, msg) {
+			l.warnings = append(l.warnings, msg)
+		}
+		l.mu.Unlock()
+	})
+}
+
+// list returns the recorded messages in the order they occurred
+func (l *consoleLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.warnings)
+}
+
+// remoteString formats a console argument: strings as they are, other values
+// as JSON or their description
+func remoteString(o *runtime.RemoteObject) string {
+	switch {
+	case o.Type == runtime.TypeString:
+		var s string
+		json.Unmarshal(o.Value, &s)
+		return s
+	case o.Description != "":
+		return firstLine(o.Description)
+	case len(o.Value) > 0:
+		return string(o.Value)
+	}
+	return string(o.Type)
+}
+
+// firstLine drops the stack trace of an error description
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// location formats a script position, omitting the temp file the document is
+// loaded from
+func location(url string, line int64) string {
+	if url == "" || strings.HasPrefix(url, "file:") || strings.HasPrefix(url, "data:") {
+		return fmt.Sprintf(" (line %d)", line+1)
+	}
+	return fmt.Sprintf(" (%s:%d)", url, line+1)
+}
@@ -0,0 +1,173 @@
+package pdf
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/ziputil"
+)
+
+// HeadlessShellVersion is the Chrome for Testing release a Download installs
+// unless it names another.
+const HeadlessShellVersion = "131.0.6778.85"
+
+// DefaultDownloadURL is where Chrome for Testing builds are downloaded from.
+const DefaultDownloadURL = "https://storage.googleapis.com/chrome-for-testing-public"
+
+// maxShellSize bounds the extracted headless shell, which is about 250 MB
+const maxShellSize = 1 << 30
+
+// Download installs a pinned chrome-headless-shell build from Chrome for
+// Testing into a cache directory, for machines without a browser.
+type Download struct {
+	// Chrome for Testing version, HeadlessShellVersion when empty
+	Version string
+
+	// Expected SHA-256 of the zip: one hex digest, or platform=digest pairs
+	// separated by commas (e.g. "linux64=...,mac-arm64=...") so one setting
+	// serves a mixed team. Downloads are refused without a checksum.
+	SHA256 string
+
+	// Directory the builds are kept in, by version and platform
+	CacheDir string
+
+	// Mirror of the Chrome for Testing bucket, DefaultDownloadURL when empty
+	BaseURL string
+
+	// Client used to download, http.DefaultClient when nil
+	Client *http.Client
+}
+
+// Install returns the path of the cached headless shell, downloading,
+// verifying, and extracting it first when it isn't cached yet.
+func (d Download) Install(ctx context.Context) (string, error) {
+	platform, err := shellPlatform(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+	version := cmp.Or(d.Version, HeadlessShellVersion)
+	dir := filepath.Join(d.CacheDir, "chrome-headless-shell", version, platform)
+	name := "chrome-headless-shell"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	bin := filepath.Join(dir, "chrome-headless-shell-"+platform, name)
+
+	// A build is only moved into place once verified and extracted
+	if _, err := os.Stat(bin); err == nil {
+		return bin, nil
+	}
+
+	want, err := expectedSum(d.SHA256, platform)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/%s/%s/chrome-headless-shell-%s.zip", strings.TrimSuffix(cmp.Or(d.BaseURL, DefaultDownloadURL), "/"), version, platform, platform)
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return "", fmt.Errorf("create browser cache: %w", err)
+	}
+	archive, err := d.fetch(ctx, url, filepath.Dir(dir), want)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(archive)
+
+	staging, err := os.MkdirTemp(filepath.Dir(dir), platform+"-*")
+	if err != nil {
+		return "", fmt.Errorf("create browser cache: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	if err := ziputil.ExtractWithLimit(archive, staging, maxShellSize); err != nil {
+		return "", fmt.Errorf("extract %s: %w", url, err)
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		// Another process may have installed the same build meanwhile
+		if _, statErr := os.Stat(bin); statErr == nil {
+			return bin, nil
+		}
+		return "", fmt.Errorf("install headless shell: %w", err)
+	}
+	if _, err := os.Stat(bin); err != nil {
+		return "", fmt.Errorf("install headless shell: %s not found in archive", name)
+	}
+	return bin, nil
+}
+
+// fetch downloads url into a temp file in dir and checks its SHA-256
+func (d Download) fetch(ctx context.Context, url, dir, want string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	client := cmp.Or(d.Client, http.DefaultClient)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+
+	f, err := os.CreateTemp(dir, "download-*.zip")
+	if err != nil {
+		return "", fmt.Errorf("create browser cache: %w", err)
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("download %s: %w", url, err)
+	}
+
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("download %s: SHA-256 is %s, expected %s", url, got, want)
+	}
+	return f.Name(), nil
+}
+
+// expectedSum picks the platform's digest from a SHA256 setting
+func expectedSum(setting, platform string) (string, error) {
+	for _, entry := range strings.Split(setting, ",") {
+		entry = strings.TrimSpace(entry)
+		key, sum, found := strings.Cut(entry, "=")
+		if !found {
+			key, sum = platform, entry
+		}
+		if key == platform && sum != "" {
+			return strings.ToLower(strings.TrimSpace(sum)), nil
+		}
+	}
+	return "", fmt.Errorf("no SHA-256 given for the %s headless shell; set it to verify the download", platform)
+}
+
+// shellPlatform names the Chrome for Testing build for an OS and architecture
+func shellPlatform(goos, goarch string) (string, error) {
+	switch goos + "/" + goarch {
+	case "linux/amd64":
+		return "linux64", nil
+	case "darwin/arm64":
+		return "mac-arm64", nil
+	case "darwin/amd64":
+		return "mac-x64", nil
+	case "windows/amd64", "windows/arm64":
+		return "win64", nil
+	case "windows/386":
+		return "win32", nil
+	}
+	return "", fmt.Errorf("no headless shell build for %s/%s; install Chrome or set CHROME_BIN", goos, goarch)
+}
@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GotenbergBackend renders PDFs with a remote Gotenberg service.
+type GotenbergBackend struct {
+	// Base URL of the Gotenberg service, e.g. http://gotenberg:3000
+	URL string
+
+	// HTTP client to use (uses http.DefaultClient if nil)
+	Client *http.Client
+}
+
+// Generate implements Backend.
+func (b GotenbergBackend) Generate(ctx context.Context, htmlContent string, opts Options) ([]byte, error) {
+	body, contentType, err := gotenbergForm(htmlContent, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, opts)
+	defer cancel()
+
+	endpoint := strings.TrimSuffix(b.URL, "/") + "/forms/chromium/convert/html"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("create gotenberg request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gotenberg: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read gotenberg response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gotenberg: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	return data, nil
+}
+
+// gotenbergForm builds the multipart request body for the HTML conversion route.
+func gotenbergForm(htmlContent string, opts Options) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fw, err := mw.CreateFormFile("files", "index.html")
+	if err != nil {
+		return nil, "", fmt.Errorf("create form file: %w", err)
+	}
+	if _, err := io.WriteString(fw, htmlContent); err != nil {
+		return nil, "", fmt.Errorf("write form file: %w", err)
+	}
+
+	fields := [][2]string{
+		{"paperWidth", strconv.FormatFloat(opts.PaperWidth, 'f', -1, 64)},
+		{"paperHeight", strconv.FormatFloat(opts.PaperHeight, 'f', -1, 64)},
+		{"marginTop", strconv.FormatFloat(opts.MarginTop, 'f', -1, 64)},
+		{"marginBottom", strconv.FormatFloat(opts.MarginBottom, 'f', -1, 64)},
+		{"marginLeft", strconv.FormatFloat(opts.MarginLeft, 'f', -1, 64)},
+		{"marginRight", strconv.FormatFloat(opts.MarginRight, 'f', -1, 64)},
+		{"printBackground", strconv.FormatBool(opts.PrintBackground)},
+		{"preferCssPageSize", strconv.FormatBool(opts.PreferCSSPageSize)},
+	}
+	for _, f := range fields {
+		if err := mw.WriteField(f[0], f[1]); err != nil {
+			return nil, "", fmt.Errorf("write form field %s: %w", f[0], err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, "", fmt.Errorf("close form: %w", err)
+	}
+
+	return &buf, mw.FormDataContentType(), nil
+}
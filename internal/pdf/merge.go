@@ -0,0 +1,29 @@
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func init() {
+	// Use built-in defaults rather than reading or creating a pdfcpu config directory
+	api.DisableConfigDir()
+}
+
+// Merge concatenates PDF files, in order, into a single PDF at outputPath.
+func Merge(outputPath string, inputs []string) error {
+	if len(inputs) == 0 {
+		return fmt.Errorf("no PDFs to merge")
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	if err := api.MergeCreateFile(inputs, outputPath, false, conf); err != nil {
+		return fmt.Errorf("merge PDFs: %w", err)
+	}
+
+	return nil
+}
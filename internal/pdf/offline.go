@@ -0,0 +1,86 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// OfflineError is returned in strict offline mode when the page tried to load
+// anything over the network. The PDF is not written.
+type OfflineError struct {
+	URLs []string // blocked URLs, sorted, without duplicates
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("strict offline: the page requested %d external URLs: %s", len(e.URLs), strings.Join(e.URLs, ", "))
+}
+
+// offlineGuard fails every network request of a page and records its URL
+type offlineGuard struct {
+	mu      sync.Mutex
+	blocked []string
+}
+
+// listen intercepts the requests of the page in ctx; call before it navigates
+func (g *offlineGuard) listen(ctx context.Context) chromedp.Action {
+	chromedp.ListenTarget(ctx, func(ev any) {
+		paused, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		// Requests can't be answered from inside the event handler
+		go func() {
+			c := chromedp.FromContext(ctx)
+			if c == nil || c.Target == nil {
+				return
+			}
+			exec := cdp.WithExecutor(ctx, c.Target)
+			if !isNetworkURL(paused.Request.URL) {
+				fetch.ContinueRequest(paused.RequestID).Do(exec)
+				return
+			}
+			g.mu.Lock()
+			g.blocked = append(g.blocked, paused.Request.URL)
+			g.mu.Unlock()
+			fetch.FailRequest(paused.RequestID, network.ErrorReasonBlockedByClient).Do(exec)
+		}()
+	})
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		return fetch.Enable().WithPatterns([]*fetch.RequestPattern{{URLPattern: "*"}}).Do(ctx)
+	})
+}
+
+// err returns an OfflineError when any request was blocked
+func (g *offlineGuard) err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.blocked) == 0 {
+		return nil
+	}
+	urls := slices.Clone(g.blocked)
+	slices.Sort(urls)
+	return &OfflineError{URLs: slices.Compact(urls)}
+}
+
+// isNetworkURL reports whether loading ref leaves the machine
+func isNetworkURL(ref string) bool {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return true
+	}
+	switch u.Scheme {
+	case "file", "data", "blob", "about", "chrome", "devtools":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// OutlineEntry is a bookmark of a PDF's document outline.
+type OutlineEntry struct {
+	Title string
+	Level int // 1 for top-level bookmarks
+	Page  int // first page is 1
+}
+
+// Outline returns the bookmarks of a PDF in document order, such as the
+// headings Chrome embeds with Options.Outline.
+func Outline(data []byte) ([]OutlineEntry, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	bookmarks, err := api.Bookmarks(bytes.NewReader(data), conf)
+	if err != nil {
+		return nil, fmt.Errorf("read outline: %w", err)
+	}
+
+	var entries []OutlineEntry
+	var walk func([]pdfcpu.Bookmark, int)
+	walk = func(bookmarks []pdfcpu.Bookmark, level int) {
+		for _, b := range bookmarks {
+			entries = append(entries, OutlineEntry{Title: b.Title, Level: level, Page: b.PageFrom})
+			walk(b.Kids, level+1)
+		}
+	}
+	walk(bookmarks, 1)
+	return entries, nil
+}
@@ -0,0 +1,46 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+var (
+	pageObjRegex   = regexp.MustCompile(`/Type\s*/Page[^s]`)
+	pagesDictRegex = regexp.MustCompile(`<<[^<>]*/Type\s*/Pages\b[^<>]*>>`)
+	countRegex     = regexp.MustCompile(`/Count\s+(\d+)`)
+)
+
+// PageCount returns the number of pages in a PDF document.
+// It reads the root page tree's /Count when present and falls back to counting page objects,
+// then to a full parse for documents whose objects are compressed (e.g. merged PDFs).
+func PageCount(data []byte) int {
+	maxCount := 0
+	for _, dict := range pagesDictRegex.FindAll(data, -1) {
+		m := countRegex.FindSubmatch(dict)
+		if m == nil {
+			continue
+		}
+		if n, err := strconv.Atoi(string(m[1])); err == nil && n > maxCount {
+			maxCount = n
+		}
+	}
+	if maxCount > 0 {
+		return maxCount
+	}
+
+	if n := len(pageObjRegex.FindAll(data, -1)); n > 0 {
+		return n
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	if n, err := api.PageCount(bytes.NewReader(data), conf); err == nil {
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// paperSizes maps paper names to width and height in inches (portrait).
+var paperSizes = map[string][2]float64{
+	"a3":      {11.69, 16.54},
+	"a4":      {8.27, 11.69},
+	"a5":      {5.83, 8.27},
+	"b5":      {6.93, 9.84},
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+}
+
+// PaperSize returns the portrait width and height in inches for a paper name
+// such as "A4" or "letter". ok is false for unknown names.
+func PaperSize(name string) (width, height float64, ok bool) {
+	size, ok := paperSizes[strings.ToLower(strings.TrimSpace(name))]
+	return size[0], size[1], ok
+}
+
+// SetPaper applies a named paper size and orientation ("portrait" or "landscape")
+// to the options. Empty values leave the current setting unchanged.
+func (o *Options) SetPaper(name, orientation string) error {
+	if name != "" {
+		w, h, ok := PaperSize(name)
+		if !ok {
+			return fmt.Errorf("unknown paper size %q", name)
+		}
+		o.PaperWidth, o.PaperHeight = w, h
+	}
+
+	switch strings.ToLower(strings.TrimSpace(orientation)) {
+	case "landscape":
+		if o.PaperWidth < o.PaperHeight {
+			o.PaperWidth, o.PaperHeight = o.PaperHeight, o.PaperWidth
+		}
+	case "portrait":
+		if o.PaperWidth > o.PaperHeight {
+			o.PaperWidth, o.PaperHeight = o.PaperHeight, o.PaperWidth
+		}
+	case "":
+	default:
+		return fmt.Errorf("unknown orientation %q (use portrait or landscape)", orientation)
+	}
+
+	return nil
+}
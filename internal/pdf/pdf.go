@@ -2,13 +2,20 @@
 package pdf
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
+
+	"github.com/kuzik/markdown-pdf-action/internal/fileurl"
 )
 
 // Options configures PDF generation settings.
@@ -29,62 +36,191 @@ type Options struct {
 	// Prefer CSS page size over paper dimensions
 	PreferCSSPageSize bool
 
+	// HTML printed at the top and bottom of every page (Chrome only). Elements
+	// with the classes pageNumber, totalPages, title, date, and url are filled
+	// in by Chrome. Leave the margins room for them.
+	HeaderTemplate string
+	FooterTemplate string
+
 	// Timeout for Chrome operations
 	Timeout time.Duration
 
+	// Maximum time to wait for images, fonts, and scripts to settle before printing
+	MaxWait time.Duration
+
 	// Path to Chrome binary (uses default if empty)
 	ChromeBin string
+
+	// DevTools endpoint of an already-running Chrome (ws:// or http://).
+	// When set, no local Chrome is started and ChromeBin is ignored.
+	RemoteDebuggingURL string
+
+	// Backend that performs the conversion (uses headless Chrome if nil)
+	Backend Backend
+
+	// Proxy server for the browser's requests, e.g. http://proxy.corp:3128,
+	// and the hosts that bypass it, e.g. "localhost;*.corp.example.com"
+	ProxyServer string
+	ProxyBypass string
+
+	// Host resolver rules of a local Chrome, e.g. "MAP assets.example.com 10.0.0.5"
+	HostResolverRules string
+
+	// Language of a local Chrome, used for Accept-Language, navigator.language,
+	// and locale-aware formatting, e.g. de-DE
+	Language string
+
+	// Fail every network request of the page and return an OfflineError
+	// listing them, to prove a document renders without internet access
+	// (Chrome only)
+	StrictOffline bool
+
+	// Called with the requests the page made once the document has printed,
+	// when set (Chrome only)
+	Resources func([]Resource)
+
+	// Called once the document has printed with the page's JavaScript
+	// errors, console.error messages, and failed resource loads, when set
+	// (Chrome only)
+	Warnings func([]string)
+
+	// Embed the document outline, built from the headings, as PDF bookmarks
+	// (Chrome only)
+	Outline bool
+
+	// Extra command line flags of a local Chrome, as "name" or "name=value"
+	// with or without leading dashes; "name=false" drops a default flag
+	ChromeFlags []string
+
+	// Keep Chrome's web security enabled: no file access from the page and no
+	// cross-origin relaxation. Used when rendering untrusted content.
+	Sandboxed bool
+
+	// When set, output is made byte-reproducible: creation and modification
+	// dates are set to this time, the document ID is derived from the content,
+	// and no temp file path can leak into the document.
+	FixedDate time.Time
+
+	// Called with the duration of each Chrome stage (launch chrome, navigate,
+	// wait for body, wait for content, print) when set
+	Trace func(stage string, d time.Duration)
 }
 
 // DefaultOptions returns sensible defaults for PDF generation.
 func DefaultOptions() Options {
 	return Options{
-		PaperWidth:        8.27,  // A4 width in inches
-		PaperHeight:       11.69, // A4 height in inches
-		MarginTop:         0.4,
-		MarginBottom:      0.4,
-		MarginLeft:        0.4,
-		MarginRight:       0.4,
-		PrintBackground:   true,
-		PreferCSSPageSize: false,
-		Timeout:           30 * time.Second,
-		ChromeBin:         os.Getenv("CHROME_BIN"),
+		PaperWidth:         8.27,  // A4 width in inches
+		PaperHeight:        11.69, // A4 height in inches
+		MarginTop:          0.4,
+		MarginBottom:       0.4,
+		MarginLeft:         0.4,
+		MarginRight:        0.4,
+		PrintBackground:    true,
+		PreferCSSPageSize:  false,
+		Timeout:            30 * time.Second,
+		MaxWait:            10 * time.Second,
+		ChromeBin:          os.Getenv("CHROME_BIN"),
+		RemoteDebuggingURL: os.Getenv("CHROME_REMOTE_URL"),
 	}
 }
 
 // FromHTML converts HTML content to PDF and writes it to the output path.
-func FromHTML(htmlContent, outputPath string) error {
-	return FromHTMLWithOptions(htmlContent, outputPath, DefaultOptions())
+func FromHTML(ctx context.Context, htmlContent, outputPath string) error {
+	return FromHTMLWithOptions(ctx, htmlContent, outputPath, DefaultOptions())
 }
 
 // FromHTMLWithOptions converts HTML content to PDF with custom options.
-func FromHTMLWithOptions(htmlContent, outputPath string, opts Options) error {
-	// Write HTML to temporary file
-	tmpFile, err := writeTempHTML(htmlContent)
+func FromHTMLWithOptions(ctx context.Context, htmlContent, outputPath string, opts Options) error {
+	pdfBuf, err := Generate(ctx, htmlContent, opts)
 	if err != nil {
 		return err
 	}
-	defer os.Remove(tmpFile)
 
+	// Write PDF to output file
+	if err := os.WriteFile(outputPath, pdfBuf, 0o644); err != nil {
+		return fmt.Errorf("write pdf: %w", err)
+	}
+
+	return nil
+}
+
+// Generate converts HTML content to PDF using the configured backend and returns the PDF bytes.
+func Generate(ctx context.Context, htmlContent string, opts Options) ([]byte, error) {
+	backend := opts.Backend
+	if backend == nil {
+		backend = ChromeBackend{}
+	}
+
+	pdfBuf, err := backend.Generate(ctx, htmlContent, opts)
+	if err != nil || opts.FixedDate.IsZero() {
+		return pdfBuf, err
+	}
+
+	start := time.Now()
+	pdfBuf = Normalize(pdfBuf, opts.FixedDate)
+	opts.trace("normalize", time.Since(start))
+	return pdfBuf, nil
+}
+
+// trace reports the duration of a stage to the Trace hook, if any
+func (o Options) trace(stage string, d time.Duration) {
+	if o.Trace != nil {
+		o.Trace(stage, d)
+	}
+}
+
+// ChromeBackend renders PDFs with a local headless Chrome.
+type ChromeBackend struct{}
+
+// Generate implements Backend. Cancelling ctx shuts down the Chrome process
+// and removes the temp HTML file.
+func (ChromeBackend) Generate(ctx context.Context, htmlContent string, opts Options) ([]byte, error) {
 	// Setup Chrome context
-	ctx, cancel, err := setupChromeContext(opts)
+	chromeCtx, cancel, err := setupChromeContext(ctx, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer cancel()
 
-	// Generate PDF
-	pdfBuf, err := generatePDF(ctx, tmpFile, opts)
+	// A remote browser can't see local files, so the document is injected directly
+	if opts.inlineDocument() {
+		return generatePDF(chromeCtx, setDocumentContent(htmlContent), opts)
+	}
+
+	// Write HTML to temporary file
+	tmpFile, err := writeTempHTML(htmlContent)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer removeTemp(tmpFile)
 
-	// Write PDF to output file
-	if err := os.WriteFile(outputPath, pdfBuf, 0o644); err != nil {
-		return fmt.Errorf("write pdf: %w", err)
+	// Generate PDF
+	fileURL, err := fileurl.FromPath(tmpFile)
+	if err != nil {
+		return nil, err
 	}
+	return generatePDF(chromeCtx, chromedp.Navigate(fileURL), opts)
+}
 
-	return nil
+// inlineDocument reports whether the document is injected into a blank page
+// rather than loaded from a temp file: remote browsers can't read local files,
+// and reproducible output must not depend on a random temp file name.
+func (o Options) inlineDocument() bool {
+	return o.RemoteDebuggingURL != "" || !o.FixedDate.IsZero()
+}
+
+// setDocumentContent loads HTML into a blank page without touching the filesystem.
+func setDocumentContent(htmlContent string) chromedp.Action {
+	return chromedp.Tasks{
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			tree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			return page.SetDocumentContent(tree.Frame.ID, htmlContent).Do(ctx)
+		}),
+	}
 }
 
 // writeTempHTML writes HTML content to a temporary file.
@@ -104,28 +240,22 @@ func writeTempHTML(htmlContent string) (string, error) {
 	return tmpFile.Name(), nil
 }
 
-// setupChromeContext creates a Chrome context with appropriate options.
-func setupChromeContext(opts Options) (context.Context, context.CancelFunc, error) {
-	chromeOpts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.DisableGPU,
-		chromedp.NoSandbox,
-		chromedp.Headless,
-		chromedp.Flag("allow-file-access-from-files", true),
-		chromedp.Flag("disable-web-security", true),
+// setupChromeContext creates a Chrome context derived from parent with appropriate options.
+func setupChromeContext(parent context.Context, opts Options) (context.Context, context.CancelFunc, error) {
+	var (
+		allocCtx    context.Context
+		allocCancel context.CancelFunc
 	)
 
-	if opts.ChromeBin != "" {
-		chromeOpts = append(chromeOpts, chromedp.ExecPath(opts.ChromeBin))
+	if opts.RemoteDebuggingURL != "" {
+		allocCtx, allocCancel = chromedp.NewRemoteAllocator(parent, opts.RemoteDebuggingURL)
+	} else {
+		allocCtx, allocCancel = chromedp.NewExecAllocator(parent, execAllocatorOptions(opts)...)
 	}
 
-	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), chromeOpts...)
 	ctx, ctxCancel := chromedp.NewContext(allocCtx)
 
-	timeout := opts.Timeout
-	if timeout == 0 {
-		timeout = 30 * time.Second
-	}
-	ctx, timeoutCancel := context.WithTimeout(ctx, timeout)
+	ctx, timeoutCancel := withTimeout(ctx, opts)
 
 	cancel := func() {
 		timeoutCancel()
@@ -136,17 +266,130 @@ func setupChromeContext(opts Options) (context.Context, context.CancelFunc, erro
 	return ctx, cancel, nil
 }
 
-// generatePDF uses Chrome to convert HTML file to PDF.
-func generatePDF(ctx context.Context, htmlPath string, opts Options) ([]byte, error) {
+// execAllocatorOptions returns the flags used to launch a local Chrome.
+func execAllocatorOptions(opts Options) []chromedp.ExecAllocatorOption {
+	chromeOpts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.DisableGPU,
+		chromedp.NoSandbox,
+		chromedp.Headless,
+	)
+
+	if !opts.Sandboxed {
+		chromeOpts = append(chromeOpts,
+			chromedp.Flag("allow-file-access-from-files", true),
+			chromedp.Flag("disable-web-security", true),
+		)
+	}
+
+	if opts.ProxyServer != "" {
+		chromeOpts = append(chromeOpts, chromedp.ProxyServer(opts.ProxyServer))
+	}
+	if opts.ProxyBypass != "" {
+		chromeOpts = append(chromeOpts, chromedp.Flag("proxy-bypass-list", opts.ProxyBypass))
+	}
+	if opts.HostResolverRules != "" {
+		chromeOpts = append(chromeOpts, chromedp.Flag("host-resolver-rules", opts.HostResolverRules))
+	}
+	if opts.Language != "" {
+		chromeOpts = append(chromeOpts,
+			chromedp.Flag("lang", opts.Language),
+			chromedp.Flag("accept-lang", opts.Language),
+		)
+	}
+	for _, f := range opts.ChromeFlags {
+		name, value := ParseChromeFlag(f)
+		chromeOpts = append(chromeOpts, chromedp.Flag(name, value))
+	}
+
+	// Without CHROME_BIN, fall back to Edge and the macOS and Windows install
+	// locations chromedp doesn't search
+	if bin := cmp.Or(opts.ChromeBin, FindChrome()); bin != "" {
+		chromeOpts = append(chromeOpts, chromedp.ExecPath(bin))
+	}
+
+	return chromeOpts
+}
+
+// ParseChromeFlag splits "--name=value" into the flag name and its value:
+// true for a bare name, false for "false", and the string otherwise.
+func ParseChromeFlag(flag string) (string, any) {
+	name, value, found := strings.Cut(strings.TrimLeft(strings.TrimSpace(flag), "-"), "=")
+	switch {
+	case !found || value == "true":
+		return name, true
+	case value == "false":
+		return name, false
+	}
+	return name, value
+}
+
+// errDocumentTimeout is the cause of a render stopped by its own timeout,
+// telling it apart from an earlier deadline of the caller, such as --deadline
+var errDocumentTimeout = errors.New("document timeout")
+
+// withTimeout bounds ctx by the configured operation timeout.
+func withTimeout(ctx context.Context, opts Options) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, effectiveTimeout(opts), errDocumentTimeout)
+}
+
+// effectiveTimeout returns the per-document timeout, defaulting to 30 seconds.
+func effectiveTimeout(opts Options) time.Duration {
+	if opts.Timeout == 0 {
+		return 30 * time.Second
+	}
+	return opts.Timeout
+}
+
+// generatePDF uses Chrome to load a document and print it to PDF.
+func generatePDF(ctx context.Context, load chromedp.Action, opts Options) ([]byte, error) {
 	var pdfBuf []byte
 
-	err := chromedp.Run(ctx,
-		chromedp.Navigate("file://"+htmlPath),
-		chromedp.WaitReady("body", chromedp.ByQuery),
-		chromedp.Sleep(500*time.Millisecond),
-		chromedp.ActionFunc(func(ctx context.Context) error {
+	start := time.Now()
+	stage := "launch chrome"
+	track := func(name string, action chromedp.Action) chromedp.Action {
+		return chromedp.ActionFunc(func(ctx context.Context) error {
+			if stage == "launch chrome" {
+				// The browser, or the pool's tab, is started before the first action
+				opts.trace(stage, time.Since(start))
+			}
+			stage = name
+
+			begin := time.Now()
+			err := action.Do(ctx)
+			opts.trace(name, time.Since(begin))
+			return err
+		})
+	}
+
+	var actions []chromedp.Action
+	var guard *offlineGuard
+	if opts.StrictOffline {
+		guard = &offlineGuard{}
+		actions = append(actions, guard.listen(ctx))
+	}
+	var console *consoleLog
+	if opts.Warnings != nil {
+		console = &consoleLog{}
+		console.listen(ctx)
+	}
+	var requests *resourceLog
+	if opts.Resources != nil {
+		requests = &resourceLog{}
+		actions = append(actions, requests.listen(ctx))
+	}
+
+	err := chromedp.Run(ctx, append(actions,
+		track("navigate", load),
+		track("wait for body", chromedp.WaitReady("body", chromedp.ByQuery)),
+		track("wait for content", waitForContent(opts.MaxWait)),
+		track("print", chromedp.ActionFunc(func(ctx context.Context) error {
+			params := page.PrintToPDF()
+			if opts.Outline {
+				// Chrome builds the outline from the tagged structure
+				params = params.WithGenerateTaggedPDF(true).WithGenerateDocumentOutline(true)
+			}
 			var err error
-			pdfBuf, _, err = page.PrintToPDF().
+			pdfBuf, _, err = params.
 				WithPrintBackground(opts.PrintBackground).
 				WithPreferCSSPageSize(opts.PreferCSSPageSize).
 				WithPaperWidth(opts.PaperWidth).
@@ -155,14 +398,89 @@ func generatePDF(ctx context.Context, htmlPath string, opts Options) ([]byte, er
 				WithMarginBottom(opts.MarginBottom).
 				WithMarginLeft(opts.MarginLeft).
 				WithMarginRight(opts.MarginRight).
+				WithDisplayHeaderFooter(opts.HeaderTemplate != "" || opts.FooterTemplate != "").
+				WithHeaderTemplate(orEmptySpan(opts.HeaderTemplate)).
+				WithFooterTemplate(orEmptySpan(opts.FooterTemplate)).
 				Do(ctx)
 			return err
-		}),
-	)
+		})),
+	)...)
 
 	if err != nil {
-		return nil, fmt.Errorf("chromedp: %w", err)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		cause := context.Cause(ctx)
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(cause, context.DeadlineExceeded) || errors.Is(cause, errDocumentTimeout) {
+			limit := fmt.Sprintf("timeout %s", effectiveTimeout(opts))
+			if errors.Is(cause, context.DeadlineExceeded) {
+				// The caller's deadline came before the document's timeout
+				limit = "run deadline reached"
+			}
+			return nil, fmt.Errorf("chromedp: timed out during %s stage after %s (%s): %w",
+				stage, elapsed, limit, context.DeadlineExceeded)
+		}
+		return nil, fmt.Errorf("chromedp: %s stage failed after %s: %w", stage, elapsed, err)
+	}
+	if console != nil {
+		opts.Warnings(console.list())
+	}
+	if requests != nil {
+		opts.Resources(requests.list())
+	}
+	if guard != nil {
+		if err := guard.err(); err != nil {
+			return nil, err
+		}
 	}
 
 	return pdfBuf, nil
 }
+
+// orEmptySpan returns an empty element for an unset header or footer template,
+// which Chrome would otherwise fill with its default date and title.
+func orEmptySpan(tmpl string) string {
+	if tmpl == "" {
+		return "<span></span>"
+	}
+	return tmpl
+}
+
+// readinessScript resolves once the page has loaded, every image is decoded,
+// web fonts are ready, and injected scripts have cleared window.renderReady.
+const readinessScript = `(async () => {
+	if (document.readyState !== "complete") {
+		await new Promise(r => window.addEventListener("load", r, { once: true }));
+	}
+	await Promise.all(Array.from(document.images).map(img => img.decode().catch(() => {})));
+	if (document.fonts) {
+		await document.fonts.ready;
+	}
+	while (window.renderReady === false) {
+		await new Promise(r => setTimeout(r, 50));
+	}
+	await new Promise(r => requestAnimationFrame(() => requestAnimationFrame(r)));
+	return true;
+})()`
+
+// waitForContent blocks until dynamic content is ready or maxWait elapses.
+// Hitting maxWait is logged and printing proceeds with whatever has rendered.
+func waitForContent(maxWait time.Duration) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if maxWait <= 0 {
+			maxWait = 10 * time.Second
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, maxWait)
+		defer cancel()
+
+		var ready bool
+		err := chromedp.Evaluate(readinessScript, &ready, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}).Do(waitCtx)
+
+		if err != nil && ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+			log.Printf("Warning: content not ready after %s, printing anyway", maxWait)
+			return nil
+		}
+		return err
+	})
+}
@@ -0,0 +1,309 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/chromedp"
+
+	"github.com/kuzik/markdown-pdf-action/internal/fileurl"
+)
+
+// ErrPoolClosed is returned when rendering through a closed Pool.
+var ErrPoolClosed = errors.New("pdf pool closed")
+
+// Pool shares one Chrome browser across concurrent renders. Each render runs in
+// its own fresh tab, at most Size tabs are open at once, and the browser is
+// restarted automatically when a health check fails.
+//
+// Pool implements Backend, so it can be set as Options.Backend.
+type Pool struct {
+	opts           Options
+	slots          chan struct{}
+	waiting        atomic.Int64
+	healthInterval time.Duration
+
+	// restartMu serializes restarts; mu guards the fields below it
+	restartMu     sync.Mutex
+	mu            sync.Mutex
+	browserCtx    context.Context // nil while a failed restart left no browser
+	browserCancel context.CancelFunc
+	generation    int // counts the browsers started, to tell which one failed
+	restarts      int
+	closed        bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPool starts a browser and returns a pool allowing size concurrent tabs.
+// A positive healthInterval enables periodic background health checks.
+func NewPool(size int, healthInterval time.Duration, opts Options) (*Pool, error) {
+	if size < 1 {
+		size = 1
+	}
+
+	p := &Pool{
+		opts:           opts,
+		slots:          make(chan struct{}, size),
+		healthInterval: healthInterval,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+
+	browserCtx, browserCancel, err := p.launch()
+	if err != nil {
+		return nil, err
+	}
+	p.browserCtx, p.browserCancel = browserCtx, browserCancel
+
+	go p.monitor()
+
+	return p, nil
+}
+
+// Generate implements Backend by rendering in a fresh tab of the shared browser.
+// A render that fails on an unhealthy browser is retried once after a restart.
+func (p *Pool) Generate(ctx context.Context, htmlContent string, opts Options) ([]byte, error) {
+	p.waiting.Add(1)
+	select {
+	case p.slots <- struct{}{}:
+		p.waiting.Add(-1)
+	case <-ctx.Done():
+		p.waiting.Add(-1)
+		return nil, ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	browserCtx, gen, err := p.browser()
+	if err == nil {
+		var pdfBuf []byte
+		pdfBuf, err = p.renderInTab(ctx, browserCtx, htmlContent, opts)
+		if err == nil || ctx.Err() != nil {
+			return pdfBuf, err
+		}
+		healthErr := checkHealth(browserCtx)
+		if healthErr == nil {
+			return nil, err
+		}
+		log.Printf("Warning: Chrome unhealthy after failed render (%v), restarting", healthErr)
+	} else if errors.Is(err, ErrPoolClosed) {
+		return nil, err
+	}
+
+	// Another render or the monitor may have restarted the browser already
+	if restartErr := p.restart(gen); restartErr != nil {
+		return nil, fmt.Errorf("%w (restart failed: %v)", err, restartErr)
+	}
+	browserCtx, _, err = p.browser()
+	if err != nil {
+		return nil, err
+	}
+	return p.renderInTab(ctx, browserCtx, htmlContent, opts)
+}
+
+// Restarts returns how many times the browser has been restarted.
+func (p *Pool) Restarts() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.restarts
+}
+
+// Queued returns how many renders are waiting for a free tab.
+func (p *Pool) Queued() int {
+	return int(p.waiting.Load())
+}
+
+// Active returns how many tabs are rendering.
+func (p *Pool) Active() int {
+	return len(p.slots)
+}
+
+// Close shuts down the browser and stops health checks.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	cancel := p.browserCancel
+	p.mu.Unlock()
+
+	close(p.stop)
+	<-p.done
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// renderInTab opens a new tab of a browser, prints the document, and closes the tab.
+func (p *Pool) renderInTab(ctx, browserCtx context.Context, htmlContent string, opts Options) ([]byte, error) {
+	tabCtx, tabCancel := chromedp.NewContext(browserCtx)
+	defer tabCancel()
+
+	// Tie the tab's lifetime to the caller's context as well as the browser's,
+	// keeping the cause so an expired deadline is reported as one
+	tabCtx, cancelCause := context.WithCancelCause(tabCtx)
+	defer cancelCause(nil)
+	stopAfter := context.AfterFunc(ctx, func() { cancelCause(context.Cause(ctx)) })
+	defer stopAfter()
+
+	tabCtx, timeoutCancel := withTimeout(tabCtx, opts)
+	defer timeoutCancel()
+
+	if opts.inlineDocument() {
+		return generatePDF(tabCtx, setDocumentContent(htmlContent), opts)
+	}
+
+	tmpFile, err := writeTempHTML(htmlContent)
+	if err != nil {
+		return nil, err
+	}
+	defer removeTemp(tmpFile)
+
+	fileURL, err := fileurl.FromPath(tmpFile)
+	if err != nil {
+		return nil, err
+	}
+	return generatePDF(tabCtx, chromedp.Navigate(fileURL), opts)
+}
+
+// errNoBrowser is returned while the browser is down after a failed restart
+var errNoBrowser = errors.New("chrome is not running")
+
+// browser returns the current browser context and its generation.
+func (p *Pool) browser() (context.Context, int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.closed:
+		return nil, p.generation, ErrPoolClosed
+	case p.browserCtx == nil:
+		return nil, p.generation, errNoBrowser
+	}
+	return p.browserCtx, p.generation, nil
+}
+
+// launch starts (or connects to) Chrome and opens its initial target.
+func (p *Pool) launch() (context.Context, context.CancelFunc, error) {
+	var (
+		allocCtx    context.Context
+		allocCancel context.CancelFunc
+	)
+
+	if p.opts.RemoteDebuggingURL != "" {
+		allocCtx, allocCancel = chromedp.NewRemoteAllocator(context.Background(), p.opts.RemoteDebuggingURL)
+	} else {
+		allocCtx, allocCancel = chromedp.NewExecAllocator(context.Background(), execAllocatorOptions(p.opts)...)
+	}
+
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+	if err := chromedp.Run(browserCtx); err != nil {
+		browserCancel()
+		allocCancel()
+		return nil, nil, fmt.Errorf("start chrome: %w", err)
+	}
+
+	return browserCtx, func() {
+		browserCancel()
+		allocCancel()
+	}, nil
+}
+
+// restart replaces the browser of generation gen with a fresh instance. It
+// does nothing when that browser was already replaced, so concurrent failures
+// restart it once. A failed start leaves no browser until the next restart.
+func (p *Pool) restart(gen int) error {
+	p.restartMu.Lock()
+	defer p.restartMu.Unlock()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+	if p.generation != gen {
+		p.mu.Unlock()
+		return nil
+	}
+	oldCancel := p.browserCancel
+	p.browserCtx, p.browserCancel = nil, nil
+	p.generation++
+	p.restarts++
+	p.mu.Unlock()
+
+	if oldCancel != nil {
+		oldCancel()
+	}
+	browserCtx, browserCancel, err := p.launch()
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		// Close ran while Chrome started and won't stop this one
+		browserCancel()
+		return ErrPoolClosed
+	}
+	p.browserCtx, p.browserCancel = browserCtx, browserCancel
+	return nil
+}
+
+// checkHealth verifies a browser still answers DevTools commands.
+func checkHealth(browserCtx context.Context) error {
+	if err := browserCtx.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(browserCtx, 5*time.Second)
+	defer cancel()
+
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, _, _, _, _, err := browser.GetVersion().Do(ctx)
+		return err
+	}))
+}
+
+// monitor periodically health checks the browser and restarts it when it stops responding.
+func (p *Pool) monitor() {
+	defer close(p.done)
+
+	if p.healthInterval <= 0 {
+		<-p.stop
+		return
+	}
+
+	ticker := time.NewTicker(p.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			browserCtx, gen, err := p.browser()
+			if errors.Is(err, ErrPoolClosed) {
+				continue
+			}
+			if err == nil {
+				err = checkHealth(browserCtx)
+			}
+			if err != nil {
+				log.Printf("Warning: Chrome health check failed (%v), restarting", err)
+				if err := p.restart(gen); err != nil && !errors.Is(err, ErrPoolClosed) {
+					log.Printf("Warning: Chrome restart failed: %v", err)
+				}
+			}
+		}
+	}
+}
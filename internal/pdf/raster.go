@@ -0,0 +1,87 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultThumbnailWidth is the thumbnail width in pixels used when none is given.
+const DefaultThumbnailWidth = 240
+
+// Thumbnail renders the first page of a PDF to a PNG image width pixels wide,
+// using pdftoppm from poppler (PDFTOPPM_BIN or PATH).
+func Thumbnail(ctx context.Context, pdfPath, pngPath string, width int) error {
+	if width <= 0 {
+		width = DefaultThumbnailWidth
+	}
+
+	if err := os.MkdirAll(filepath.Dir(pngPath), 0o755); err != nil {
+		return fmt.Errorf("create thumbnail directory: %w", err)
+	}
+
+	// pdftoppm appends the extension itself
+	prefix := strings.TrimSuffix(pngPath, filepath.Ext(pngPath))
+
+	_, err := runPoppler(ctx, "pdftoppm",
+		"-png", "-singlefile",
+		"-f", "1", "-l", "1",
+		"-scale-to-x", strconv.Itoa(width), "-scale-to-y", "-1",
+		pdfPath, prefix)
+	if err != nil {
+		return err
+	}
+
+	if generated := prefix + ".png"; generated != pngPath {
+		if err := os.Rename(generated, pngPath); err != nil {
+			return fmt.Errorf("move thumbnail: %w", err)
+		}
+	}
+	return nil
+}
+
+// Rasterize renders every page of a PDF to PNG files in outDir at the given
+// resolution and returns their paths in page order.
+func Rasterize(ctx context.Context, pdfPath, outDir string, dpi int) ([]string, error) {
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create raster directory: %w", err)
+	}
+
+	if _, err := runPoppler(ctx, "pdftoppm", "-png", "-r", strconv.Itoa(dpi), pdfPath, filepath.Join(outDir, "page")); err != nil {
+		return nil, err
+	}
+
+	// pdftoppm zero-pads page numbers to the width of the page count, so
+	// lexical order is page order
+	pages, err := filepath.Glob(filepath.Join(outDir, "page-*.png"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(pages)
+	return pages, nil
+}
+
+// runPoppler runs a poppler command line tool (from <TOOL>_BIN or PATH) and
+// returns its standard output.
+func runPoppler(ctx context.Context, tool string, args ...string) ([]byte, error) {
+	bin := os.Getenv(strings.ToUpper(tool) + "_BIN")
+	if bin == "" {
+		bin = tool
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", tool, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
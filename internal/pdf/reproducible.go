@@ -0,0 +1,83 @@
+package pdf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	pdfDateRegex = regexp.MustCompile(`(/(?:CreationDate|ModDate)\s*\(D:)(\d+)([^)]*)\)`)
+	xmpDateRegex = regexp.MustCompile(`(<xmp:(?:CreateDate|ModifyDate|MetadataDate)>)([^<]+)(</xmp:)`)
+	docIDRegex   = regexp.MustCompile(`/ID\s*\[\s*<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>\s*\]`)
+)
+
+// Normalize rewrites the volatile parts of a PDF so identical input produces
+// identical bytes: creation and modification dates are set to date (UTC) and
+// the document ID is replaced with a hash of the normalized content.
+// Every replacement keeps its original length, so xref offsets stay valid.
+func Normalize(data []byte, date time.Time) []byte {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	digits := date.UTC().Format("20060102150405")
+
+	out = pdfDateRegex.ReplaceAllFunc(out, func(m []byte) []byte {
+		parts := pdfDateRegex.FindSubmatch(m)
+		n := len(parts[2])
+		fixed := digits
+		if n < len(fixed) {
+			fixed = fixed[:n]
+		}
+		fixed += strings.Repeat("0", n-len(fixed))
+
+		// Zero the timezone offset: +hh'mm' becomes +00'00'
+		tz := []byte(string(parts[3]))
+		for i, c := range tz {
+			switch {
+			case c >= '0' && c <= '9':
+				tz[i] = '0'
+			case c == '-':
+				tz[i] = '+'
+			}
+		}
+
+		return []byte(string(parts[1]) + fixed + string(tz) + ")")
+	})
+
+	out = xmpDateRegex.ReplaceAllFunc(out, func(m []byte) []byte {
+		parts := xmpDateRegex.FindSubmatch(m)
+		n := len(parts[2])
+		fixed := date.UTC().Format("2006-01-02T15:04:05Z")
+		if len(fixed) > n {
+			fixed = fixed[:n]
+		}
+		fixed += strings.Repeat(" ", n-len(fixed))
+		return []byte(string(parts[1]) + fixed + string(parts[3]))
+	})
+
+	if loc := docIDRegex.FindSubmatchIndex(out); loc != nil {
+		// Hash the document with the ID blanked, so the ID depends only on content
+		blanked := make([]byte, len(out))
+		copy(blanked, out)
+		for i := loc[2]; i < loc[3]; i++ {
+			blanked[i] = '0'
+		}
+		for i := loc[4]; i < loc[5]; i++ {
+			blanked[i] = '0'
+		}
+
+		sum := sha256.Sum256(blanked)
+		id := strings.ToUpper(hex.EncodeToString(sum[:]))
+		for len(id) < loc[3]-loc[2] || len(id) < loc[5]-loc[4] {
+			id += id
+		}
+
+		copy(out[loc[2]:loc[3]], id[:loc[3]-loc[2]])
+		copy(out[loc[4]:loc[5]], id[:loc[5]-loc[4]])
+	}
+
+	return out
+}
@@ -0,0 +1,81 @@
+package pdf
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// Resource is a request the page made while rendering.
+type Resource struct {
+	URL      string
+	Type     string // Image, Stylesheet, Font, Script, Fetch, ...
+	Status   int    // HTTP status; 0 for local files and failures
+	Bytes    int64  // bytes received over the wire
+	External bool   // loaded over the network rather than from a local file
+	Error    string // why loading failed, e.g. net::ERR_BLOCKED_BY_CLIENT
+}
+
+// resourceLog records the requests of a page in the order they were made
+type resourceLog struct {
+	mu        sync.Mutex
+	order     []network.RequestID
+	resources map[network.RequestID]*Resource
+	document  bool // the document itself has been seen
+}
+
+// listen records the requests of the page in ctx; call before it navigates
+func (l *resourceLog) listen(ctx context.Context) chromedp.Action {
+	l.resources = make(map[network.RequestID]*Resource)
+	chromedp.ListenTarget(ctx, func(ev any) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		switch ev := ev.(type) {
+		case *network.EventRequestWillBeSent:
+			// Leave out the document itself and inline data, which are self-contained
+			if ev.Type == network.ResourceTypeDocument && !l.document {
+				l.document = true
+				return
+			}
+			if strings.HasPrefix(ev.Request.URL, "data:") {
+				return
+			}
+			if _, ok := l.resources[ev.RequestID]; !ok {
+				l.order = append(l.order, ev.RequestID)
+			}
+			// A redirect reuses the request ID; the final URL is what loaded
+			l.resources[ev.RequestID] = &Resource{URL: ev.Request.URL, Type: string(ev.Type), External: isNetworkURL(ev.Request.URL)}
+		case *network.EventResponseReceived:
+			if r := l.resources[ev.RequestID]; r != nil {
+				r.Status = int(ev.Response.Status)
+				if strings.HasPrefix(ev.Response.URL, "file:") {
+					r.Status = 0
+				}
+			}
+		case *network.EventLoadingFinished:
+			if r := l.resources[ev.RequestID]; r != nil {
+				r.Bytes = int64(ev.EncodedDataLength)
+			}
+		case *network.EventLoadingFailed:
+			if r := l.resources[ev.RequestID]; r != nil {
+				r.Error = ev.ErrorText
+			}
+		}
+	})
+	return network.Enable()
+}
+
+// list returns the recorded requests
+func (l *resourceLog) list() []Resource {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	resources := make([]Resource, 0, len(l.order))
+	for _, id := range l.order {
+		resources = append(resources, *l.resources[id])
+	}
+	return resources
+}
@@ -0,0 +1,16 @@
+package pdf
+
+import (
+	"context"
+	"strings"
+)
+
+// ExtractText returns the text of a PDF with whitespace collapsed to single
+// spaces, using pdftotext from poppler (PDFTOTEXT_BIN or PATH).
+func ExtractText(ctx context.Context, pdfPath string) (string, error) {
+	out, err := runPoppler(ctx, "pdftotext", "-enc", "UTF-8", "-q", pdfPath, "-")
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(string(out)), " "), nil
+}
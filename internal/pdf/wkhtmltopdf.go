@@ -0,0 +1,88 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// WkhtmltopdfBackend renders PDFs with the wkhtmltopdf binary.
+type WkhtmltopdfBackend struct {
+	// Path to the wkhtmltopdf binary (uses WKHTMLTOPDF_BIN or PATH if empty)
+	Bin string
+}
+
+// Generate implements Backend.
+func (b WkhtmltopdfBackend) Generate(ctx context.Context, htmlContent string, opts Options) ([]byte, error) {
+	tmpFile, err := writeTempHTML(htmlContent)
+	if err != nil {
+		return nil, err
+	}
+	defer removeTemp(tmpFile)
+
+	bin := b.Bin
+	if bin == "" {
+		bin = os.Getenv("WKHTMLTOPDF_BIN")
+	}
+	if bin == "" {
+		bin = "wkhtmltopdf"
+	}
+
+	ctx, cancel := withTimeout(ctx, opts)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, wkhtmltopdfArgs(tmpFile, opts)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("wkhtmltopdf: timed out after %s (timeout %s): %w",
+				time.Since(start).Round(time.Millisecond), effectiveTimeout(opts), context.DeadlineExceeded)
+		}
+		return nil, fmt.Errorf("wkhtmltopdf: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// wkhtmltopdfArgs maps Options to wkhtmltopdf command line flags.
+func wkhtmltopdfArgs(htmlPath string, opts Options) []string {
+	args := []string{
+		"--quiet",
+		"--encoding", "utf-8",
+		"--page-width", inches(opts.PaperWidth),
+		"--page-height", inches(opts.PaperHeight),
+		"--margin-top", inches(opts.MarginTop),
+		"--margin-bottom", inches(opts.MarginBottom),
+		"--margin-left", inches(opts.MarginLeft),
+		"--margin-right", inches(opts.MarginRight),
+	}
+
+	if !opts.PrintBackground {
+		args = append(args, "--no-background")
+	}
+	if !opts.Sandboxed {
+		args = append(args, "--enable-local-file-access")
+	}
+	if opts.ProxyServer != "" {
+		args = append(args, "--proxy", opts.ProxyServer)
+	}
+	for _, host := range strings.FieldsFunc(opts.ProxyBypass, func(r rune) bool { return r == ';' || r == ',' }) {
+		args = append(args, "--bypass-proxy-for", strings.TrimSpace(host))
+	}
+
+	return append(args, htmlPath, "-")
+}
+
+// inches formats a length in inches for command line tools.
+func inches(v float64) string {
+	return fmt.Sprintf("%gin", v)
+}
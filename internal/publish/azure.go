@@ -0,0 +1,46 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// azureStore uploads block blobs to Azure Blob Storage with a SAS token
+type azureStore struct {
+	base string // account endpoint and container
+	sas  string
+}
+
+// newAzure creates an Azure store from AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_SAS_TOKEN
+func newAzure(cfg Config) (*azureStore, error) {
+	account := os.Getenv("AZURE_STORAGE_ACCOUNT")
+	sas := strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?")
+	if sas == "" || (account == "" && cfg.Endpoint == "") {
+		return nil, fmt.Errorf("azure needs AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_SAS_TOKEN")
+	}
+
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = "https://" + account + ".blob.core.windows.net"
+	}
+	return &azureStore{base: endpoint + "/" + cfg.Bucket, sas: sas}, nil
+}
+
+func (s *azureStore) url(key string) string {
+	return s.base + "/" + escapePath(key)
+}
+
+func (s *azureStore) put(ctx context.Context, key, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.url(key)+"?"+s.sas, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	req.Header.Set("X-Ms-Version", "2021-08-06")
+	return do(req)
+}
@@ -0,0 +1,152 @@
+// Package publish uploads generated files to cloud object storage.
+package publish
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Config selects the storage a job publishes to.
+type Config struct {
+	Provider  string // s3 | gcs | azure
+	Bucket    string // bucket, or Azure container
+	Prefix    string // key prefix, e.g. docs/main/
+	Region    string // S3 region; defaults to AWS_REGION, then us-east-1
+	Endpoint  string // S3-compatible or Azure endpoint, e.g. http://minio:9000
+	PublicURL string // base URL the published files are served from, e.g. a CDN
+
+	// ContentTypes maps file extensions to Content-Type overrides
+	ContentTypes map[string]string
+}
+
+// defaultContentTypes covers the files the actions generate
+var defaultContentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".zip":  "application/zip",
+	".html": "text/html; charset=utf-8",
+	".md":   "text/markdown; charset=utf-8",
+	".json": "application/json",
+	".png":  "image/png",
+}
+
+// store writes objects to one provider
+type store interface {
+	// put uploads body under key
+	put(ctx context.Context, key, contentType string, body []byte) error
+	// url returns the provider's URL of key
+	url(key string) string
+}
+
+// Publisher uploads files to a configured bucket.
+type Publisher struct {
+	cfg   Config
+	store store
+}
+
+// New creates a publisher, reading credentials from the environment:
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN for s3;
+// GCS_HMAC_ACCESS_ID and GCS_HMAC_SECRET for gcs; AZURE_STORAGE_ACCOUNT and
+// AZURE_STORAGE_SAS_TOKEN for azure.
+func New(cfg Config) (*Publisher, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+
+	var (
+		s   store
+		err error
+	)
+	switch cfg.Provider {
+	case "s3":
+		s, err = newS3(cfg)
+	case "gcs":
+		s, err = newGCS(cfg)
+	case "azure":
+		s, err = newAzure(cfg)
+	default:
+		return nil, fmt.Errorf("unknown provider %q (use s3, gcs, or azure)", cfg.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{cfg: cfg, store: s}, nil
+}
+
+// Key returns the object key of a file published under name.
+func (p *Publisher) Key(name string) string {
+	return path.Join(p.cfg.Prefix, filepath.ToSlash(name))
+}
+
+// Upload publishes the file at filePath under name and returns its public URL.
+func (p *Publisher) Upload(ctx context.Context, filePath, name string) (string, error) {
+	body, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", filePath, err)
+	}
+
+	key := p.Key(name)
+	if err := p.store.put(ctx, key, p.ContentType(filePath), body); err != nil {
+		return "", fmt.Errorf("upload %s: %w", key, err)
+	}
+
+	if p.cfg.PublicURL != "" {
+		return strings.TrimSuffix(p.cfg.PublicURL, "/") + "/" + escapePath(key), nil
+	}
+	return p.store.url(key), nil
+}
+
+// ContentType returns the Content-Type a file is published with.
+func (p *Publisher) ContentType(filePath string) string {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	for e, t := range p.cfg.ContentTypes {
+		if strings.ToLower("."+strings.TrimPrefix(e, ".")) == ext {
+			return t
+		}
+	}
+	if t, ok := defaultContentTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// do sends an upload request and turns non-2xx responses into errors
+func do(req *http.Request) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// escapePath percent-encodes an object key for use in a URL, keeping slashes
+func escapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,144 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3Store uploads to S3 or an S3-compatible API with Signature Version 4
+type s3Store struct {
+	bucket   string
+	region   string
+	endpoint string // custom endpoint; objects are addressed path-style
+	keyID    string
+	secret   string
+	token    string
+}
+
+// newS3 creates an S3 store from the AWS credential variables
+func newS3(cfg Config) (*s3Store, error) {
+	s := &s3Store{
+		bucket:   cfg.Bucket,
+		region:   cfg.Region,
+		endpoint: strings.TrimSuffix(cfg.Endpoint, "/"),
+		keyID:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secret:   os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if s.region == "" {
+		s.region = os.Getenv("AWS_REGION")
+	}
+	if s.region == "" {
+		s.region = "us-east-1"
+	}
+	if s.keyID == "" || s.secret == "" {
+		return nil, fmt.Errorf("s3 needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	return s, nil
+}
+
+// newGCS creates a store for the S3-compatible XML API of Google Cloud Storage,
+// authenticated with an HMAC key
+func newGCS(cfg Config) (*s3Store, error) {
+	s := &s3Store{
+		bucket:   cfg.Bucket,
+		region:   "auto",
+		endpoint: strings.TrimSuffix(cfg.Endpoint, "/"),
+		keyID:    os.Getenv("GCS_HMAC_ACCESS_ID"),
+		secret:   os.Getenv("GCS_HMAC_SECRET"),
+	}
+	if s.endpoint == "" {
+		s.endpoint = "https://storage.googleapis.com"
+	}
+	if s.keyID == "" || s.secret == "" {
+		return nil, fmt.Errorf("gcs needs GCS_HMAC_ACCESS_ID and GCS_HMAC_SECRET")
+	}
+	return s, nil
+}
+
+func (s *s3Store) url(key string) string {
+	if s.endpoint != "" {
+		return s.endpoint + "/" + s.bucket + "/" + escapePath(key)
+	}
+	return "https://" + s.bucket + ".s3." + s.region + ".amazonaws.com/" + escapePath(key)
+}
+
+func (s *s3Store) put(ctx context.Context, key, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.url(key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, body, time.Now().UTC())
+	return do(req)
+}
+
+// sign adds Signature Version 4 authentication headers to req
+func (s *s3Store) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.token != "" {
+		req.Header.Set("X-Amz-Security-Token", s.token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.secret), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.keyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,43 @@
+// Package sanitize provides HTML sanitization for rendering untrusted content.
+package sanitize
+
+import (
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+var policy = newPolicy()
+
+// newPolicy builds a policy for user-generated markdown output that keeps
+// syntax highlighting, heading anchors, and math markup intact.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+
+	// Heading IDs, code language classes, and math spans
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\w\-:.]+$`)).Globally()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[\w\- ]+$`)).Globally()
+
+	// Inline styles emitted by the syntax highlighter
+	p.AllowStyles("color", "background-color", "font-weight", "font-style", "text-decoration").Globally()
+
+	// Page breaks between combined documents
+	p.AllowStyles("page-break-before", "page-break-after", "break-before", "break-after", "visibility").Globally()
+
+	// Image sizes from markdown attributes, e.g. {width=50%}
+	p.AllowStyles("width", "height", "max-width", "max-height").OnElements("img")
+
+	// QR codes generated from qr: image sources after sanitizing
+	p.AllowURLSchemes("qr")
+
+	// Task list checkboxes
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return p
+}
+
+// HTML removes scripts, event handlers, and other unsafe markup from HTML.
+func HTML(htmlContent string) string {
+	return policy.Sanitize(htmlContent)
+}
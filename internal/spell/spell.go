@@ -0,0 +1,219 @@
+// Package spell checks the prose of markdown sources with hunspell and with
+// Vale style rules.
+package spell
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Finding is one misspelling or style alert in a source file.
+type Finding struct {
+	Path    string
+	Line    int
+	Rule    string // "spelling", or the Vale check, e.g. "Microsoft.Contractions"
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", f.Path, f.Line, f.Rule, f.Message)
+}
+
+var (
+	fenceRegex      = regexp.MustCompile("^\\s*(```|~~~)")
+	referenceRegex  = regexp.MustCompile(`^\s*\[[^\]]+\]:\s`)
+	inlineCodeRegex = regexp.MustCompile("`+[^`]*`+")
+	linkTargetRegex = regexp.MustCompile(`\]\([^)]*\)`)
+	urlRegex        = regexp.MustCompile(`(?i)\b(https?|ftp|mailto|file):\S+|www\.\S+|\S+@\S+\.\w+`)
+	tagRegex        = regexp.MustCompile(`<!--.*?-->|</?[a-zA-Z][^>]*>`)
+
+	// markup drops emphasis, heading, quote, and table markers
+	markup = strings.NewReplacer("*", " ", "_", " ", "#", " ", ">", " ", "|", " ")
+)
+
+// Prose returns the lines of a markdown document with front matter, code,
+// URLs, link targets, and HTML tags blanked out, so line numbers still match
+// the source.
+func Prose(src []byte) []string {
+	lines := strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+	prose := make([]string, len(lines))
+
+	frontMatter := len(lines) > 0 && strings.TrimSpace(lines[0]) == "---"
+	fence := ""
+	for i, line := range lines {
+		switch {
+		case frontMatter:
+			if i > 0 && strings.TrimSpace(line) == "---" {
+				frontMatter = false
+			}
+			continue
+		case fence != "":
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := fenceRegex.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			continue
+		}
+		if referenceRegex.MatchString(line) {
+			continue
+		}
+
+		line = inlineCodeRegex.ReplaceAllString(line, " ")
+		line = linkTargetRegex.ReplaceAllString(line, "] ")
+		line = urlRegex.ReplaceAllString(line, " ")
+		line = tagRegex.ReplaceAllString(line, " ")
+		prose[i] = markup.Replace(line)
+	}
+	return prose
+}
+
+// LoadDictionary reads accepted words, one per line; "#" starts a comment.
+func LoadDictionary(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	words := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if word := strings.TrimSpace(line); word != "" {
+			words[word] = true
+		}
+	}
+	return words, nil
+}
+
+// accepted reports whether a word is in the dictionary as written, or in
+// lower case for words capitalized at the start of a sentence
+func accepted(words map[string]bool, word string) bool {
+	if words[word] {
+		return true
+	}
+	r := []rune(word)
+	return unicode.IsUpper(r[0]) && words[strings.ToLower(word)]
+}
+
+// Hunspell checks the prose of a markdown file against the hunspell dictionary
+// lang (e.g. "en_US") with the hunspell binary bin. Each misspelled word is
+// reported once, at its first line, with hunspell's suggestions.
+func Hunspell(ctx context.Context, bin, lang string, words map[string]bool, path string) ([]Finding, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	prose := Prose(src)
+
+	// In pipe mode ("-a") hunspell answers each input line with one line per
+	// word and an empty line; "^" keeps lines from being read as commands
+	var input bytes.Buffer
+	for _, line := range prose {
+		input.WriteString("^" + line + "\n")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, "-a", "-d", lang)
+	cmd.Stdin = &input
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("hunspell: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(nil, 1024*1024)
+	if !scanner.Scan() {
+		return nil, errors.New("hunspell: no output")
+	}
+	// The first line is the version banner
+
+	var findings []Finding
+	seen := make(map[string]bool)
+	line := 0
+	for scanner.Scan() && line < len(prose) {
+		text := scanner.Text()
+		if text == "" {
+			line++
+			continue
+		}
+
+		var word, suggestions string
+		switch text[0] {
+		case '&': // & word count offset: suggestion, ...
+			head, tail, _ := strings.Cut(text, ": ")
+			fields := strings.Fields(head)
+			if len(fields) < 2 {
+				continue
+			}
+			word, suggestions = fields[1], tail
+		case '#': // # word offset
+			fields := strings.Fields(text)
+			if len(fields) < 2 {
+				continue
+			}
+			word = fields[1]
+		default:
+			continue
+		}
+		if seen[word] || accepted(words, word) {
+			continue
+		}
+		seen[word] = true
+
+		message := fmt.Sprintf("%q is not in the dictionary", word)
+		if suggestions != "" {
+			message += " (did you mean " + suggestions + "?)"
+		}
+		findings = append(findings, Finding{Path: path, Line: line + 1, Rule: "spelling", Message: message})
+	}
+	return findings, scanner.Err()
+}
+
+// valeAlert is an alert in Vale's JSON output
+type valeAlert struct {
+	Check   string
+	Line    int
+	Match   string
+	Message string
+}
+
+// Vale checks files against the style rules of the Vale configuration config
+// (a .vale.ini) with the vale binary bin.
+func Vale(ctx context.Context, bin, config string, words map[string]bool, paths []string) ([]Finding, error) {
+	var stdout, stderr bytes.Buffer
+	args := append([]string{"--config=" + config, "--output=JSON", "--no-exit"}, paths...)
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("vale: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var alerts map[string][]valeAlert
+	if err := json.Unmarshal(stdout.Bytes(), &alerts); err != nil {
+		return nil, fmt.Errorf("vale: read output: %w", err)
+	}
+
+	var findings []Finding
+	for _, path := range slices.Sorted(maps.Keys(alerts)) {
+		for _, a := range alerts[path] {
+			if a.Match != "" && accepted(words, a.Match) {
+				continue
+			}
+			findings = append(findings, Finding{Path: path, Line: a.Line, Rule: a.Check, Message: a.Message})
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,97 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/images"
+)
+
+var (
+	stylesheetRegex = regexp.MustCompile(`<link\s+[^>]*rel=["']?stylesheet["']?[^>]*>`)
+	hrefRegex       = regexp.MustCompile(`href=["']([^"']+)["']`)
+	scriptRegex     = regexp.MustCompile(`<script(\s+[^>]*?)\s*src=["']([^"']+)["']([^>]*)>\s*</script>`)
+)
+
+// InlineAssets replaces the local stylesheets, scripts, images, and fonts an
+// HTML template references relative to dir with their content, so the
+// rendered document needs no files. Remote URLs and template actions are left
+// as they are.
+func InlineAssets(html, dir string) string {
+	html = stylesheetRegex.ReplaceAllStringFunc(html, func(tag string) string {
+		m := hrefRegex.FindStringSubmatch(tag)
+		if m == nil || !isLocalAsset(m[1]) {
+			return tag
+		}
+
+		path := filepath.Join(dir, m[1])
+		css, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Warning: failed to inline stylesheet %s: %v", m[1], err)
+			return tag
+		}
+		// Stylesheets resolve their url()s relative to themselves
+		return "<style>\n" + images.EmbedCSSURLs(string(css), filepath.Dir(path)) + "\n</style>"
+	})
+
+	html = scriptRegex.ReplaceAllStringFunc(html, func(tag string) string {
+		m := scriptRegex.FindStringSubmatch(tag)
+		if !isLocalAsset(m[2]) {
+			return tag
+		}
+
+		js, err := os.ReadFile(filepath.Join(dir, m[2]))
+		if err != nil {
+			log.Printf("Warning: failed to inline script %s: %v", m[2], err)
+			return tag
+		}
+		return "<script" + m[1] + m[3] + ">\n" + string(js) + "\n</script>"
+	})
+
+	// Images, srcset candidates, and url()s in <style> blocks and style attributes
+	html, _ = images.EmbedImagesAsBase64(html, dir)
+	return html
+}
+
+// isLocalAsset reports whether a reference names a file next to the template
+func isLocalAsset(ref string) bool {
+	return !images.IsAbsoluteOrDataURL(ref) && !strings.HasPrefix(ref, "//") && !strings.Contains(ref, "{{")
+}
+
+// LoadWithAssets reads and parses a template file after inlining the assets it
+// references (see InlineAssets).
+func (l *Loader) LoadWithAssets(name string) (*template.Template, error) {
+	path := filepath.Join(l.dir, name)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read template %s: %w", name, err)
+	}
+
+	tmpl, err := template.New(name).Parse(InlineAssets(string(content), l.dir))
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+// RenderWithAssets loads a template with its assets inlined and executes it.
+func (l *Loader) RenderWithAssets(name string, data any) (string, error) {
+	tmpl, err := l.LoadWithAssets(name)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute template %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,256 @@
+package templates
+
+import (
+	"html/template"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// FieldPath is a chain of field names read from the template data, e.g. Customer.Name
+type FieldPath []string
+
+func (p FieldPath) String() string { return strings.Join(p, ".") }
+
+// Report lists the problems found in a template by Check
+type Report struct {
+	Undefined []string // fields the template reads that the data lacks
+	Unused    []string // top-level data fields the template never reads
+	Err       error    // execution error with the sample data
+}
+
+// OK reports whether the template can be executed with the data. Unused
+// fields are not an error.
+func (r Report) OK() bool { return len(r.Undefined) == 0 && r.Err == nil }
+
+// String lists the problems of the report, e.g. "undefined fields: Name; unused fields: Date"
+func (r Report) String() string {
+	var problems []string
+	if len(r.Undefined) > 0 {
+		problems = append(problems, "undefined fields: "+strings.Join(r.Undefined, ", "))
+	}
+	if len(r.Unused) > 0 {
+		problems = append(problems, "unused fields: "+strings.Join(r.Unused, ", "))
+	}
+	if r.Err != nil {
+		problems = append(problems, r.Err.Error())
+	}
+	return strings.Join(problems, "; ")
+}
+
+// Check validates a template against sample data: every field the template
+// reads, in branches not taken too, must exist in the data, and it must
+// execute without error.
+func Check(tmpl *template.Template, data any) Report {
+	var report Report
+
+	fields := Fields(tmpl)
+	referenced := make(map[string]bool)
+	for _, p := range fields {
+		referenced[p[0]] = true
+		if !HasPath(data, p) {
+			report.Undefined = append(report.Undefined, p.String())
+		}
+	}
+	for _, name := range topLevelFields(data) {
+		if !referenced[name] {
+			report.Unused = append(report.Unused, name)
+		}
+	}
+
+	// Undefined fields would only repeat as an execution error
+	if len(report.Undefined) == 0 {
+		if strict, err := tmpl.Clone(); err != nil {
+			report.Err = err
+		} else if err := strict.Option("missingkey=error").Execute(io.Discard, data); err != nil {
+			report.Err = err
+		}
+	}
+
+	return report
+}
+
+// Fields returns the field paths the template reads from its data.
+// Fields inside range and with blocks are relative to a different dot and are
+// only included when referenced through $.
+func Fields(tmpl *template.Template) []FieldPath {
+	seen := make(map[string]FieldPath)
+	if tmpl.Tree != nil {
+		collectFields(tmpl.Tree.Root, true, seen)
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	paths := make([]FieldPath, 0, len(keys))
+	for _, k := range keys {
+		paths = append(paths, seen[k])
+	}
+	return paths
+}
+
+// collectFields walks a template parse tree; rootDot is false inside blocks that rebind dot
+func collectFields(node parse.Node, rootDot bool, seen map[string]FieldPath) {
+	add := func(idents []string) {
+		if len(idents) > 0 {
+			p := FieldPath(slices.Clone(idents))
+			seen[p.String()] = p
+		}
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(child, rootDot, seen)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, rootDot, seen)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectFields(cmd, rootDot, seen)
+		}
+	case *parse.CommandNode:
+		// index . "Key" "Nested" reads keys that aren't valid identifiers
+		if len(n.Args) > 2 && rootDot {
+			if fn, ok := n.Args[0].(*parse.IdentifierNode); ok && fn.Ident == "index" {
+				if _, ok := n.Args[1].(*parse.DotNode); ok {
+					var keys []string
+					for _, arg := range n.Args[2:] {
+						str, ok := arg.(*parse.StringNode)
+						if !ok {
+							break
+						}
+						keys = append(keys, str.Text)
+					}
+					add(keys)
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			collectFields(arg, rootDot, seen)
+		}
+	case *parse.FieldNode:
+		if rootDot {
+			add(n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			add(n.Ident[1:])
+		}
+	case *parse.IfNode:
+		collectFields(n.Pipe, rootDot, seen)
+		collectFields(n.List, rootDot, seen)
+		collectFields(n.ElseList, rootDot, seen)
+	case *parse.RangeNode:
+		collectFields(n.Pipe, rootDot, seen)
+		collectFields(n.List, false, seen)
+		collectFields(n.ElseList, rootDot, seen)
+	case *parse.WithNode:
+		collectFields(n.Pipe, rootDot, seen)
+		collectFields(n.List, false, seen)
+		collectFields(n.ElseList, rootDot, seen)
+	case *parse.TemplateNode:
+		collectFields(n.Pipe, rootDot, seen)
+	}
+}
+
+// HasPath reports whether the nested field path exists in data: map keys,
+// exported struct fields, or methods. Nil pointers are followed by type.
+func HasPath(data any, path FieldPath) bool {
+	v := reflect.ValueOf(data)
+	for _, key := range path {
+		if !v.IsValid() {
+			return false
+		}
+		if v.Kind() != reflect.Pointer && v.Kind() != reflect.Interface && v.CanAddr() {
+			v = v.Addr()
+		}
+		if m := methodByName(v, key); m.IsValid() {
+			// Methods end the path; their results aren't known statically
+			return true
+		}
+		v = indirect(v)
+
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return false
+			}
+			v = v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return false
+			}
+		case reflect.Struct:
+			f, ok := v.Type().FieldByName(key)
+			if !ok || !f.IsExported() {
+				return false
+			}
+			v = v.FieldByIndex(f.Index)
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// methodByName returns the exported method key of v, if any
+func methodByName(v reflect.Value, key string) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		v = reflect.New(v.Type().Elem())
+	}
+	return v.MethodByName(key)
+}
+
+// indirect follows pointers and interfaces; nil pointers become zero values
+// so their type can still be inspected
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if v.Kind() == reflect.Interface {
+				return reflect.Value{}
+			}
+			v = reflect.Zero(v.Type().Elem())
+			continue
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// topLevelFields lists the keys of map data or the exported fields of struct data
+func topLevelFields(data any) []string {
+	v := indirect(reflect.ValueOf(data))
+
+	var names []string
+	switch v.Kind() {
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			if k.Kind() == reflect.String {
+				names = append(names, k.String())
+			}
+		}
+	case reflect.Struct:
+		for _, f := range reflect.VisibleFields(v.Type()) {
+			if f.IsExported() && !f.Anonymous {
+				names = append(names, f.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
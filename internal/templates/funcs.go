@@ -0,0 +1,339 @@
+package templates
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// dateLayouts are the input formats accepted by the date function.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006",
+	"01/02/2006",
+}
+
+// currencySymbols maps ISO codes to the symbol placed before the amount.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"INR": "₹",
+	"UAH": "₴",
+}
+
+// Funcs returns the function library available to document templates.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		// Strings
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"title":    titleCase,
+		"trim":     strings.TrimSpace,
+		"replace":  func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains": func(substr, s string) bool { return strings.Contains(s, substr) },
+		"join":     join,
+		"split":    func(sep, s string) []string { return strings.Split(s, sep) },
+
+		// Defaults
+		"default":  defaultValue,
+		"empty":    isEmpty,
+		"coalesce": coalesce,
+
+		// Arithmetic
+		"add":   func(a, b any) (float64, error) { return arith(a, b, func(x, y float64) float64 { return x + y }) },
+		"sub":   func(a, b any) (float64, error) { return arith(a, b, func(x, y float64) float64 { return x - y }) },
+		"mul":   func(a, b any) (float64, error) { return arith(a, b, func(x, y float64) float64 { return x * y }) },
+		"div":   divide,
+		"mod":   func(a, b any) (float64, error) { return arith(a, b, math.Mod) },
+		"round": round,
+		"sum":   sum,
+
+		// Formatting
+		"number":   formatNumber,
+		"currency": formatCurrency,
+		"date":     formatDate,
+		"now":      time.Now,
+	}
+}
+
+// LoadFuncFile reads a YAML mapping of function names to template snippets and
+// returns them as template functions. Each snippet is executed with its single
+// argument as dot (or the argument list when called with several) and may use
+// the functions in base as well as the other snippets:
+//
+//	vat: '{{ mul . 0.2 | number 2 }}'
+//	greeting: 'Dear {{ default "customer" . }},'
+func LoadFuncFile(path string, base template.FuncMap) (template.FuncMap, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read function file: %w", err)
+	}
+
+	var snippets map[string]string
+	if err := yaml.Unmarshal(content, &snippets); err != nil {
+		return nil, fmt.Errorf("parse function file: %w", err)
+	}
+
+	var set *texttemplate.Template
+	funcs := make(template.FuncMap, len(snippets))
+
+	for name := range snippets {
+		funcs[name] = func(args ...any) (string, error) {
+			var dot any = args
+			if len(args) == 1 {
+				dot = args[0]
+			}
+
+			var buf strings.Builder
+			if err := set.ExecuteTemplate(&buf, name, dot); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		}
+	}
+
+	set = texttemplate.New("").Funcs(texttemplate.FuncMap(base)).Funcs(texttemplate.FuncMap(funcs))
+	for name, body := range snippets {
+		if _, err := set.New(name).Parse(body); err != nil {
+			return nil, fmt.Errorf("parse function %s: %w", name, err)
+		}
+	}
+
+	return funcs, nil
+}
+
+// titleCase upper-cases the first letter of each word.
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = strings.ToUpper(string(r[0])) + string(r[1:])
+	}
+	return strings.Join(words, " ")
+}
+
+// join concatenates the elements of any slice with sep.
+func join(sep string, items any) string {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Sprint(items)
+	}
+
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+// defaultValue returns value, or def when value is empty.
+func defaultValue(def, value any) any {
+	if isEmpty(value) {
+		return def
+	}
+	return value
+}
+
+// coalesce returns the first non-empty value.
+func coalesce(values ...any) any {
+	for _, v := range values {
+		if !isEmpty(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+// isEmpty reports whether a value is nil, zero, or an empty string or collection.
+func isEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+// toFloat converts numbers and numeric strings to float64.
+func toFloat(value any) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		s := strings.ReplaceAll(strings.TrimSpace(v), ",", "")
+		if s == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("not a number: %q", v)
+		}
+		return f, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.CanFloat():
+		return rv.Float(), nil
+	case rv.CanInt():
+		return float64(rv.Int()), nil
+	case rv.CanUint():
+		return float64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("not a number: %v", value)
+}
+
+// arith applies op to two numeric values.
+func arith(a, b any, op func(x, y float64) float64) (float64, error) {
+	x, err := toFloat(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toFloat(b)
+	if err != nil {
+		return 0, err
+	}
+	return op(x, y), nil
+}
+
+// divide divides a by b, failing on division by zero.
+func divide(a, b any) (float64, error) {
+	y, err := toFloat(b)
+	if err != nil {
+		return 0, err
+	}
+	if y == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	return arith(a, y, func(x, y float64) float64 { return x / y })
+}
+
+// round rounds value to the given number of decimal places.
+func round(places int, value any) (float64, error) {
+	f, err := toFloat(value)
+	if err != nil {
+		return 0, err
+	}
+	scale := math.Pow(10, float64(places))
+	return math.Round(f*scale) / scale, nil
+}
+
+// sum adds up a slice of numbers.
+func sum(items any) (float64, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return toFloat(items)
+	}
+
+	var total float64
+	for i := 0; i < v.Len(); i++ {
+		f, err := toFloat(v.Index(i).Interface())
+		if err != nil {
+			return 0, err
+		}
+		total += f
+	}
+	return total, nil
+}
+
+// formatNumber formats value with the given decimals and thousands separators.
+func formatNumber(decimals int, value any) (string, error) {
+	f, err := toFloat(value)
+	if err != nil {
+		return "", err
+	}
+
+	s := strconv.FormatFloat(math.Abs(f), 'f', decimals, 64)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if f < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if fracPart != "" {
+		b.WriteByte('.')
+		b.WriteString(fracPart)
+	}
+
+	return b.String(), nil
+}
+
+// formatCurrency formats value as an amount in the given ISO currency code.
+func formatCurrency(code string, value any) (string, error) {
+	amount, err := formatNumber(2, value)
+	if err != nil {
+		return "", err
+	}
+
+	code = strings.ToUpper(code)
+	symbol, ok := currencySymbols[code]
+	if !ok {
+		return code + " " + amount, nil
+	}
+	if neg, found := strings.CutPrefix(amount, "-"); found {
+		return "-" + symbol + neg, nil
+	}
+	return symbol + amount, nil
+}
+
+// formatDate formats a time, date string, or Unix timestamp with a Go layout.
+func formatDate(layout string, value any) (string, error) {
+	var t time.Time
+
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case string:
+		parsed, err := parseDate(v)
+		if err != nil {
+			return "", err
+		}
+		t = parsed
+	default:
+		secs, err := toFloat(v)
+		if err != nil {
+			return "", fmt.Errorf("not a date: %v", value)
+		}
+		t = time.Unix(int64(secs), 0).UTC()
+	}
+
+	return t.Format(layout), nil
+}
+
+// parseDate parses a date string in any of the supported layouts.
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("not a date: %q", s)
+}
@@ -0,0 +1,64 @@
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultTheme is the theme documents use unless another is selected.
+const DefaultTheme = "github"
+
+//go:embed themes/*.css
+var themeFS embed.FS
+
+// Theme is a built-in stylesheet layered over the document template.
+type Theme struct {
+	Name        string
+	Description string // from the comment opening the stylesheet
+	CSS         string
+}
+
+// Themes returns the built-in themes, sorted by name.
+func Themes() []Theme {
+	var themes []Theme
+	for _, name := range themeNames() {
+		if theme, err := LookupTheme(name); err == nil {
+			themes = append(themes, theme)
+		}
+	}
+	return themes
+}
+
+// LookupTheme returns the built-in theme with the given name; empty selects
+// DefaultTheme.
+func LookupTheme(name string) (Theme, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+
+	content, err := themeFS.ReadFile(path.Join("themes", name+".css"))
+	if err != nil || strings.ContainsAny(name, "/\\") {
+		return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(themeNames(), ", "))
+	}
+
+	css := string(content)
+	theme := Theme{Name: name, CSS: css}
+	if rest, ok := strings.CutPrefix(css, "/*"); ok {
+		if comment, _, ok := strings.Cut(rest, "*/"); ok {
+			theme.Description = strings.TrimSpace(comment)
+		}
+	}
+	return theme, nil
+}
+
+// themeNames lists the built-in themes in name order
+func themeNames() []string {
+	entries, _ := themeFS.ReadDir("themes")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".css"))
+	}
+	return names
+}
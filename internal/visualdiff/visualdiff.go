@@ -0,0 +1,124 @@
+// Package visualdiff compares rendered page images pixel by pixel.
+package visualdiff
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+// Tolerance is the largest per-channel difference (0-255) still treated as equal,
+// so antialiasing noise does not count as a change.
+const Tolerance = 16
+
+var highlight = color.RGBA{R: 255, A: 255}
+
+// Result describes the difference between two page images.
+type Result struct {
+	Changed int     // number of differing pixels
+	Total   int     // number of pixels compared (the larger of the two pages)
+	Percent float64 // Changed as a percentage of Total
+
+	// Diff shows the new page faded, with differing pixels in red
+	Diff *image.RGBA
+}
+
+// Compare compares two images. Pixels outside the smaller image count as changed.
+func Compare(oldImg, newImg image.Image) Result {
+	ob, nb := oldImg.Bounds(), newImg.Bounds()
+	w := max(ob.Dx(), nb.Dx())
+	h := max(ob.Dy(), nb.Dy())
+
+	diff := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(diff, diff.Bounds(), image.White, image.Point{}, draw.Src)
+
+	changed := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			op := image.Pt(ob.Min.X+x, ob.Min.Y+y)
+			np := image.Pt(nb.Min.X+x, nb.Min.Y+y)
+			inOld, inNew := op.In(ob), np.In(nb)
+
+			if inOld && inNew && samePixel(oldImg.At(op.X, op.Y), newImg.At(np.X, np.Y)) {
+				diff.Set(x, y, faded(newImg.At(np.X, np.Y)))
+				continue
+			}
+
+			changed++
+			diff.Set(x, y, highlight)
+		}
+	}
+
+	total := w * h
+	percent := 0.0
+	if total > 0 {
+		percent = float64(changed) * 100 / float64(total)
+	}
+
+	return Result{Changed: changed, Total: total, Percent: percent, Diff: diff}
+}
+
+// CompareFiles compares two PNG files.
+func CompareFiles(oldPath, newPath string) (Result, error) {
+	oldImg, err := Load(oldPath)
+	if err != nil {
+		return Result{}, err
+	}
+	newImg, err := Load(newPath)
+	if err != nil {
+		return Result{}, err
+	}
+	return Compare(oldImg, newImg), nil
+}
+
+// Load decodes a PNG file.
+func Load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open image: %w", err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// Save encodes an image as PNG.
+func Save(img image.Image, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create image: %w", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("encode %s: %w", path, err)
+	}
+	return nil
+}
+
+// samePixel reports whether two colors differ by at most Tolerance in every channel.
+func samePixel(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return near(ar, br) && near(ag, bg) && near(ab, bb) && near(aa, ba)
+}
+
+// near compares two 16-bit color channels against Tolerance.
+func near(a, b uint32) bool {
+	d := int(a>>8) - int(b>>8)
+	return d >= -Tolerance && d <= Tolerance
+}
+
+// faded lightens a color so highlighted changes stand out.
+func faded(c color.Color) color.Color {
+	r, g, b, _ := c.RGBA()
+	fade := func(v uint32) uint8 { return uint8(255 - (255-v>>8)/4) }
+	return color.RGBA{R: fade(r), G: fade(g), B: fade(b), A: 255}
+}
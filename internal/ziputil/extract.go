@@ -0,0 +1,164 @@
+package ziputil
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrUnsafePath is returned when an archive entry would be written outside the
+// destination directory (zip slip) or is a symbolic link.
+var ErrUnsafePath = errors.New("unsafe path in zip archive")
+
+// ErrTooLarge is returned when an archive expands beyond the allowed size.
+var ErrTooLarge = errors.New("zip archive too large")
+
+// Entry describes a file or directory stored in a zip archive.
+type Entry struct {
+	Name     string
+	Size     int64 // uncompressed size in bytes
+	Modified time.Time
+	Dir      bool
+}
+
+// List returns the entries of a zip archive in archive order.
+func List(zipPath string) ([]Entry, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("open zip: %w", err)
+	}
+	defer zr.Close()
+
+	entries := make([]Entry, 0, len(zr.File))
+	for _, f := range zr.File {
+		entries = append(entries, Entry{
+			Name:     f.Name,
+			Size:     int64(f.UncompressedSize64),
+			Modified: f.Modified,
+			Dir:      f.FileInfo().IsDir(),
+		})
+	}
+	return entries, nil
+}
+
+// Extract unpacks a zip archive into destDir, creating it if needed.
+// Entries that would escape destDir and symbolic links are rejected with ErrUnsafePath.
+func Extract(zipPath, destDir string) error {
+	return ExtractWithLimit(zipPath, destDir, 0)
+}
+
+// ExtractWithLimit is like Extract but fails with ErrTooLarge once more than
+// maxBytes have been written. A maxBytes of 0 means no limit.
+func ExtractWithLimit(zipPath, destDir string, maxBytes int64) error {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("open zip: %w", err)
+	}
+	defer zr.Close()
+
+	root, err := filepath.Abs(destDir)
+	if err != nil {
+		return fmt.Errorf("resolve destination: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return fmt.Errorf("create destination: %w", err)
+	}
+
+	// Validate every entry before writing anything
+	for _, f := range zr.File {
+		if _, err := entryPath(root, f); err != nil {
+			return err
+		}
+	}
+
+	var written int64
+	for _, f := range zr.File {
+		target, _ := entryPath(root, f)
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("create %s: %w", f.Name, err)
+			}
+			continue
+		}
+
+		remaining := int64(-1)
+		if maxBytes > 0 {
+			remaining = maxBytes - written
+		}
+
+		n, err := extractFile(f, target, remaining)
+		written += n
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// entryPath returns the absolute destination of an archive entry, rejecting
+// absolute paths, parent traversal, and symbolic links.
+func entryPath(root string, f *zip.File) (string, error) {
+	if f.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("%w: %s is a symbolic link", ErrUnsafePath, f.Name)
+	}
+
+	name := strings.ReplaceAll(f.Name, `\`, "/")
+	if name == "" || strings.HasPrefix(name, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, f.Name)
+	}
+
+	target := filepath.Join(root, filepath.FromSlash(name))
+	if target != root && !strings.HasPrefix(target, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, f.Name)
+	}
+
+	return target, nil
+}
+
+// extractFile writes one archive entry to target, copying at most remaining
+// bytes (unlimited if negative), and returns the number of bytes written.
+func extractFile(f *zip.File, target string, remaining int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return 0, fmt.Errorf("create directory for %s: %w", f.Name, err)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, fmt.Errorf("open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	// Executables, such as a browser shipped as a zip, stay executable
+	mode := os.FileMode(0o644)
+	if f.Mode()&0o111 != 0 {
+		mode = 0o755
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return 0, fmt.Errorf("create %s: %w", f.Name, err)
+	}
+	defer out.Close()
+
+	var src io.Reader = rc
+	if remaining >= 0 {
+		// Read one byte past the limit to detect archives that expand further
+		src = io.LimitReader(rc, remaining+1)
+	}
+
+	n, err := io.Copy(out, src)
+	if err != nil {
+		return n, fmt.Errorf("extract %s: %w", f.Name, err)
+	}
+	if remaining >= 0 && n > remaining {
+		return n, fmt.Errorf("%w: more than the allowed size", ErrTooLarge)
+	}
+
+	return n, nil
+}
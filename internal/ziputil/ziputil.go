@@ -1,26 +1,51 @@
-// Package ziputil provides utilities for creating zip archives.
+// Package ziputil provides utilities for creating, listing, and extracting zip archives.
 package ziputil
 
 import (
 	"archive/zip"
+	"compress/flate"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
 )
 
+// Options controls which files are archived and how.
+type Options struct {
+	// Globs (relative to the archive root) of files to include; empty includes everything.
+	Include []string
+
+	// Globs (relative to the archive root) of files to leave out.
+	Exclude []string
+
+	// Deflate compression level from 1 (fastest) to 9 (smallest), 0 to store
+	// files uncompressed, or -1 for the default level.
+	Level int
+
+	// Fixed modification time for every entry, for reproducible archives.
+	// The zero value keeps each file's own modification time.
+	Modified time.Time
+}
+
+// DefaultOptions returns options that archive every file with default compression.
+func DefaultOptions() Options {
+	return Options{Level: flate.DefaultCompression}
+}
+
 // CreateFromFolder creates a zip archive of a directory.
 func CreateFromFolder(srcDir, outZip string) error {
-	f, err := os.Create(outZip)
-	if err != nil {
-		return fmt.Errorf("create zip: %w", err)
-	}
-	defer f.Close()
+	return CreateFromFolderWithOptions(srcDir, outZip, DefaultOptions())
+}
 
-	zw := zip.NewWriter(f)
-	defer zw.Close()
+// CreateFromFolderWithOptions creates a zip archive of the files in a directory
+// that match the include and exclude globs.
+func CreateFromFolderWithOptions(srcDir, outZip string, opts Options) error {
+	var files []string
 
-	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -29,25 +54,32 @@ func CreateFromFolder(srcDir, outZip string) error {
 		}
 
 		rel, _ := filepath.Rel(srcDir, path)
-		w, err := zw.Create(rel)
-		if err != nil {
-			return err
+		if opts.Matches(rel) {
+			files = append(files, path)
 		}
-
-		rf, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer rf.Close()
-
-		_, err = io.Copy(w, rf)
-		return err
+		return nil
 	})
+	if err != nil {
+		return fmt.Errorf("walk %s: %w", srcDir, err)
+	}
+
+	return CreateFromFilesWithOptions(files, srcDir, outZip, opts)
 }
 
 // CreateFromFiles creates a zip archive from a list of files.
 // baseDir is used to compute relative paths within the archive.
 func CreateFromFiles(files []string, baseDir, outZip string) error {
+	return CreateFromFilesWithOptions(files, baseDir, outZip, DefaultOptions())
+}
+
+// CreateFromFilesWithOptions creates a zip archive from a list of files using the
+// given compression level and timestamps. Files are archived in the given order;
+// the include and exclude globs are not applied.
+func CreateFromFilesWithOptions(files []string, baseDir, outZip string, opts Options) error {
+	if opts.Level < flate.HuffmanOnly || opts.Level > flate.BestCompression {
+		return fmt.Errorf("invalid compression level %d", opts.Level)
+	}
+
 	f, err := os.Create(outZip)
 	if err != nil {
 		return fmt.Errorf("create zip: %w", err)
@@ -55,25 +87,75 @@ func CreateFromFiles(files []string, baseDir, outZip string) error {
 	defer f.Close()
 
 	zw := zip.NewWriter(f)
-	defer zw.Close()
+	zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, opts.Level)
+	})
 
 	for _, file := range files {
-		if err := addFileToZip(zw, file, baseDir); err != nil {
+		if err := addFileToZip(zw, file, baseDir, opts); err != nil {
+			zw.Close()
 			return err
 		}
 	}
 
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("finish zip: %w", err)
+	}
 	return nil
 }
 
+// Matches reports whether a path relative to the archive root passes the include and exclude globs.
+func (o Options) Matches(rel string) bool {
+	rel = filepath.ToSlash(rel)
+
+	if len(o.Include) > 0 {
+		included := false
+		for _, pattern := range o.Include {
+			if ok, _ := doublestar.Match(pattern, rel); ok {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, pattern := range o.Exclude {
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			return false
+		}
+	}
+
+	return true
+}
+
 // addFileToZip adds a single file to a zip writer.
-func addFileToZip(zw *zip.Writer, filePath, baseDir string) error {
+func addFileToZip(zw *zip.Writer, filePath, baseDir string, opts Options) error {
 	rel, err := filepath.Rel(baseDir, filePath)
 	if err != nil {
 		rel = filepath.Base(filePath)
 	}
 
-	w, err := zw.Create(rel)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(rel)
+	header.Method = zip.Deflate
+	if opts.Level == flate.NoCompression {
+		header.Method = zip.Store
+	}
+	if !opts.Modified.IsZero() {
+		header.Modified = opts.Modified
+	}
+
+	w, err := zw.CreateHeader(header)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,114 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// captionKind describes numbered captions of one element type. The regex
+// captures the element's opening tag, the markup up to the caption, and the
+// caption's opening tag, text, and closing tag.
+type captionKind struct {
+	label string // e.g. "Figure"
+	list  string // title of the caption list
+	regex *regexp.Regexp
+}
+
+var (
+	// Figures emitted by the "figures" markdown extension
+	figureCaptions = captionKind{
+		label: "Figure",
+		list:  "List of Figures",
+		regex: regexp.MustCompile(`(?s)(<figure class="figure">)(.*?)(<figcaption>)(.*?)(</figcaption>)`),
+	}
+
+	// Tables captioned by the "table_captions" markdown extension
+	tableCaptions = captionKind{
+		label: "Table",
+		list:  "List of Tables",
+		regex: regexp.MustCompile(`(?s)(<table>)(\s*)(<caption class="table-caption">)(.*?)(</caption>)`),
+	}
+)
+
+var tagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// captionEntry is one numbered figure or table in a caption list
+type captionEntry struct {
+	ID    string
+	Label string        // e.g. "Figure 3"
+	Text  template.HTML // caption without markup
+}
+
+var captionListTemplate = template.Must(template.New("captions").Parse(`<nav class="caption-list">
+<h1>{{.Title}}</h1>
+<ol>
+{{- range .Entries}}
+<li><a href="#{{.ID}}"><span class="caption-number">{{.Label}}:</span> {{.Text}}</a></li>
+{{- end}}
+</ol>
+</nav>
+`))
+
+// number prefixes each caption with "<Label> N:" and anchors its element,
+// numbering across the whole body so combined documents count on
+func (k captionKind) number(body string) (string, []captionEntry) {
+	var entries []captionEntry
+	prefix := strings.ToLower(k.label)
+
+	body = k.regex.ReplaceAllStringFunc(body, func(m string) string {
+		sub := k.regex.FindStringSubmatch(m)
+		n := len(entries) + 1
+		entry := captionEntry{
+			ID:    fmt.Sprintf("%s-%d", prefix, n),
+			Label: fmt.Sprintf("%s %d", k.label, n),
+			Text:  template.HTML(strings.TrimSpace(tagRegex.ReplaceAllString(sub[4], ""))),
+		}
+		entries = append(entries, entry)
+
+		open := strings.TrimSuffix(sub[1], ">") + fmt.Sprintf(` id="%s">`, entry.ID)
+		return fmt.Sprintf(`%s%s%s<span class="caption-number">%s:</span> %s%s`,
+			open, sub[2], sub[3], entry.Label, sub[4], sub[5])
+	})
+	return body, entries
+}
+
+// captionList renders a linked list of numbered captions, such as a List of Figures
+func (k captionKind) captionList(entries []captionEntry) (string, error) {
+	var b strings.Builder
+	err := captionListTemplate.Execute(&b, struct {
+		Title   string
+		Entries []captionEntry
+	}{k.list, entries})
+	if err != nil {
+		return "", fmt.Errorf("render %s: %w", strings.ToLower(k.list), err)
+	}
+	return b.String(), nil
+}
+
+// numberCaptions numbers figures and tables and inserts the requested lists
+// before the body
+func numberCaptions(body string, req RenderRequest) (string, error) {
+	var lists string
+	for _, c := range []struct {
+		kind captionKind
+		list bool
+	}{
+		{figureCaptions, req.ListOfFigures},
+		{tableCaptions, req.ListOfTables},
+	} {
+		var entries []captionEntry
+		body, entries = c.kind.number(body)
+		if !c.list || len(entries) == 0 {
+			continue
+		}
+
+		list, err := c.kind.captionList(entries)
+		if err != nil {
+			return "", err
+		}
+		lists += list
+	}
+	return lists + body, nil
+}
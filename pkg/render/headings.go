@@ -0,0 +1,56 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+)
+
+// Heading is a heading of a rendered document and the page it printed on.
+type Heading struct {
+	Level int
+	ID    string
+	Title string
+	Page  int // 0 when the heading wasn't found in the PDF's outline
+}
+
+var (
+	headingRegex   = regexp.MustCompile(`(?is)<h([1-6])(\s[^>]*)?>(.*?)</h[1-6]>`)
+	headingIDRegex = regexp.MustCompile(`(?i)\bid\s*=\s*["']([^"']*)["']`)
+)
+
+// locateHeadings finds the page of each heading of a document in the outline
+// of its PDF, matching them by title in document order
+func locateHeadings(document string, pdfBuf []byte) ([]Heading, error) {
+	outline, err := pdf.Outline(pdfBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	var headings []Heading
+	next := 0
+	for _, m := range headingRegex.FindAllStringSubmatch(document, -1) {
+		level, _ := strconv.Atoi(m[1])
+		h := Heading{Level: level, Title: headingTitle(m[3])}
+		if id := headingIDRegex.FindStringSubmatch(m[2]); id != nil {
+			h.ID = html.UnescapeString(id[1])
+		}
+		for i := next; i < len(outline); i++ {
+			if strings.EqualFold(headingTitle(outline[i].Title), h.Title) {
+				h.Page = outline[i].Page
+				next = i + 1
+				break
+			}
+		}
+		headings = append(headings, h)
+	}
+	return headings, nil
+}
+
+// headingTitle returns the text of heading markup with its whitespace collapsed
+func headingTitle(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tagRegex.ReplaceAllString(s, ""))), " ")
+}
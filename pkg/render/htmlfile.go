@@ -0,0 +1,46 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/images"
+	"github.com/kuzik/markdown-pdf-action/internal/sanitize"
+	"github.com/kuzik/markdown-pdf-action/internal/templates"
+)
+
+var (
+	documentRegex  = regexp.MustCompile(`(?is)^\s*(?:<!--.*?-->\s*)*<(?:!doctype|html)[\s>]`)
+	headRegex      = regexp.MustCompile(`(?is)<head[\s>].*?</head>`)
+	bodyRegex      = regexp.MustCompile(`(?is)<body[^>]*>(.*)</body>`)
+	headStyleRegex = regexp.MustCompile(`(?is)<style[^>]*>.*?</style>|<link\s+[^>]*rel=["']?stylesheet["']?[^>]*>`)
+)
+
+// IsHTMLDocument reports whether src is a complete HTML document rather than a
+// fragment, judging by a leading doctype or <html> tag.
+func IsHTMLDocument(src []byte) bool {
+	return documentRegex.Match(src)
+}
+
+// PrepareHTML readies a pre-generated HTML file for printing by inlining the
+// local stylesheets, scripts, and images it references relative to baseDir.
+// When it is going to be wrapped in the template, a complete document is
+// reduced to the stylesheets of its head and the content of its body. Safe
+// sanitizes the HTML first, which also drops its stylesheets and scripts.
+func PrepareHTML(src []byte, baseDir string, wrap, safe bool) string {
+	content := string(src)
+	if wrap && IsHTMLDocument(src) {
+		if m := bodyRegex.FindStringSubmatch(content); m != nil {
+			styles := headStyleRegex.FindAllString(headRegex.FindString(content), -1)
+			content = strings.Join(append(styles, m[1]), "\n")
+		}
+	}
+
+	if safe {
+		// Sanitizing dropped the stylesheets and scripts, and only images
+		// inside baseDir are embedded
+		content, _ = images.EmbedImagesConfined(sanitize.HTML(content), baseDir)
+		return content
+	}
+	return templates.InlineAssets(content, baseDir)
+}
@@ -0,0 +1,238 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Ways of handling tables wider than the page.
+const (
+	WideTablesShrink    = "shrink"    // scale the table down to the page width
+	WideTablesLandscape = "landscape" // move it, and wide code blocks, to a landscape page
+	WideTablesSlice     = "slice"     // split its columns into tables repeating the first column
+)
+
+// Ways of fitting code lines wider than the page.
+const (
+	CodeWrapWrap   = "wrap"   // soft-wrap long lines, marking them with ↵
+	CodeWrapShrink = "shrink" // reduce the font size, down to half, then wrap
+)
+
+// Where footnotes are printed.
+const (
+	FootnotesEnd  = "end"  // collected at the end of the document
+	FootnotesPage = "page" // at the bottom of the page referencing them
+)
+
+// defaultKeepLines is the default minimum number of paragraph lines left at the
+// bottom (orphans) or carried to the top (widows) of a page.
+const defaultKeepLines = 3
+
+// cssPixelsPerInch converts paper dimensions to CSS pixels.
+const cssPixelsPerInch = 96
+
+// bodyPadding is the horizontal padding of the template body, in CSS pixels.
+const bodyPadding = 2 * 45
+
+// Layout selects print layout behavior of the document template.
+type Layout struct {
+	// Number of text columns, e.g. 2 for newsletter-style documents. Zero or
+	// one is a single column. Sections can override it with the one-column,
+	// two-column, and three-column classes.
+	Columns int
+
+	// How tables wider than the page are handled: shrink, landscape, or slice.
+	// Empty leaves them overflowing the page edge.
+	WideTables string
+
+	// How code lines wider than the page are fit: wrap or shrink.
+	// Empty cuts them off at the block edge.
+	CodeWrap string
+
+	// Page break rules.
+	Pagination Pagination
+
+	// Where footnotes are printed: end (the default) or page. Page footnotes
+	// fall back to the end in multi-column layouts.
+	Footnotes string
+
+	// Mirrored margins for duplex printing, as lengths such as "25mm" or
+	// "1in" (bare numbers are inches). The inner margin is at the binding
+	// edge: on the left of right-hand pages, the first page being one, and on
+	// the right of left-hand pages. An empty side keeps its PDF margin.
+	MarginInner string
+	MarginOuter string
+
+	// Parsed margins in inches
+	inner, outer float64
+}
+
+// Pagination controls where pages may break. The zero value keeps headings
+// with the content that follows, keeps list items and table rows on one page,
+// and leaves at least three lines of a paragraph on either side of a break.
+type Pagination struct {
+	// Allow a page break right after a heading.
+	SplitHeadings bool
+
+	// Allow list items, table rows, and definition terms to break across pages.
+	SplitItems bool
+
+	// Minimum lines of a paragraph left at the bottom of a page (orphans) and
+	// carried to the top of the next (widows). Zero uses the default of 3.
+	Orphans int
+	Widows  int
+}
+
+// normalize validates the layout.
+func (l Layout) normalize() (Layout, error) {
+	l.WideTables = strings.ToLower(strings.TrimSpace(l.WideTables))
+	l.CodeWrap = strings.ToLower(strings.TrimSpace(l.CodeWrap))
+	l.Footnotes = strings.ToLower(strings.TrimSpace(l.Footnotes))
+
+	switch l.WideTables {
+	case "", WideTablesShrink, WideTablesLandscape, WideTablesSlice:
+	default:
+		return l, fmt.Errorf("invalid wide table handling %q (use shrink, landscape, or slice)", l.WideTables)
+	}
+
+	if l.Columns < 0 {
+		return l, fmt.Errorf("columns must not be negative")
+	}
+
+	switch l.CodeWrap {
+	case "", CodeWrapWrap, CodeWrapShrink:
+	default:
+		return l, fmt.Errorf("invalid code wrapping %q (use wrap or shrink)", l.CodeWrap)
+	}
+
+	switch l.Footnotes {
+	case "", FootnotesEnd, FootnotesPage:
+	default:
+		return l, fmt.Errorf("invalid footnote placement %q (use end or page)", l.Footnotes)
+	}
+
+	var err error
+	if l.inner, err = parseLength(l.MarginInner); err != nil {
+		return l, fmt.Errorf("invalid inner margin: %w", err)
+	}
+	if l.outer, err = parseLength(l.MarginOuter); err != nil {
+		return l, fmt.Errorf("invalid outer margin: %w", err)
+	}
+
+	if l.Pagination.Orphans < 0 || l.Pagination.Widows < 0 {
+		return l, fmt.Errorf("orphans and widows must not be negative")
+	}
+	if l.Pagination.Orphans == 0 {
+		l.Pagination.Orphans = defaultKeepLines
+	}
+	if l.Pagination.Widows == 0 {
+		l.Pagination.Widows = defaultKeepLines
+	}
+	return l, nil
+}
+
+// pageBox is the printable area of a regular page, in CSS pixels
+type pageBox struct {
+	Width  float64
+	Height float64
+}
+
+// printableBox returns the area inside the page margins
+func printableBox(opts PDFOptions) pageBox {
+	return pageBox{
+		Width:  math.Floor((opts.PaperWidth - opts.MarginLeft - opts.MarginRight) * cssPixelsPerInch),
+		Height: math.Floor((opts.PaperHeight - opts.MarginTop - opts.MarginBottom) * cssPixelsPerInch),
+	}
+}
+
+// lengthUnits converts length units to inches
+var lengthUnits = map[string]float64{
+	"in": 1,
+	"cm": 1 / 2.54,
+	"mm": 1 / 25.4,
+	"pt": 1.0 / 72,
+	"pc": 1.0 / 6,
+	"px": 1.0 / cssPixelsPerInch,
+}
+
+// parseLength parses a length such as "25mm" into inches. Empty is zero.
+func parseLength(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+
+	value, factor := s, 1.0
+	for unit, f := range lengthUnits {
+		if strings.HasSuffix(s, unit) {
+			value, factor = strings.TrimSpace(strings.TrimSuffix(s, unit)), f
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a length such as 25mm or 1in", s)
+	}
+	return n * factor, nil
+}
+
+// pageMargins are the left and right margins of mirrored pages, in inches.
+type pageMargins struct {
+	Inner float64
+	Outer float64
+}
+
+// mirroredMargins returns the margins for the template when inner and outer
+// margins are set. The PDF margins are set to those of right-hand pages,
+// which backends that ignore left and right page rules use on every page.
+func (l Layout) mirroredMargins(opts *PDFOptions) *pageMargins {
+	if l.MarginInner == "" && l.MarginOuter == "" {
+		return nil
+	}
+
+	m := &pageMargins{Inner: opts.MarginLeft, Outer: opts.MarginRight}
+	if l.MarginInner != "" {
+		m.Inner = l.inner
+	}
+	if l.MarginOuter != "" {
+		m.Outer = l.outer
+	}
+	opts.MarginLeft, opts.MarginRight = m.Inner, m.Outer
+	return m
+}
+
+// pageSizes are the paper sizes passed to the template for landscape pages.
+type pageSizes struct {
+	// Regular pages, in inches
+	Width  float64
+	Height float64
+
+	// Landscape pages, in inches
+	WideWidth  float64
+	WideHeight float64
+
+	// Width available to content on a landscape page, in CSS pixels
+	WideContent float64
+}
+
+// landscapePages returns the page size for the template when wide content
+// moves to landscape pages; they need the CSS page size to be honored.
+func (l Layout) landscapePages(opts *PDFOptions) *pageSizes {
+	if l.WideTables != WideTablesLandscape {
+		return nil
+	}
+
+	opts.PreferCSSPageSize = true
+	long := max(opts.PaperWidth, opts.PaperHeight)
+	short := min(opts.PaperWidth, opts.PaperHeight)
+	return &pageSizes{
+		Width:       opts.PaperWidth,
+		Height:      opts.PaperHeight,
+		WideWidth:   long,
+		WideHeight:  short,
+		WideContent: math.Floor((long-opts.MarginLeft-opts.MarginRight)*cssPixelsPerInch - bodyPadding),
+	}
+}
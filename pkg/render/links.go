@@ -0,0 +1,85 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Ways of printing the targets of external links.
+const (
+	LinkURLsInline   = "inline"   // "(https://...)" after the link text
+	LinkURLsEndnotes = "endnotes" // a numbered reference to a list of links at the end
+)
+
+// externalLinkRegex matches links to http(s) URLs, capturing the URL and the link text
+var externalLinkRegex = regexp.MustCompile(`(?s)<a\s[^>]*?href="(https?://[^"]+)"[^>]*>(.*?)</a>`)
+
+var linkNotesTemplate = template.Must(template.New("links").Parse(`<section class="link-notes">
+<h1>Links</h1>
+<ol>
+{{- range .}}
+<li id="{{.ID}}">{{.URL}}</li>
+{{- end}}
+</ol>
+</section>
+`))
+
+// annotateLinks prints the URL of each external link, either after the link or
+// as a numbered endnote. Links whose text already is the URL are left alone.
+func annotateLinks(body, mode string) (string, error) {
+	switch mode {
+	case "":
+		return body, nil
+	case LinkURLsInline, LinkURLsEndnotes:
+	default:
+		return "", fmt.Errorf("invalid link URL annotation %q (use inline or endnotes)", mode)
+	}
+
+	var urls []string
+	body = externalLinkRegex.ReplaceAllStringFunc(body, func(m string) string {
+		sub := externalLinkRegex.FindStringSubmatch(m)
+		url := html.UnescapeString(sub[1])
+		if showsURL(sub[2], url) {
+			return m
+		}
+
+		if mode == LinkURLsInline {
+			return m + fmt.Sprintf(`<span class="link-url"> (%s)</span>`, sub[1])
+		}
+		n := slices.Index(urls, url) + 1
+		if n == 0 {
+			urls = append(urls, url)
+			n = len(urls)
+		}
+		return m + fmt.Sprintf(`<sup class="link-note"><a href="#link-%d">[%d]</a></sup>`, n, n)
+	})
+	if len(urls) == 0 {
+		return body, nil
+	}
+
+	type linkNote struct{ ID, URL string }
+	notes := make([]linkNote, len(urls))
+	for i, url := range urls {
+		notes[i] = linkNote{fmt.Sprintf("link-%d", i+1), url}
+	}
+
+	var b strings.Builder
+	if err := linkNotesTemplate.Execute(&b, notes); err != nil {
+		return "", fmt.Errorf("render link list: %w", err)
+	}
+	return body + b.String(), nil
+}
+
+// showsURL reports whether link text spells out the URL, as autolinks do
+func showsURL(text, url string) bool {
+	text = html.UnescapeString(strings.TrimSpace(tagRegex.ReplaceAllString(text, "")))
+	for _, prefix := range []string{"https://", "http://", "www."} {
+		text = strings.TrimPrefix(text, prefix)
+		url = strings.TrimPrefix(url, prefix)
+	}
+	return strings.TrimSuffix(text, "/") == strings.TrimSuffix(url, "/")
+}
@@ -0,0 +1,81 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale selects the document language and text direction.
+type Locale struct {
+	// BCP 47 language tag, e.g. "de", "ar", or "zh-Hant". Sets the html lang
+	// attribute, which also enables CSS hyphenation, and picks a font stack
+	// that covers the script.
+	Lang string
+
+	// Text direction: "ltr", "rtl", or "auto". Defaults to "rtl" for
+	// right-to-left languages and is omitted otherwise.
+	Dir string
+}
+
+// rtlLanguages are written right to left.
+var rtlLanguages = map[string]bool{
+	"ar": true, "dv": true, "fa": true, "he": true, "ks": true,
+	"ku": true, "ps": true, "sd": true, "ug": true, "ur": true, "yi": true,
+}
+
+// fontStacks lists fonts covering each language's script, ahead of the default
+// stack. Keys are primary language subtags, or language-script pairs for Chinese.
+var fontStacks = map[string]string{
+	"zh":      `"Noto Sans CJK SC", "Source Han Sans SC", "PingFang SC", "Microsoft YaHei"`,
+	"zh-hant": `"Noto Sans CJK TC", "Source Han Sans TC", "PingFang TC", "Microsoft JhengHei"`,
+	"zh-tw":   `"Noto Sans CJK TC", "Source Han Sans TC", "PingFang TC", "Microsoft JhengHei"`,
+	"zh-hk":   `"Noto Sans CJK HK", "Noto Sans CJK TC", "PingFang HK", "Microsoft JhengHei"`,
+	"ja":      `"Noto Sans CJK JP", "Source Han Sans JP", "Hiragino Sans", "Yu Gothic", "Meiryo"`,
+	"ko":      `"Noto Sans CJK KR", "Source Han Sans KR", "Apple SD Gothic Neo", "Malgun Gothic"`,
+	"ar":      `"Noto Naskh Arabic", "Noto Sans Arabic", "Geeza Pro", "Segoe UI"`,
+	"fa":      `"Noto Naskh Arabic", "Noto Sans Arabic", "Geeza Pro", "Segoe UI"`,
+	"ur":      `"Noto Nastaliq Urdu", "Noto Naskh Arabic", "Noto Sans Arabic"`,
+	"he":      `"Noto Sans Hebrew", "Arial Hebrew", "Segoe UI"`,
+	"yi":      `"Noto Sans Hebrew", "Arial Hebrew", "Segoe UI"`,
+	"th":      `"Noto Sans Thai", "Thonburi", "Leelawadee UI"`,
+	"hi":      `"Noto Sans Devanagari", "Kohinoor Devanagari", "Nirmala UI"`,
+}
+
+// normalize validates the locale and fills in the direction implied by the language.
+func (l Locale) normalize() (Locale, error) {
+	l.Lang = strings.TrimSpace(l.Lang)
+	l.Dir = strings.ToLower(strings.TrimSpace(l.Dir))
+
+	switch l.Dir {
+	case "", "ltr", "rtl", "auto":
+	default:
+		return l, fmt.Errorf("invalid text direction %q (use ltr, rtl, or auto)", l.Dir)
+	}
+
+	if l.Dir == "" && rtlLanguages[l.primary()] {
+		l.Dir = "rtl"
+	}
+	return l, nil
+}
+
+// primary returns the lowercased primary language subtag.
+func (l Locale) primary() string {
+	primary, _, _ := strings.Cut(strings.ToLower(l.Lang), "-")
+	return primary
+}
+
+// fonts returns the script-specific font stack for the language, or "".
+func (l Locale) fonts() string {
+	tag := strings.ToLower(strings.ReplaceAll(l.Lang, "_", "-"))
+	for tag != "" {
+		if stack, ok := fontStacks[tag]; ok {
+			return stack
+		}
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// durationBuckets are the upper bounds of the render duration histogram, in seconds
+var durationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// Render outcomes counted in the metrics.
+const (
+	resultSuccess  = "success"
+	resultFailure  = "failure"
+	resultCanceled = "canceled"
+)
+
+// renderStats counts the documents rendered by this process
+type renderStats struct {
+	mu         sync.Mutex
+	results    map[string]uint64
+	inProgress int
+	queued     int
+	pages      uint64
+	buckets    []uint64 // per duration bucket, not cumulative
+	count      uint64
+	sum        float64
+}
+
+var stats = &renderStats{
+	results: make(map[string]uint64),
+	buckets: make([]uint64, len(durationBuckets)),
+}
+
+// begin records a render starting and returns the function recording its end.
+// Renders failing after ctx ended count as canceled.
+func (s *renderStats) begin(ctx context.Context) func(Result, error) {
+	start := time.Now()
+
+	s.mu.Lock()
+	s.inProgress++
+	s.mu.Unlock()
+
+	return func(res Result, err error) {
+		seconds := time.Since(start).Seconds()
+
+		result := resultSuccess
+		switch {
+		case err != nil && ctx.Err() != nil:
+			result = resultCanceled
+		case err != nil:
+			result = resultFailure
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.inProgress--
+		s.results[result]++
+		s.pages += uint64(res.Pages)
+		s.count++
+		s.sum += seconds
+		for i, le := range durationBuckets {
+			if seconds <= le {
+				s.buckets[i]++
+				break
+			}
+		}
+	}
+}
+
+// queue records jobs entering (1) or leaving (-1) a Queue
+func (s *renderStats) queue(delta int) {
+	s.mu.Lock()
+	s.queued += delta
+	s.mu.Unlock()
+}
+
+// MetricsHandler serves the render metrics of this process in the Prometheus
+// text format, for a rendering service to expose at /metrics. The pools add
+// their Chrome restarts, queued renders, and open tabs.
+//
+//	http.Handle("/metrics", render.MetricsHandler(pool))
+func MetricsHandler(pools ...*PDFPool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		stats.write(w, pools)
+	})
+}
+
+// write prints the metrics in the Prometheus text exposition format
+func (s *renderStats) write(w io.Writer, pools []*PDFPool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("markdown_pdf_renders_total", "counter", "Documents rendered, by result.")
+	for _, result := range []string{resultSuccess, resultFailure, resultCanceled} {
+		fmt.Fprintf(w, "markdown_pdf_renders_total{result=%q} %d\n", result, s.results[result])
+	}
+
+	metric("markdown_pdf_renders_in_progress", "gauge", "Documents being rendered.")
+	fmt.Fprintf(w, "markdown_pdf_renders_in_progress %d\n", s.inProgress)
+
+	metric("markdown_pdf_jobs_queued", "gauge", "Jobs waiting in render queues.")
+	fmt.Fprintf(w, "markdown_pdf_jobs_queued %d\n", s.queued)
+
+	metric("markdown_pdf_render_duration_seconds", "histogram", "Time to render a document, from markdown to written PDF.")
+	var cumulative uint64
+	for i, le := range durationBuckets {
+		cumulative += s.buckets[i]
+		fmt.Fprintf(w, "markdown_pdf_render_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "markdown_pdf_render_duration_seconds_bucket{le=\"+Inf\"} %d\n", s.count)
+	fmt.Fprintf(w, "markdown_pdf_render_duration_seconds_sum %g\n", s.sum)
+	fmt.Fprintf(w, "markdown_pdf_render_duration_seconds_count %d\n", s.count)
+
+	metric("markdown_pdf_pages_total", "counter", "Pages of the rendered documents.")
+	fmt.Fprintf(w, "markdown_pdf_pages_total %d\n", s.pages)
+
+	if len(pools) == 0 {
+		return
+	}
+
+	var restarts, queued, active int
+	for _, p := range pools {
+		restarts += p.Restarts()
+		queued += p.Queued()
+		active += p.Active()
+	}
+
+	metric("markdown_pdf_chrome_restarts_total", "counter", "Chrome restarts after failed health checks.")
+	fmt.Fprintf(w, "markdown_pdf_chrome_restarts_total %d\n", restarts)
+
+	metric("markdown_pdf_queue_depth", "gauge", "Renders waiting for a free Chrome tab.")
+	fmt.Fprintf(w, "markdown_pdf_queue_depth %d\n", queued)
+
+	metric("markdown_pdf_chrome_tabs_active", "gauge", "Chrome tabs rendering a document.")
+	fmt.Fprintf(w, "markdown_pdf_chrome_tabs_active %d\n", active)
+}
@@ -0,0 +1,17 @@
+package render
+
+import (
+	"fmt"
+	"html"
+
+	"github.com/kuzik/markdown-pdf-action/internal/qrcode"
+)
+
+// coverQRCode renders a QR code of link for the top of the first page
+func coverQRCode(link string) (string, error) {
+	code, err := qrcode.Encode(link)
+	if err != nil {
+		return "", fmt.Errorf("generate QR code: %w", err)
+	}
+	return fmt.Sprintf(`<div class="cover-qr"><img src="%s" alt="%s"></div>`+"\n", code.DataURL(), html.EscapeString(link)), nil
+}
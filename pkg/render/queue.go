@@ -0,0 +1,405 @@
+package render
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kuzik/markdown-pdf-action/internal/webhook"
+)
+
+// ErrQueueFull is returned by Queue.Submit when the queue holds its capacity.
+var ErrQueueFull = errors.New("render queue full")
+
+// ErrQueueClosed is returned by Queue.Submit after Close.
+var ErrQueueClosed = errors.New("render queue closed")
+
+// Job states reported by Queue.Status.
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// QueueOptions configure a Queue.
+type QueueOptions struct {
+	// Maximum number of jobs waiting to start; Submit fails with ErrQueueFull
+	// beyond it. Defaults to 100.
+	Capacity int
+
+	// Number of documents rendered at once. Defaults to 1. With a PDFPool
+	// backend, match it to the pool size.
+	Concurrency int
+
+	// Maximum number of renders started per second. Zero is unlimited.
+	Rate float64
+
+	// How long finished jobs, and their PDFs, remain available to Status.
+	// Defaults to 10 minutes.
+	Retention time.Duration
+
+	// Maximum number of finished jobs, and total bytes of their PDFs, kept
+	// for Status; the oldest are forgotten first, before their retention
+	// ends. Default to 1000 jobs and 256 MiB.
+	MaxFinished      int
+	MaxFinishedBytes int64
+
+	// PDF settings of the documents submitted over HTTP, e.g. a PDFPool
+	// backend (uses DefaultPDFOptions if nil).
+	PDF *PDFOptions
+
+	// URL receiving a POST of each finished job's JobStatus as JSON, so
+	// downstream systems can react without polling. Failed deliveries are
+	// retried a few times, then dropped.
+	Webhook string
+
+	// Base URL the Handler is served at, e.g. "https://docs.example.com/api".
+	// When set, webhook payloads include pdf_url linking to the finished PDF.
+	PublicURL string
+}
+
+// JobStatus is the state of a job submitted to a Queue.
+type JobStatus struct {
+	ID       string    `json:"id"`
+	State    string    `json:"state"`
+	Error    string    `json:"error,omitempty"`
+	Pages    int       `json:"pages,omitempty"`
+	Created  time.Time `json:"created"`
+	Started  time.Time `json:"started,omitzero"`
+	Finished time.Time `json:"finished,omitzero"`
+
+	// PDF bytes of a finished job
+	PDF []byte `json:"-"`
+}
+
+// jobNotification is the webhook payload of a finished job
+type jobNotification struct {
+	JobStatus
+	PDFURL string `json:"pdf_url,omitempty"`
+}
+
+// queueJob is a submitted request and its status
+type queueJob struct {
+	req    RenderRequest
+	status JobStatus
+}
+
+// Queue renders submitted documents in the background with bounded memory:
+// at most Capacity jobs wait, Concurrency render at once, starts are limited
+// to Rate per second, so bursts can't open unbounded Chrome tabs, and at most
+// MaxFinished results of MaxFinishedBytes are kept. Clients poll Status for
+// the result.
+type Queue struct {
+	opts    QueueOptions
+	pending chan *queueJob
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	mu        sync.Mutex
+	jobs      map[string]*queueJob
+	finished  []*queueJob // in the order they finished
+	bytes     int64       // of the PDFs of finished jobs
+	closed    bool
+	nextStart time.Time
+}
+
+// NewQueue starts the workers of a render queue.
+func NewQueue(opts QueueOptions) *Queue {
+	if opts.Capacity < 1 {
+		opts.Capacity = 100
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = 10 * time.Minute
+	}
+	if opts.MaxFinished < 1 {
+		opts.MaxFinished = 1000
+	}
+	if opts.MaxFinishedBytes <= 0 {
+		opts.MaxFinishedBytes = 256 << 20
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		opts:    opts,
+		pending: make(chan *queueJob, opts.Capacity),
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(map[string]*queueJob),
+	}
+
+	for range opts.Concurrency {
+		q.workers.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Submit queues a render and returns the job ID to poll Status with.
+func (q *Queue) Submit(req RenderRequest) (string, error) {
+	id, err := newJobID()
+	if err != nil {
+		return "", err
+	}
+	job := &queueJob{req: req, status: JobStatus{ID: id, State: JobQueued, Created: time.Now()}}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return "", ErrQueueClosed
+	}
+	q.prune()
+
+	select {
+	case q.pending <- job:
+	default:
+		return "", ErrQueueFull
+	}
+	q.jobs[id] = job
+	stats.queue(1)
+	return id, nil
+}
+
+// Status returns the state of a job; ok is false for unknown or expired jobs.
+func (q *Queue) Status(id string) (status JobStatus, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.prune()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return JobStatus{}, false
+	}
+	return job.status, true
+}
+
+// Len returns the number of jobs waiting to start.
+func (q *Queue) Len() int {
+	return len(q.pending)
+}
+
+// Close stops accepting jobs, cancels running renders, and waits for the
+// workers to exit. Jobs still waiting are marked failed.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.pending)
+	q.mu.Unlock()
+
+	q.cancel()
+	q.workers.Wait()
+}
+
+// work renders queued jobs until the queue is closed
+func (q *Queue) work() {
+	defer q.workers.Done()
+
+	for job := range q.pending {
+		stats.queue(-1)
+		if err := q.wait(); err != nil {
+			q.finish(job, Result{}, err)
+			q.notify(job)
+			continue
+		}
+
+		q.mu.Lock()
+		job.status.State = JobRunning
+		job.status.Started = time.Now()
+		q.mu.Unlock()
+
+		res, err := Render(q.ctx, job.req)
+		q.finish(job, res, err)
+		q.notify(job)
+	}
+}
+
+// notify POSTs the status of a finished job to the webhook in the background
+func (q *Queue) notify(job *queueJob) {
+	if q.opts.Webhook == "" {
+		return
+	}
+
+	q.mu.Lock()
+	payload := jobNotification{JobStatus: job.status}
+	q.mu.Unlock()
+	if payload.State == JobDone && q.opts.PublicURL != "" {
+		payload.PDFURL = strings.TrimSuffix(q.opts.PublicURL, "/") + "/render/" + payload.ID + "/pdf"
+	}
+
+	q.workers.Add(1)
+	go func() {
+		defer q.workers.Done()
+		if err := webhook.Post(context.Background(), q.opts.Webhook, payload); err != nil {
+			log.Printf("Warning: webhook for render job %s failed: %v", payload.ID, err)
+		}
+	}()
+}
+
+// wait blocks until the rate limit allows the next render to start
+func (q *Queue) wait() error {
+	if q.opts.Rate <= 0 {
+		return q.ctx.Err()
+	}
+
+	q.mu.Lock()
+	now := time.Now()
+	start := q.nextStart
+	if start.Before(now) {
+		start = now
+	}
+	q.nextStart = start.Add(time.Duration(float64(time.Second) / q.opts.Rate))
+	q.mu.Unlock()
+
+	select {
+	case <-time.After(start.Sub(now)):
+		return nil
+	case <-q.ctx.Done():
+		return q.ctx.Err()
+	}
+}
+
+// finish records the outcome of a job, keeping only the PDF of its result
+func (q *Queue) finish(job *queueJob, res Result, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job.req = RenderRequest{}
+	job.status.Finished = time.Now()
+	if err != nil {
+		job.status.State = JobFailed
+		job.status.Error = err.Error()
+	} else {
+		job.status.State = JobDone
+		job.status.Pages = res.Pages
+		job.status.PDF = res.PDF
+	}
+	q.finished = append(q.finished, job)
+	q.bytes += int64(len(job.status.PDF))
+	q.prune()
+}
+
+// prune forgets jobs finished longer than the retention ago, and the oldest
+// finished jobs beyond MaxFinished and MaxFinishedBytes, always keeping the
+// latest; q.mu must be held
+func (q *Queue) prune() {
+	cutoff := time.Now().Add(-q.opts.Retention)
+	for len(q.finished) > 0 {
+		oldest := q.finished[0]
+		over := len(q.finished) > q.opts.MaxFinished || q.bytes > q.opts.MaxFinishedBytes
+		if !oldest.status.Finished.Before(cutoff) && (!over || len(q.finished) == 1) {
+			break
+		}
+		delete(q.jobs, oldest.status.ID)
+		q.bytes -= int64(len(oldest.status.PDF))
+		q.finished[0] = nil
+		q.finished = q.finished[1:]
+	}
+}
+
+// newJobID returns a random job ID
+func newJobID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// renderSubmission is the JSON body of a render submitted over HTTP
+type renderSubmission struct {
+	Markdown string `json:"markdown"`
+	HTML     string `json:"html"`
+	Title    string `json:"title"`
+}
+
+// Handler serves the queue over HTTP for asynchronous rendering. Submitted
+// content is treated as untrusted (see RenderRequest.Safe).
+//
+//	POST /render          {"markdown": "...", "title": "..."} → 202 {"id": "...", ...}
+//	GET  /render/{id}     job status
+//	GET  /render/{id}/pdf PDF of a finished job
+//
+// A full queue answers 503 with a Retry-After header.
+func (q *Queue) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /render", func(w http.ResponseWriter, r *http.Request) {
+		var sub renderSubmission
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 32<<20)).Decode(&sub); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if sub.Markdown == "" && sub.HTML == "" {
+			http.Error(w, "invalid request: markdown or html is required", http.StatusBadRequest)
+			return
+		}
+
+		id, err := q.Submit(RenderRequest{
+			Markdown: []byte(sub.Markdown),
+			HTML:     sub.HTML,
+			Title:    sub.Title,
+			PDF:      q.opts.PDF,
+			Safe:     true,
+		})
+		switch {
+		case errors.Is(err, ErrQueueFull), errors.Is(err, ErrQueueClosed):
+			w.Header().Set("Retry-After", "10")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		status, _ := q.Status(id)
+		w.Header().Set("Location", "/render/"+id)
+		writeJSON(w, http.StatusAccepted, status)
+	})
+
+	mux.HandleFunc("GET /render/{id}", func(w http.ResponseWriter, r *http.Request) {
+		status, ok := q.Status(r.PathValue("id"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	})
+
+	mux.HandleFunc("GET /render/{id}/pdf", func(w http.ResponseWriter, r *http.Request) {
+		status, ok := q.Status(r.PathValue("id"))
+		switch {
+		case !ok:
+			http.NotFound(w, r)
+		case status.State != JobDone:
+			http.Error(w, fmt.Sprintf("job is %s", status.State), http.StatusConflict)
+		default:
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write(status.PDF)
+		}
+	})
+
+	return mux
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
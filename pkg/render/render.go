@@ -0,0 +1,595 @@
+// Package render exposes the markdown to PDF pipeline as an importable library.
+//
+// It wraps the internal markdown, images, templates, and pdf packages so other Go
+// services can render documents without shelling out to the markdown-to-pdf binary.
+package render
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/kuzik/markdown-pdf-action/internal/images"
+	"github.com/kuzik/markdown-pdf-action/internal/markdown"
+	"github.com/kuzik/markdown-pdf-action/internal/pdf"
+	"github.com/kuzik/markdown-pdf-action/internal/sanitize"
+	"github.com/kuzik/markdown-pdf-action/internal/templates"
+	"github.com/yuin/goldmark"
+)
+
+//go:embed template.html
+var templateFS embed.FS
+
+// PDFOptions configures PDF generation settings.
+type PDFOptions = pdf.Options
+
+// DefaultPDFOptions returns sensible defaults for PDF generation.
+func DefaultPDFOptions() PDFOptions {
+	return pdf.DefaultOptions()
+}
+
+// MarkdownOptions selects the goldmark extensions used to convert markdown.
+// The zero value uses the defaults.
+type MarkdownOptions = markdown.Options
+
+// Section is the part of combined markdown that came from one source. Its
+// heading IDs are prefixed with its namespace so the same heading in two
+// sources doesn't collide, and its in-document links are updated.
+type Section = markdown.Section
+
+// WikilinkOptions configures how the "wikilinks" markdown extension resolves
+// [[Page]] links and ![[image.png]] embeds.
+type WikilinkOptions = markdown.WikilinkOptions
+
+// RegisterMarkdownExtension makes a goldmark extension available to
+// MarkdownOptions under name.
+func RegisterMarkdownExtension(name string, ext goldmark.Extender) {
+	markdown.Register(name, ext)
+}
+
+// PDFBackend converts a complete HTML document to PDF bytes.
+type PDFBackend = pdf.Backend
+
+// NewPDFBackend returns a backend by name: chrome (default), wkhtmltopdf, or gotenberg.
+// endpoint is the wkhtmltopdf binary path or the Gotenberg service URL.
+func NewPDFBackend(name, endpoint string) (PDFBackend, error) {
+	return pdf.NewBackend(name, endpoint)
+}
+
+// PDFPool shares one Chrome browser across concurrent renders with per-render tabs
+// and automatic restarts. Set it as PDFOptions.Backend to render through the pool.
+type PDFPool = pdf.Pool
+
+// NewPDFPool starts a browser pool allowing size concurrent tabs.
+// A positive healthInterval enables periodic background health checks.
+func NewPDFPool(size int, healthInterval time.Duration, opts PDFOptions) (*PDFPool, error) {
+	return pdf.NewPool(size, healthInterval, opts)
+}
+
+// RenderRequest describes a single document to render.
+type RenderRequest struct {
+	// Markdown source. If empty, SourcePath is read instead.
+	Markdown []byte
+
+	// Parts of Markdown combined from several sources, by byte offset, whose
+	// heading IDs are namespaced per source, e.g. "auth-installation".
+	Sections []Section
+
+	// Pre-rendered body HTML. When set, markdown conversion and image
+	// embedding are skipped and the HTML is wrapped as-is.
+	HTML string
+
+	// Print HTML as the complete document instead of wrapping it in the
+	// template, such as a pre-generated report (see PrepareHTML). Captions,
+	// link URLs, the QR code, and the appendix are not added. Ignored for
+	// Safe requests, whose sanitized HTML is always wrapped.
+	Standalone bool
+
+	// Path to a markdown file, used when Markdown and HTML are empty.
+	SourcePath string
+
+	// HTML appended after the body, such as a generated history page.
+	// It is trusted and not sanitized, even for Safe requests.
+	Appendix string
+
+	// Directory used to resolve relative image paths
+	// (defaults to the directory of SourcePath).
+	BaseDir string
+
+	// Document title. Defaults to the text of the first h1, then the "title"
+	// of the front matter, then the base name of SourcePath or OutputPath,
+	// and "Document" without either.
+	Title string
+
+	// Document language and text direction.
+	Locale Locale
+
+	// Print layout behavior, such as wide table handling.
+	Layout Layout
+
+	// Markdown extensions to enable or disable.
+	MarkdownOptions MarkdownOptions
+
+	// Insert a List of Figures or Tables before the body. Captions come from
+	// the "figures" and "table_captions" markdown extensions and are numbered
+	// either way.
+	ListOfFigures bool
+	ListOfTables  bool
+
+	// Print the URL of each external link, inline or as numbered endnotes
+	// (LinkURLsInline, LinkURLsEndnotes), for printed copies. Empty prints none.
+	LinkURLs string
+
+	// Link encoded in a QR code at the top of the first page, such as the
+	// document's canonical URL, so printed copies lead back to it.
+	QRCode string
+
+	// Built-in theme, such as "latex" (see Themes). Empty uses the default.
+	Theme string
+
+	// Raw HTML, CSS, or JS added to the document template.
+	Inject Injection
+
+	// Directory with a template.html replacing the built-in template, such as
+	// a corporate letterhead. The stylesheets, scripts, images, and fonts it
+	// references relative to the directory are inlined. It is executed with
+	// the same data: .Title, .Content, .Lang, .Dir, .Fonts, .Theme, .Math, and
+	// the .InjectHead, .InjectBodyStart, and .InjectBodyEnd fields.
+	TemplateDir string
+
+	// Optional transform applied to the complete HTML document before printing.
+	TransformHTML func(html string) (string, error)
+
+	// Where to write the PDF. If empty, the PDF is only returned in Result.
+	OutputPath string
+
+	// PDF generation settings (uses DefaultPDFOptions if nil).
+	PDF *PDFOptions
+
+	// Render untrusted content: raw HTML is dropped, the body is sanitized,
+	// and Chrome keeps its web security enabled.
+	Safe bool
+
+	// Called with the duration of each stage of the document, such as
+	// "convert markdown", "wrap template", the Chrome stages, and "write",
+	// to diagnose slow renders. Nil disables tracing.
+	Trace func(stage string, d time.Duration)
+
+	// Replace images that couldn't be embedded, such as a mistyped path, with
+	// a visible "Missing image: path" box, so reviewers spot them on the page.
+	ImagePlaceholders bool
+
+	// Find the page each heading printed on, in Result.Headings, by embedding
+	// the document outline as PDF bookmarks (Chrome only).
+	LocateHeadings bool
+
+	// Record the requests the page makes while printing in Result.Resources,
+	// to audit what external content a document loads (Chrome only).
+	LogResources bool
+}
+
+// Injection is raw markup added to the document template, for small
+// customizations without replacing it. It is trusted, even for Safe requests.
+type Injection struct {
+	Head      string // end of <head>, after the template styles so they can be overridden
+	BodyStart string // start of <body>, before the content
+	BodyEnd   string // end of <body>, after the content and layout scripts
+}
+
+// Result holds the artifacts produced by a render.
+type Result struct {
+	// Path the PDF was written to (empty if OutputPath was not set).
+	OutputPath string
+
+	// Document title used in the HTML wrapper.
+	Title string
+
+	// Fully wrapped HTML document that was printed.
+	HTML string
+
+	// Generated PDF bytes.
+	PDF []byte
+
+	// Number of pages in the generated PDF.
+	Pages int
+
+	// Requests the page made while printing, when LogResources is set.
+	Resources []Resource
+
+	// Headings of the document with their pages, when LocateHeadings is set.
+	Headings []Heading
+
+	// JavaScript errors, console.error messages, and failed resource loads of
+	// the page, such as a diagram script that couldn't parse its source
+	// (Chrome only).
+	Warnings []string
+}
+
+// Resource is a request the page made while rendering: its URL, resource
+// type, HTTP status, bytes received, and any loading error.
+type Resource = pdf.Resource
+
+var tmplLoader *templates.EmbeddedLoader
+
+func init() {
+	tmplLoader = templates.NewEmbeddedLoader(templateFS)
+}
+
+type pageData struct {
+	Title   string
+	Content template.HTML
+
+	Lang  string
+	Dir   string
+	Fonts template.CSS // script-specific fonts placed ahead of the default stack
+	Theme template.CSS // theme stylesheet layered over the template styles
+	Math  bool         // the content has math for KaTeX to typeset
+
+	Layout  Layout
+	Pages   *pageSizes   // set when wide content moves to landscape pages
+	Page    pageBox      // zero when the paper size is unknown
+	Margins *pageMargins // set when pages have mirrored inner and outer margins
+
+	InjectHead      template.HTML
+	InjectBodyStart template.HTML
+	InjectBodyEnd   template.HTML
+}
+
+// Render runs the markdown to PDF pipeline for a single document.
+func Render(ctx context.Context, req RenderRequest) (Result, error) {
+	done := stats.begin(ctx)
+	res, err := renderDocument(ctx, req)
+	done(res, err)
+	return res, err
+}
+
+// renderDocument runs the pipeline of Render
+func renderDocument(ctx context.Context, req RenderRequest) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	body, err := resolveBody(req)
+	if err != nil {
+		return Result{}, err
+	}
+	if req.ImagePlaceholders {
+		body = images.ReplaceMissing(body)
+	}
+
+	title := req.Title
+	if title == "" {
+		title = documentTitle(req, body)
+	}
+
+	opts := pdf.DefaultOptions()
+	if req.PDF != nil {
+		opts = *req.PDF
+	}
+	if req.Safe {
+		opts.Sandboxed = true
+	}
+
+	htmlContent := body
+	if !req.Standalone || req.Safe {
+		if htmlContent, err = wrapBody(body, title, req, &opts); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if req.TransformHTML != nil {
+		start := time.Now()
+		if htmlContent, err = req.TransformHTML(htmlContent); err != nil {
+			return Result{}, fmt.Errorf("transform HTML: %w", err)
+		}
+		traceSince(req.Trace, "transform HTML", start)
+	}
+
+	// Convert HTML to PDF
+	start := time.Now()
+	opts.Trace = req.Trace
+	var resources []Resource
+	if req.LogResources {
+		opts.Resources = func(r []Resource) { resources = r }
+	}
+	opts.Outline = opts.Outline || req.LocateHeadings
+	var warnings []string
+	opts.Warnings = func(w []string) { warnings = w }
+	pdfBuf, err := pdf.Generate(ctx, htmlContent, opts)
+	if err != nil {
+		return Result{}, fmt.Errorf("convert to PDF: %w", err)
+	}
+	traceSince(req.Trace, "generate PDF (total)", start)
+
+	res := Result{Title: title, HTML: htmlContent, PDF: pdfBuf, Pages: pdf.PageCount(pdfBuf), Resources: resources, Warnings: warnings}
+	if req.LocateHeadings {
+		if res.Headings, err = locateHeadings(htmlContent, pdfBuf); err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("locate headings: %v", err))
+		}
+	}
+
+	if req.OutputPath != "" {
+		start = time.Now()
+		// Ensure output directory exists
+		if err := os.MkdirAll(filepath.Dir(req.OutputPath), 0o755); err != nil {
+			return Result{}, fmt.Errorf("create output directory: %w", err)
+		}
+		if err := os.WriteFile(req.OutputPath, pdfBuf, 0o644); err != nil {
+			return Result{}, fmt.Errorf("write pdf: %w", err)
+		}
+		res.OutputPath = req.OutputPath
+		traceSince(req.Trace, "write", start)
+	}
+
+	return res, nil
+}
+
+// wrapBody adds the captions, link URLs, QR code, and appendix of a request to
+// its body and wraps it in the document template
+func wrapBody(body, title string, req RenderRequest, opts *PDFOptions) (string, error) {
+	start := time.Now()
+	body, err := numberCaptions(body, req)
+	if err != nil {
+		return "", err
+	}
+
+	if body, err = annotateLinks(body, req.LinkURLs); err != nil {
+		return "", err
+	}
+
+	if req.QRCode != "" {
+		cover, err := coverQRCode(req.QRCode)
+		if err != nil {
+			return "", err
+		}
+		body = cover + body
+	}
+
+	body += req.Appendix
+	traceSince(req.Trace, "annotate", start)
+
+	layout, err := req.Layout.normalize()
+	if err != nil {
+		return "", err
+	}
+
+	// Wrap in styled HTML template
+	start = time.Now()
+	htmlContent, err := wrapHTML(body, title, wrapOptions{
+		locale: req.Locale,
+		layout: layout,
+		pdf:    opts,
+		theme:  req.Theme,
+		inject: req.Inject,
+		dir:    req.TemplateDir,
+	})
+	if err != nil {
+		return "", fmt.Errorf("wrap HTML: %w", err)
+	}
+	traceSince(req.Trace, "wrap template", start)
+	return htmlContent, nil
+}
+
+// traceSince reports the duration of a stage begun at start to trace, if set
+func traceSince(trace func(string, time.Duration), stage string, start time.Time) {
+	if trace != nil {
+		trace(stage, time.Since(start))
+	}
+}
+
+// resolveBody returns the body HTML for a request, converting markdown if needed.
+func resolveBody(req RenderRequest) (string, error) {
+	if req.HTML != "" {
+		if req.Safe {
+			return sanitize.HTML(req.HTML), nil
+		}
+		return req.HTML, nil
+	}
+
+	src := req.Markdown
+	if len(src) == 0 {
+		if req.SourcePath == "" {
+			return "", fmt.Errorf("no markdown, HTML, or source path provided")
+		}
+		start := time.Now()
+		var err error
+		src, err = os.ReadFile(req.SourcePath)
+		if err != nil {
+			return "", fmt.Errorf("read markdown: %w", err)
+		}
+		traceSince(req.Trace, "read", start)
+	}
+
+	// Determine base directory for resolving images
+	baseDir := req.BaseDir
+	if baseDir == "" && req.SourcePath != "" {
+		baseDir = filepath.Dir(req.SourcePath)
+	}
+
+	return markdownBodyHTML(src, baseDir, req.MarkdownOptions, req.Safe, req.Sections, req.Trace)
+}
+
+// BodyHTML converts markdown to HTML and embeds images relative to baseDir.
+func BodyHTML(src []byte, baseDir string) (string, error) {
+	return MarkdownBodyHTML(src, baseDir, MarkdownOptions{}, false)
+}
+
+// SafeBodyHTML converts untrusted markdown to sanitized HTML and embeds images relative to baseDir.
+func SafeBodyHTML(src []byte, baseDir string) (string, error) {
+	return MarkdownBodyHTML(src, baseDir, MarkdownOptions{}, true)
+}
+
+// MarkdownBodyHTML converts markdown to HTML with the given extensions and embeds
+// images relative to baseDir. Safe drops raw HTML and sanitizes the output.
+func MarkdownBodyHTML(src []byte, baseDir string, opts MarkdownOptions, safe bool, sections ...Section) (string, error) {
+	return markdownBodyHTML(src, baseDir, opts, safe, sections, nil)
+}
+
+// markdownBodyHTML is MarkdownBodyHTML reporting the duration of each stage to trace
+func markdownBodyHTML(src []byte, baseDir string, opts MarkdownOptions, safe bool, sections []Section, trace func(string, time.Duration)) (string, error) {
+	conv, err := markdown.CachedConverter(opts, !safe)
+	if err != nil {
+		return "", err
+	}
+
+	// Convert markdown to HTML
+	start := time.Now()
+	htmlBody, err := conv.ToHTMLSections(src, baseDir, sections)
+	if err != nil {
+		return "", fmt.Errorf("convert markdown: %w", err)
+	}
+	traceSince(trace, "convert markdown", start)
+
+	// Sanitize before embedding so only vetted image sources are inlined
+	if safe {
+		start = time.Now()
+		htmlBody = sanitize.HTML(htmlBody)
+		traceSince(trace, "sanitize", start)
+	}
+
+	// Embed images as base64 data URLs; untrusted content only reaches files
+	// inside baseDir
+	start = time.Now()
+	embed := images.EmbedImagesAsBase64
+	if safe {
+		embed = images.EmbedImagesConfined
+	}
+	htmlWithImages, err := embed(htmlBody, baseDir)
+	if err != nil {
+		return "", fmt.Errorf("embed images: %w", err)
+	}
+	traceSince(trace, "embed images", start)
+
+	return htmlWithImages, nil
+}
+
+// WrapHTML wraps HTML content in the styled document template.
+func WrapHTML(content, title string) (string, error) {
+	return WrapHTMLWithLocale(content, title, Locale{})
+}
+
+// WrapHTMLWithLocale wraps HTML content in the styled document template with the
+// given language and text direction.
+func WrapHTMLWithLocale(content, title string, loc Locale) (string, error) {
+	return wrapHTML(content, title, wrapOptions{locale: loc})
+}
+
+// Themes lists the names of the built-in themes.
+func Themes() []string {
+	var names []string
+	for _, theme := range templates.Themes() {
+		names = append(names, theme.Name)
+	}
+	return names
+}
+
+// TemplateReport lists the problems found in a custom document template.
+type TemplateReport = templates.Report
+
+// customTemplateFields are the template data fields documented for custom
+// templates; the others serve the built-in template's layout scripts.
+var customTemplateFields = map[string]bool{
+	"Title": true, "Content": true, "Lang": true, "Dir": true, "Fonts": true, "Theme": true,
+	"InjectHead": true, "InjectBodyStart": true, "InjectBodyEnd": true,
+}
+
+// CheckTemplate parses the template.html of a template directory (see
+// RenderRequest.TemplateDir) with its assets inlined and checks it against
+// sample document data, without rendering. A template that never prints
+// .Content is reported as an error.
+func CheckTemplate(dir string) (TemplateReport, error) {
+	tmpl, err := templates.NewLoader(dir).LoadWithAssets("template.html")
+	if err != nil {
+		return TemplateReport{}, err
+	}
+
+	opts := DefaultPDFOptions()
+	layout, _ := Layout{}.normalize()
+	report := templates.Check(tmpl, pageData{
+		Title:   "Sample",
+		Content: "<h1>Sample</h1>\n<p>Sample content.</p>",
+		Lang:    "en",
+		Dir:     "ltr",
+		Layout:  layout,
+		Page:    printableBox(opts),
+	})
+
+	report.Unused = slices.DeleteFunc(report.Unused, func(field string) bool {
+		return !customTemplateFields[field]
+	})
+	if report.Err == nil && slices.Contains(report.Unused, "Content") {
+		report.Err = fmt.Errorf("template never prints .Content")
+	}
+	return report, nil
+}
+
+// wrapOptions configure the document template
+type wrapOptions struct {
+	locale Locale
+	layout Layout
+	theme  string
+	inject Injection
+	dir    string // custom template directory
+
+	// Sizes the pages when known; updated to honor the CSS page size when wide
+	// content moves to landscape pages
+	pdf *PDFOptions
+}
+
+// mathRegex matches the math of the math extension and the delimiters KaTeX's
+// auto-render looks for: \(...\), \[...\], $$...$$, and $...$ hugging its text
+var mathRegex = regexp.MustCompile(`class="math|\\\(|\\\[|\$\$|\$[^$\s]([^$\n]*[^$\s])?\$`)
+
+// hasMath reports whether content has math, so the template only loads KaTeX,
+// from its CDN, for documents that need it and works offline otherwise
+func hasMath(content string) bool {
+	return mathRegex.MatchString(content)
+}
+
+// wrapHTML wraps HTML content in the document template.
+func wrapHTML(content, title string, w wrapOptions) (string, error) {
+	loc, err := w.locale.normalize()
+	if err != nil {
+		return "", err
+	}
+	layout, err := w.layout.normalize()
+	if err != nil {
+		return "", err
+	}
+	theme, err := templates.LookupTheme(w.theme)
+	if err != nil {
+		return "", err
+	}
+
+	data := pageData{
+		Title:   title,
+		Content: template.HTML(content),
+		Lang:    loc.Lang,
+		Dir:     loc.Dir,
+		Fonts:   template.CSS(loc.fonts()),
+		Theme:   template.CSS(theme.CSS),
+		Math:    hasMath(content),
+		Layout:  layout,
+
+		InjectHead:      template.HTML(w.inject.Head),
+		InjectBodyStart: template.HTML(w.inject.BodyStart),
+		InjectBodyEnd:   template.HTML(w.inject.BodyEnd),
+	}
+	if w.pdf != nil {
+		data.Margins = layout.mirroredMargins(w.pdf)
+		data.Pages = layout.landscapePages(w.pdf)
+		data.Page = printableBox(*w.pdf)
+	}
+
+	if w.dir == "" {
+		return tmplLoader.Render("template.html", data)
+	}
+
+	return templates.NewLoader(w.dir).RenderWithAssets("template.html", data)
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeBodyHTMLConfinesImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		baseDir string
+	}{
+		{"absolute path", "![x](/etc/hostname)", ""},
+		{"absolute path with base directory", "![x](/etc/hostname)", t.TempDir()},
+		{"parent directories", "![x](../../../../../../etc/hostname)", ""},
+		{"parent directories with base directory", "![x](../../../../../../etc/hostname)", t.TempDir()},
+		{"file URL", "![x](file:///etc/hostname)", t.TempDir()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SafeBodyHTML([]byte(tt.src), tt.baseDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(got, ";base64,") {
+				t.Errorf("SafeBodyHTML(%q) embedded a file: %s", tt.src, got)
+			}
+			if strings.Contains(got, "hostname") {
+				t.Errorf("SafeBodyHTML(%q) left the path for Chrome to load: %s", tt.src, got)
+			}
+		})
+	}
+}
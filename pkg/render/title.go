@@ -0,0 +1,45 @@
+package render
+
+import (
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kuzik/markdown-pdf-action/internal/frontmatter"
+)
+
+var h1Regex = regexp.MustCompile(`(?is)<h1(?:\s[^>]*)?>(.*?)</h1>`)
+
+// untitled is the title of documents with no heading, front matter title, or file
+const untitled = "Document"
+
+// documentTitle returns the title of a request that doesn't set one: the text
+// of the first h1, the front matter title, the file name of the source or of
+// the output, or else untitled
+func documentTitle(req RenderRequest, body string) string {
+	if m := h1Regex.FindStringSubmatch(body); m != nil {
+		if title := strings.Join(strings.Fields(html.UnescapeString(tagRegex.ReplaceAllString(m[1], ""))), " "); title != "" {
+			return title
+		}
+	}
+
+	src := req.Markdown
+	if len(src) == 0 && req.HTML == "" && req.SourcePath != "" {
+		src, _ = os.ReadFile(req.SourcePath)
+	}
+	if fields, _, err := frontmatter.Parse(src); err == nil {
+		if title := frontmatter.String(fields, "title"); title != "" {
+			return title
+		}
+	}
+
+	switch {
+	case req.SourcePath != "":
+		return filepath.Base(req.SourcePath)
+	case req.OutputPath != "":
+		return strings.TrimSuffix(filepath.Base(req.OutputPath), filepath.Ext(req.OutputPath))
+	}
+	return untitled
+}